@@ -12,6 +12,9 @@ import (
 //	@securityDefinitions.apikey	JWT
 //	@in							header
 //	@name						Authorization
+//	@securityDefinitions.apikey	APIKey
+//	@in							header
+//	@name						X-API-Key
 //	@BasePath					/api/
 func main() {
 	err := configuration.LoadConfig(".env")
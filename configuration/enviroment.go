@@ -1,6 +1,8 @@
 package configuration
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/spf13/viper"
 	"time"
 )
@@ -13,8 +15,58 @@ type EnvConfigModel struct {
 	DBPort         string `mapstructure:"POSTGRES_PORT"`
 	SSLMode        string `mapstructure:"SSL_MODE"`
 
-	JwtSecret    string        `mapstructure:"JWT_SECRET_KEY"`
-	JwtExpiresIn time.Duration `mapstructure:"JWT_SECRET_KEY_EXPIRES_IN"`
+	JwtSecret           string        `mapstructure:"JWT_SECRET_KEY"`
+	JwtExpiresIn        time.Duration `mapstructure:"JWT_SECRET_KEY_EXPIRES_IN"`
+	JwtRefreshExpiresIn time.Duration `mapstructure:"JWT_REFRESH_TOKEN_EXPIRES_IN"`
+	// HS256 by default (signed with JwtSecret), RS256 or EdDSA (signed with private key from PEM file)
+	JwtAlgorithm      string `mapstructure:"JWT_ALGORITHM"`
+	JwtPrivateKeyFile string `mapstructure:"JWT_PRIVATE_KEY_FILE"`
+	// Comma separated PEM files of previous public keys, JWT signed with them are still verified during rotation
+	JwtPublicKeyFiles string `mapstructure:"JWT_PUBLIC_KEY_FILES"`
+
+	PasswordMinLength        int  `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordRequireDigit     bool `mapstructure:"PASSWORD_REQUIRE_DIGIT"`
+	PasswordRequireUppercase bool `mapstructure:"PASSWORD_REQUIRE_UPPERCASE"`
+	PasswordRequireSymbol    bool `mapstructure:"PASSWORD_REQUIRE_SYMBOL"`
+
+	PasswordResetURL       string        `mapstructure:"PASSWORD_RESET_URL"` // link to page of client, reset token is added to query
+	PasswordResetExpiresIn time.Duration `mapstructure:"PASSWORD_RESET_TOKEN_EXPIRES_IN"`
+	EmailChangeURL         string        `mapstructure:"EMAIL_CHANGE_URL"` // link to page of client, confirmation token is added to query
+	EmailChangeExpiresIn   time.Duration `mapstructure:"EMAIL_CHANGE_TOKEN_EXPIRES_IN"`
+	MailFilePath           string        `mapstructure:"MAIL_FILE_PATH"` // mails are appended to file, written to log if empty
+
+	AdminUserID string `mapstructure:"ADMIN_USER_ID"` // ID of registered user which gets admin role on start, nobody if not set
+
+	// Requests per minute from one IP or user, limit is disabled if not positive
+	RateLimit       int `mapstructure:"RATE_LIMIT"`
+	AuthRateLimit   int `mapstructure:"AUTH_RATE_LIMIT"`
+	WinnerRateLimit int `mapstructure:"WINNER_RATE_LIMIT"`
+	DecideRateLimit int `mapstructure:"DECIDE_RATE_LIMIT"`
+
+	// Login is locked after max failed attempts, every next failed attempt doubles lockout up to max lockout
+	LoginMaxAttempts int           `mapstructure:"LOGIN_MAX_ATTEMPTS"`
+	LoginLockout     time.Duration `mapstructure:"LOGIN_LOCKOUT"`
+	LoginMaxLockout  time.Duration `mapstructure:"LOGIN_MAX_LOCKOUT"`
+
+	// Issuer shown in authenticator apps and time to enter TOTP code after password
+	TwoFactorIssuer             string        `mapstructure:"TWO_FACTOR_ISSUER"`
+	TwoFactorChallengeExpiresIn time.Duration `mapstructure:"TWO_FACTOR_CHALLENGE_EXPIRES_IN"`
+
+	// Comma separated origins of clients which may send credentials (state cookie of OIDC login),
+	// any origin without credentials if not set
+	CORSOrigins string `mapstructure:"CORS_ORIGINS"`
+
+	// OpenID Connect providers, decoded from JSON array of OIDC_PROVIDERS
+	OIDCProviders []OIDCProviderConfig `mapstructure:"-"`
+}
+
+type OIDCProviderConfig struct {
+	Name         string   `json:"name"`   // used in URLs of login flow, e.g. /api/auth/oidc/{name}/login
+	Issuer       string   `json:"issuer"` // discovery document is fetched from {issuer}/.well-known/openid-configuration
+	ClientID     string   `json:"clientId"`
+	ClientSecret string   `json:"clientSecret"` // optional for public clients
+	RedirectURL  string   `json:"redirectURL"`  // page which passes code and state to callback of login flow
+	Scopes       []string `json:"scopes"`       // openid, profile and email by default
 }
 
 var EnvConfig EnvConfigModel
@@ -25,9 +77,34 @@ func LoadConfig(filePath string) (err error) {
 
 	viper.AutomaticEnv()
 
+	viper.SetDefault("JWT_REFRESH_TOKEN_EXPIRES_IN", "720h")
+	viper.SetDefault("PASSWORD_MIN_LENGTH", 8)
+	viper.SetDefault("PASSWORD_RESET_TOKEN_EXPIRES_IN", "1h")
+	viper.SetDefault("EMAIL_CHANGE_TOKEN_EXPIRES_IN", "1h")
+	viper.SetDefault("RATE_LIMIT", 300)
+	viper.SetDefault("AUTH_RATE_LIMIT", 20)
+	viper.SetDefault("WINNER_RATE_LIMIT", 10)
+	viper.SetDefault("DECIDE_RATE_LIMIT", 60)
+	viper.SetDefault("LOGIN_MAX_ATTEMPTS", 5)
+	viper.SetDefault("LOGIN_LOCKOUT", "1m")
+	viper.SetDefault("LOGIN_MAX_LOCKOUT", "1h")
+	viper.SetDefault("TWO_FACTOR_ISSUER", "TikTok arena")
+	viper.SetDefault("TWO_FACTOR_CHALLENGE_EXPIRES_IN", "5m")
+
 	if viper.ReadInConfig() != nil {
 		return
 	}
 
-	return viper.Unmarshal(&EnvConfig)
+	err = viper.Unmarshal(&EnvConfig)
+	if err != nil {
+		return err
+	}
+
+	if providers := viper.GetString("OIDC_PROVIDERS"); providers != "" {
+		err = json.Unmarshal([]byte(providers), &EnvConfig.OIDCProviders)
+		if err != nil {
+			return fmt.Errorf("OIDC_PROVIDERS is not valid JSON: %v", err)
+		}
+	}
+	return nil
 }
@@ -15,39 +15,25 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/auth/login": {
-            "post": {
-                "description": "Login user with given credentials",
-                "consumes": [
-                    "application/json"
-                ],
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Get public keys which verify JWT issued by arena, key is selected by \"kid\" header of JWT",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "auth"
                 ],
-                "summary": "Login user",
-                "parameters": [
-                    {
-                        "description": "Data to login user",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.AuthInput"
-                        }
-                    }
-                ],
+                "summary": "JSON Web Key Set",
                 "responses": {
                     "200": {
-                        "description": "Login success",
+                        "description": "Public keys",
                         "schema": {
-                            "$ref": "#/definitions/dtos.RegisterDetails"
+                            "$ref": "#/definitions/dtos.JWKS"
                         }
                     },
                     "400": {
-                        "description": "Error logging in",
+                        "description": "Error loading keys",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
@@ -55,9 +41,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/auth/register": {
-            "post": {
-                "description": "Register new user with given credentials",
+        "/api/admin/tournaments": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get tournaments of all users including private ones, moderator role is required",
                 "consumes": [
                     "application/json"
                 ],
@@ -65,29 +56,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Register user",
+                "summary": "All tournaments for moderation",
                 "parameters": [
                     {
-                        "description": "Data to register user",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.AuthInput"
-                        }
+                        "type": "string",
+                        "description": "page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "page size",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Register success",
+                        "description": "All tournaments",
                         "schema": {
-                            "$ref": "#/definitions/dtos.RegisterDetails"
+                            "$ref": "#/definitions/dtos.TournamentsResponse"
                         }
                     },
-                    "400": {
-                        "description": "Failed to register user",
+                    "403": {
+                        "description": "Role is not allowed",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
@@ -95,14 +95,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/auth/whoami": {
-            "get": {
+        "/api/admin/tournaments/{tournamentId}": {
+            "delete": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Get current user id and name",
+                "description": "Delete tournament of any user with its play sessions and statistics, moderator role is required",
                 "consumes": [
                     "application/json"
                 ],
@@ -110,18 +110,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
+                ],
+                "summary": "Delete any tournament",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament id",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Authenticated user details",
                 "responses": {
                     "200": {
-                        "description": "User details",
+                        "description": "Tournament deleted",
                         "schema": {
-                            "$ref": "#/definitions/dtos.WhoAmI"
+                            "$ref": "#/definitions/dtos.MessageResponseType"
                         }
                     },
                     "400": {
-                        "description": "Error getting user data",
+                        "description": "Error during tournament deletion",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "403": {
+                        "description": "Role is not allowed",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
@@ -129,9 +144,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tournament/contest/{tournamentId}": {
-            "get": {
-                "description": "Get tournament contests",
+        "/api/admin/tournaments/{tournamentId}/stats": {
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Reset wins, places and ratings of tiktoks and delete matchups, rankings and tier lists of tournament, admin role is required",
                 "consumes": [
                     "application/json"
                 ],
@@ -139,9 +159,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tournament"
+                    "admin"
                 ],
-                "summary": "Tournament contests",
+                "summary": "Reset tournament stats",
                 "parameters": [
                     {
                         "type": "string",
@@ -149,23 +169,23 @@ const docTemplate = `{
                         "name": "tournamentId",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "name": "type",
-                        "in": "query",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Contest bracket",
+                        "description": "Stats reset",
                         "schema": {
-                            "$ref": "#/definitions/dtos.Contest"
+                            "$ref": "#/definitions/dtos.MessageResponseType"
                         }
                     },
                     "400": {
-                        "description": "Failed to return tournament contests",
+                        "description": "Error during stats reset",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "403": {
+                        "description": "Role is not allowed",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
@@ -173,14 +193,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tournament/create": {
-            "post": {
+        "/api/admin/users/{userId}/ban": {
+            "put": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Create new tournament for current user",
+                "description": "Ban or unban user with lower role, tokens of banned user are revoked, moderator role is required",
                 "consumes": [
                     "application/json"
                 ],
@@ -188,29 +208,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tournament"
+                    "admin"
                 ],
-                "summary": "Create new tournament",
+                "summary": "Ban user",
                 "parameters": [
                     {
-                        "description": "Data to create tournament",
+                        "type": "string",
+                        "description": "User id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Ban or unban",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.CreateTournament"
+                            "$ref": "#/definitions/dtos.BanUser"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Tournament created",
+                        "description": "User banned or unbanned",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
                     },
                     "400": {
-                        "description": "Error during tournament creation",
+                        "description": "Error during ban",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "403": {
+                        "description": "Role is not allowed",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
@@ -218,14 +251,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tournament/delete": {
-            "delete": {
+        "/api/admin/users/{userId}/role": {
+            "put": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Delete tournaments for current user",
+                "description": "Set role of user (user, moderator or admin), access tokens with old role are revoked, admin role is required",
                 "consumes": [
                     "application/json"
                 ],
@@ -233,29 +266,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tournament"
+                    "admin"
                 ],
-                "summary": "Delete tournaments",
+                "summary": "Change user role",
                 "parameters": [
                     {
-                        "description": "Data to delete tournaments",
+                        "type": "string",
+                        "description": "User id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.TournamentIds"
+                            "$ref": "#/definitions/dtos.ChangeRole"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Tournaments deleted",
+                        "description": "Role changed",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
                     },
                     "400": {
-                        "description": "Error during tournaments deletion",
+                        "description": "Error during role change",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "403": {
+                        "description": "Role is not allowed",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
@@ -263,14 +309,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tournament/delete/{tournamentId}": {
-            "delete": {
+        "/api/auth/2fa/disable": {
+            "post": {
                 "security": [
                     {
-                        "ApiKeyAuth": []
+                        "JWT": []
                     }
                 ],
-                "description": "Delete tournament for current user",
+                "description": "Disable 2FA of current user, password and TOTP or recovery code are required",
                 "consumes": [
                     "application/json"
                 ],
@@ -278,18 +324,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tournament"
+                    "auth"
+                ],
+                "summary": "Disable 2FA",
+                "parameters": [
+                    {
+                        "description": "Password and code",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.TwoFactorDisable"
+                        }
+                    }
                 ],
-                "summary": "Delete tournament",
                 "responses": {
                     "200": {
-                        "description": "Tournament deleted",
+                        "description": "2FA disabled",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
                     },
-                    "400": {
-                        "description": "Error during tournament deletion",
+                    "401": {
+                        "description": "Invalid code",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
@@ -297,9 +354,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tournament/details/{tournamentId}": {
-            "get": {
-                "description": "Get tournament details by its id",
+        "/api/auth/2fa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Generate TOTP secret and otpauth URI for authenticator app, 2FA is enabled after code is verified",
                 "consumes": [
                     "application/json"
                 ],
@@ -307,27 +369,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tournament"
+                    "auth"
                 ],
-                "summary": "Tournament details",
+                "summary": "Enroll 2FA",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Tournament id",
-                        "name": "tournamentId",
-                        "in": "path",
-                        "required": true
+                        "description": "Password of user",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.TwoFactorEnrollInput"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Tournament",
+                        "description": "TOTP secret",
                         "schema": {
-                            "$ref": "#/definitions/models.Tournament"
+                            "$ref": "#/definitions/dtos.TwoFactorEnrollment"
                         }
                     },
                     "400": {
-                        "description": "Tournament not found",
+                        "description": "Error enrolling 2FA",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "409": {
+                        "description": "2FA is already enabled",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
@@ -335,14 +405,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tournament/edit/{tournamentId}": {
-            "put": {
-                "security": [
-                    {
-                        "JWT": []
-                    }
-                ],
-                "description": "Edit tournament for current user",
+        "/api/auth/2fa/login": {
+            "post": {
+                "description": "Exchange challenge token returned by login of user with 2FA for tokens, TOTP or recovery code is required",
                 "consumes": [
                     "application/json"
                 ],
@@ -350,36 +415,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tournament"
+                    "auth"
                 ],
-                "summary": "Edit tournament",
+                "summary": "Second login step",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Tournament id",
-                        "name": "tournamentId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Data to edit tournament",
+                        "description": "Challenge token and code",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.EditTournament"
+                            "$ref": "#/definitions/dtos.TwoFactorLogin"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Tournament edited",
+                        "description": "Login success",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.LoginDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid code or expired challenge",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
                     },
-                    "400": {
-                        "description": "Error during tournament edition",
+                    "429": {
+                        "description": "Too many failed logins, retry after Retry-After seconds",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
@@ -387,9 +451,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tournament/tiktoks/{tournamentId}": {
-            "get": {
-                "description": "Get tournament tiktoks",
+        "/api/auth/2fa/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Replace recovery codes of current user, TOTP or recovery code is required",
                 "consumes": [
                     "application/json"
                 ],
@@ -397,30 +466,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tournament"
+                    "auth"
                 ],
-                "summary": "Tournament tiktoks",
+                "summary": "Regenerate recovery codes",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Tournament id",
-                        "name": "tournamentId",
-                        "in": "path",
-                        "required": true
+                        "description": "TOTP or recovery code",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.TwoFactorCode"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Tournament tiktoks",
+                        "description": "New recovery codes",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Tiktok"
-                            }
+                            "$ref": "#/definitions/dtos.RecoveryCodes"
                         }
                     },
-                    "400": {
-                        "description": "Tournament not found",
+                    "401": {
+                        "description": "Invalid code",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
@@ -428,9 +496,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tournament/tournaments": {
-            "get": {
-                "description": "Get all tournaments",
+        "/api/auth/2fa/verify": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Enable 2FA with first TOTP code of enrolled secret, recovery codes are returned only once",
                 "consumes": [
                     "application/json"
                 ],
@@ -438,41 +511,74 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tournament"
+                    "auth"
                 ],
-                "summary": "All tournaments",
+                "summary": "Verify 2FA",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "page number",
-                        "name": "page",
-                        "in": "query"
+                        "description": "TOTP code",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.TwoFactorCode"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "2FA enabled",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RecoveryCodes"
+                        }
                     },
+                    "401": {
+                        "description": "Invalid code",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/email": {
+            "put": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "page size",
-                        "name": "count",
-                        "in": "query"
-                    },
+                        "JWT": []
+                    }
+                ],
+                "description": "Send one-time confirmation token to new email of current user, password is required,\nemail is changed when token is confirmed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Change email",
+                "parameters": [
                     {
-                        "type": "string",
-                        "description": "search",
-                        "name": "search",
-                        "in": "query"
+                        "description": "New email, password and 2FA code",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ChangeEmailInput"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "All tournaments",
+                        "description": "Confirmation token sent",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dtos.TournamentsResponse"
-                            }
+                            "$ref": "#/definitions/dtos.MessageResponseType"
                         }
                     },
                     "400": {
-                        "description": "Failed to get all tournaments",
+                        "description": "Error changing email",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
@@ -480,9 +586,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tournament/winner/{tournamentId}": {
-            "put": {
-                "description": "Increment wins and increment times_played",
+        "/api/auth/email/confirm": {
+            "post": {
+                "description": "Set new email of user with one-time confirmation token sent to it",
                 "consumes": [
                     "application/json"
                 ],
@@ -490,36 +596,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tournament"
+                    "auth"
                 ],
-                "summary": "Update tournament winner statistics",
+                "summary": "Confirm email change",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Tournament id",
-                        "name": "tournamentId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Data to update tournament winner",
+                        "description": "Confirmation token",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.TournamentWinner"
+                            "$ref": "#/definitions/dtos.ConfirmEmailChange"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Winner updated",
+                        "description": "Email changed",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
                     },
                     "400": {
-                        "description": "Error during winner updating",
+                        "description": "Invalid confirmation token",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
@@ -527,14 +626,46 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/photo": {
-            "put": {
+        "/api/auth/keys": {
+            "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Change user photo for current user",
+                "description": "Get API keys of current user without keys themselves",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "API keys of user",
+                "responses": {
+                    "200": {
+                        "description": "Keys of user",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.APIKeyView"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Error getting keys",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Create personal API key for scripts, key is sent in X-API-Key header and is shown only once.\nScope read allows reading, scope tournament:write also allows to create, edit and delete tournaments",
                 "consumes": [
                     "application/json"
                 ],
@@ -542,29 +673,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "auth"
                 ],
-                "summary": "Change user photo",
+                "summary": "Create API key",
                 "parameters": [
                     {
-                        "description": "Data to change photo",
+                        "description": "Name and scope of key",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.ChangePhotoURL"
+                            "$ref": "#/definitions/dtos.CreateAPIKey"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Photo edited",
+                    "201": {
+                        "description": "Created key",
                         "schema": {
-                            "$ref": "#/definitions/dtos.MessageResponseType"
+                            "$ref": "#/definitions/dtos.CreatedAPIKey"
                         }
                     },
                     "400": {
-                        "description": "Error during photo change",
+                        "description": "Error creating key",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
@@ -572,72 +703,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/profile/{userId}": {
-            "get": {
+        "/api/auth/keys/{keyId}": {
+            "delete": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Get user information (tournaments, photo and etc.)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Delete API key of current user, key is not accepted anymore",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "auth"
                 ],
-                "summary": "Get user information",
+                "summary": "Revoke API key",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User id",
-                        "name": "userId",
+                        "description": "Key id",
+                        "name": "keyId",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "page size",
-                        "name": "count",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "sort page by name",
-                        "name": "sort_name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "sort page by size",
-                        "name": "sort_size",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "search",
-                        "name": "search",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User information",
+                        "description": "Key revoked",
                         "schema": {
-                            "$ref": "#/definitions/dtos.TournamentsResponseWithUser"
+                            "$ref": "#/definitions/dtos.MessageResponseType"
                         }
                     },
-                    "400": {
-                        "description": "Couldn't user information for specific user",
+                    "404": {
+                        "description": "Key doesn't exist",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
@@ -645,9 +743,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/users": {
-            "get": {
-                "description": "Get all users",
+        "/api/auth/login": {
+            "post": {
+                "description": "Login user with given credentials",
                 "consumes": [
                     "application/json"
                 ],
@@ -655,55 +753,2264 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "auth"
                 ],
-                "summary": "All users",
+                "summary": "Login user",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "page number",
-                        "name": "page",
-                        "in": "query"
+                        "description": "Data to login user",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.LoginInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login success or challenge token if user has 2FA",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.LoginDetails"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "page size",
-                        "name": "count",
-                        "in": "query"
+                    "400": {
+                        "description": "Error logging in",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
                     },
+                    "429": {
+                        "description": "Too many failed logins, retry after Retry-After seconds",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/logout": {
+            "post": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "search",
-                        "name": "search",
-                        "in": "query"
+                        "JWT": []
+                    }
+                ],
+                "description": "Revoke current access token and given refresh token or all refresh tokens of user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logout user",
+                "parameters": [
+                    {
+                        "description": "Refresh tokens to revoke",
+                        "name": "payload",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.LogoutInput"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "All users",
+                        "description": "Logout success",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dtos.UsersResponse"
-                            }
+                            "$ref": "#/definitions/dtos.MessageResponseType"
                         }
                     },
                     "400": {
-                        "description": "Failed to get all users",
+                        "description": "Error logging out",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessageResponseType"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dtos.AuthInput": {
-            "type": "object",
-            "required": [
-                "name",
-                "password"
+        },
+        "/api/auth/oidc/providers": {
+            "get": {
+                "description": "Get names of OpenID Connect providers users can login with",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Login providers",
+                "responses": {
+                    "200": {
+                        "description": "Names of providers",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.OIDCProviders"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/oidc/{provider}/callback": {
+            "get": {
+                "description": "Exchange authorization code returned by provider and login user of provider account,\nuser is registered on first login, state must be equal to state cookie set when login was started",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Complete login with provider",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State of login",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login success",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.LoginDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid callback",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "401": {
+                        "description": "Login with provider failed",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "409": {
+                        "description": "Account is linked to another user",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/oidc/{provider}/login": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get URL of provider where user logs in with authorization code flow and PKCE,\nif user is authenticated account of provider is linked to this user,\nstate of login is set to HttpOnly cookie which must be sent with callback",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Start login with provider",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "URL to redirect user to",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.OIDCLogin"
+                        }
+                    },
+                    "401": {
+                        "description": "Provider is not available",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "404": {
+                        "description": "Provider doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/password": {
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Change password of current user, old password is required, user is logged out on all devices\nand gets new tokens for current device",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Change password",
+                "parameters": [
+                    {
+                        "description": "Old and new password",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ChangePasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password changed, new tokens",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.TokenDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Error changing password",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/password/forgot": {
+            "post": {
+                "description": "Send one-time password reset token to email of user, response doesn't expose if user exists",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Request password reset",
+                "parameters": [
+                    {
+                        "description": "Name of user",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PasswordResetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reset token sent if user has email",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Error requesting password reset",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/password/reset": {
+            "post": {
+                "description": "Set new password with one-time password reset token, user is logged out on all devices",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Reset password",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PasswordReset"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password reset",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reset token or password",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/refresh": {
+            "post": {
+                "description": "Get new access token with refresh token, refresh token is rotated and can't be used again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refresh access token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RefreshInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "New access and refresh tokens",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.TokenDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/register": {
+            "post": {
+                "description": "Register new user with given credentials",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register user",
+                "parameters": [
+                    {
+                        "description": "Data to register user",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.AuthInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Register success",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RegisterDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to register user",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/whoami": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get current user id and name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Authenticated user details",
+                "responses": {
+                    "200": {
+                        "description": "User details",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.WhoAmI"
+                        }
+                    },
+                    "400": {
+                        "description": "Error getting user data",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/session/start/{tournamentId}": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Generate tournament contest and store it in new play session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Start play session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament id",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 3,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "only for groups knockout, 2 if not set",
+                        "name": "advance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only for double elimination",
+                        "name": "bracketReset",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 9007199254740991,
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "seed of shuffle and match IDs, random if not set",
+                        "name": "seed",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "random",
+                            "wins",
+                            "winrate"
+                        ],
+                        "type": "string",
+                        "name": "seeding",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 512,
+                        "minimum": 2,
+                        "type": "integer",
+                        "description": "tiktoks drawn for play, size of tournament if not set",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only for single elimination",
+                        "name": "thirdPlace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Play session",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PlaySessionDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to start play session",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "403": {
+                        "description": "Tournament is private",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/session/{sessionId}": {
+            "get": {
+                "security": [
+                    {
+                        "APIKey": []
+                    }
+                ],
+                "description": "Get contest and decided matches of play session to resume it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Play session details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session id",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Play session",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PlaySessionDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to get play session",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/session/{sessionId}/match": {
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Record vote for tiktok in match, match is decided when tiktok gets required count of votes (best-of-N),\ntournament statistics are updated when last match is decided.\nSession started by logged in user can be decided only with JWT of this user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Decide match of play session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session id",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Match and its winner",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MatchDecision"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Play session",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PlaySessionDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to decide match",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "403": {
+                        "description": "Session belongs to other user",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "409": {
+                        "description": "Match or session is already decided",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after Retry-After seconds",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tournament/contest/{tournamentId}": {
+            "get": {
+                "security": [
+                    {
+                        "APIKey": []
+                    },
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get tournament contest of tiktoks drawn from tournament pool (size of payload or of tournament)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Tournament contests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament id",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 3,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "only for groups knockout, 2 if not set",
+                        "name": "advance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only for double elimination",
+                        "name": "bracketReset",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 9007199254740991,
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "seed of shuffle and match IDs, random if not set",
+                        "name": "seed",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "random",
+                            "wins",
+                            "winrate"
+                        ],
+                        "type": "string",
+                        "name": "seeding",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 512,
+                        "minimum": 2,
+                        "type": "integer",
+                        "description": "tiktoks drawn for play, size of tournament if not set",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only for single elimination",
+                        "name": "thirdPlace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Contest bracket",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.Contest"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to return tournament contests",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "403": {
+                        "description": "Tournament is private",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tournament/contest/{tournamentId}/round": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Submit results of played rounds and get pairing of next round for contests paired by server (swiss)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Next round of tournament contest",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament id",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Contest type and results of played rounds",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ContestResults"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Next round and standings",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.Contest"
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to return next round",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "403": {
+                        "description": "Tournament is private",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tournament/create": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "APIKey": []
+                    }
+                ],
+                "description": "Create new tournament for current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Create new tournament",
+                "parameters": [
+                    {
+                        "description": "Data to create tournament",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreateTournament"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tournament created",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Error during tournament creation",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tournament/delete": {
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "APIKey": []
+                    }
+                ],
+                "description": "Delete tournaments for current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Delete tournaments",
+                "parameters": [
+                    {
+                        "description": "Data to delete tournaments",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.TournamentIds"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tournaments deleted",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Error during tournaments deletion",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tournament/delete/{tournamentId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKey": []
+                    }
+                ],
+                "description": "Delete tournament for current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Delete tournament",
+                "responses": {
+                    "200": {
+                        "description": "Tournament deleted",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Error during tournament deletion",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tournament/details/{tournamentId}": {
+            "get": {
+                "security": [
+                    {
+                        "APIKey": []
+                    }
+                ],
+                "description": "Get tournament details by its id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Tournament details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament id",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tournament",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.TournamentView"
+                        }
+                    },
+                    "400": {
+                        "description": "Tournament not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tournament/edit/{tournamentId}": {
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "APIKey": []
+                    }
+                ],
+                "description": "Edit tournament for current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Edit tournament",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament id",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Data to edit tournament",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.EditTournament"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tournament edited",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Error during tournament edition",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tournament/tierlist/{tournamentId}": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Place every tiktok of tournament into one of tournament tiers, tier lists are aggregated in tournament stats",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Submit tier list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament id",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Placements of all tiktoks",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.TierListSubmission"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Tier list submitted",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Error during tier list submitting",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tournament/tiktoks/{tournamentId}": {
+            "get": {
+                "security": [
+                    {
+                        "APIKey": []
+                    }
+                ],
+                "description": "Get tournament tiktoks",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Tournament tiktoks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament id",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort tiktoks by wins or rating",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tournament tiktoks",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.TournamentStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Tournament not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tournament/tiktoks/{tournamentId}/matrix": {
+            "get": {
+                "security": [
+                    {
+                        "APIKey": []
+                    }
+                ],
+                "description": "Get results of matches between every pair of tournament tiktoks",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Tournament head-to-head matrix",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament id",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Head-to-head matrix",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.TournamentMatrix"
+                        }
+                    },
+                    "400": {
+                        "description": "Tournament not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tournament/tournaments": {
+            "get": {
+                "security": [
+                    {
+                        "APIKey": []
+                    }
+                ],
+                "description": "Get all tournaments",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "All tournaments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "page size",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "All tournaments",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.TournamentsResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to get all tournaments",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tournament/winner/{tournamentId}": {
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Increment wins and increment times_played, if play session is provided its winner is only verified",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tournament"
+                ],
+                "summary": "Update tournament winner statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament id",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Data to update tournament winner",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.TournamentWinner"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Winner updated",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Error during winner updating",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests, retry after Retry-After seconds",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/me": {
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Delete current user with credentials, password and 2FA code are required.\nUser without password sends 2FA code or logs in with provider again right before deletion.\nTournaments are deleted with their tiktoks or anonymised, so they stay playable under deleted user.\nPlay sessions and tier lists of user are kept as anonymous",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Delete account",
+                "parameters": [
+                    {
+                        "description": "Credentials and policy of tournaments",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.DeleteAccount"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account deleted",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Error deleting account",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid 2FA code or login with provider is required",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/me/export": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Download profile, tournaments with tiktoks, play history and linked accounts of current user\nas JSON or as ZIP archive of JSON files",
+                "produces": [
+                    "application/json",
+                    "application/zip"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Export personal data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "json (default) or zip",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Personal data",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.AccountExport"
+                        }
+                    },
+                    "400": {
+                        "description": "Error exporting data",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/photo": {
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Change user photo for current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Change user photo",
+                "parameters": [
+                    {
+                        "description": "Data to change photo",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ChangePhotoURL"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Photo edited",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    },
+                    "400": {
+                        "description": "Error during photo change",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/profile/{userId}": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "APIKey": []
+                    }
+                ],
+                "description": "Get user information (tournaments, photo and etc.)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get user information",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "page size",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort page by name",
+                        "name": "sort_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort page by size",
+                        "name": "sort_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User information",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.TournamentsResponseWithUser"
+                        }
+                    },
+                    "400": {
+                        "description": "Couldn't user information for specific user",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/users": {
+            "get": {
+                "security": [
+                    {
+                        "APIKey": []
+                    }
+                ],
+                "description": "Get all users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "All users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "page size",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "All users",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.UsersResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Failed to get all users",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessageResponseType"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dtos.APIKeyView": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "key doesn't expire if not set",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "start of key to recognize it in list",
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.AccountExport": {
+            "type": "object",
+            "properties": {
+                "exportedAt": {
+                    "type": "string"
+                },
+                "identities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ExportedIdentity"
+                    }
+                },
+                "playSessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ExportedPlaySession"
+                    }
+                },
+                "profile": {
+                    "$ref": "#/definitions/dtos.ExportedProfile"
+                },
+                "tierLists": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.TierListView"
+                    }
+                },
+                "tournaments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ExportedTournament"
+                    }
+                }
+            }
+        },
+        "dtos.AuthInput": {
+            "type": "object",
+            "required": [
+                "name",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "description": "optional, required to reset password",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "description": "checked against password policy",
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.BanUser": {
+            "type": "object",
+            "properties": {
+                "isBanned": {
+                    "description": "false to unban user",
+                    "type": "boolean"
+                }
+            }
+        },
+        "dtos.ChangeEmailInput": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "code": {
+                    "description": "TOTP or recovery code, required if user has 2FA",
+                    "type": "string"
+                },
+                "email": {
+                    "description": "new email, changed after it is confirmed",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "required if user has password",
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ChangePasswordInput": {
+            "type": "object",
+            "required": [
+                "newPassword"
+            ],
+            "properties": {
+                "newPassword": {
+                    "type": "string"
+                },
+                "oldPassword": {
+                    "description": "required if user has password",
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ChangePhotoURL": {
+            "type": "object",
+            "required": [
+                "photoURL"
+            ],
+            "properties": {
+                "photoURL": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ChangeRole": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "user",
+                        "moderator",
+                        "admin"
+                    ]
+                }
+            }
+        },
+        "dtos.ConfirmEmailChange": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.Contest": {
+            "type": "object",
+            "properties": {
+                "countMatches": {
+                    "type": "integer"
+                },
+                "countRounds": {
+                    "description": "only for contests paired by server, where not all rounds are returned",
+                    "type": "integer"
+                },
+                "rounds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.Round"
+                    }
+                },
+                "seed": {
+                    "description": "contest generated with same seed from same tiktoks is the same",
+                    "type": "integer"
+                },
+                "standings": {
+                    "description": "only for contests with standings table (round robin, swiss, groups, full ranking)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.Standing"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ContestResults": {
+            "type": "object",
+            "required": [
+                "type"
+            ],
+            "properties": {
+                "participators": {
+                    "description": "URLs of tiktoks drawn for contest in order of its initial standings, all tournament tiktoks if not set",
+                    "type": "array",
+                    "maxItems": 512,
+                    "minItems": 2,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.MatchResult"
+                    }
+                },
+                "seed": {
+                    "description": "seed of contest, next round is paired with match IDs derived from it",
+                    "type": "integer",
+                    "maximum": 9007199254740991,
+                    "minimum": 0
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.CreateAPIKey": {
+            "type": "object",
+            "required": [
+                "name",
+                "scope"
+            ],
+            "properties": {
+                "expiresInDays": {
+                    "description": "key doesn't expire if not set",
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 1
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "scope": {
+                    "type": "string",
+                    "enum": [
+                        "read",
+                        "tournament:write"
+                    ]
+                }
+            }
+        },
+        "dtos.CreateTiktok": {
+            "type": "object",
+            "required": [
+                "name",
+                "url"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.CreateTournament": {
+            "type": "object",
+            "required": [
+                "name",
+                "photoURL",
+                "tiktoks"
+            ],
+            "properties": {
+                "bestOf": {
+                    "description": "1 by default, votes to decide match of single elimination and king of the hill",
+                    "type": "integer",
+                    "enum": [
+                        1,
+                        3,
+                        5
+                    ]
+                },
+                "isPrivate": {
+                    "description": "by default public, so we don't need this field to be required",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "photoURL": {
+                    "type": "string"
+                },
+                "ratingSystem": {
+                    "description": "elo by default",
+                    "type": "string",
+                    "enum": [
+                        "elo",
+                        "glicko2"
+                    ]
+                },
+                "size": {
+                    "description": "tiktoks drawn for every play, all tiktoks if not set",
+                    "type": "integer",
+                    "maximum": 512,
+                    "minimum": 2
+                },
+                "tiers": {
+                    "description": "S, A, B, C, D by default, tiers of tier lists ordered from best to worst",
+                    "type": "array",
+                    "maxItems": 10,
+                    "minItems": 2,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tiktoks": {
+                    "description": "pool of tiktoks",
+                    "type": "array",
+                    "maxItems": 512,
+                    "minItems": 2,
+                    "items": {
+                        "$ref": "#/definitions/dtos.CreateTiktok"
+                    }
+                }
+            }
+        },
+        "dtos.CreatedAPIKey": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "key doesn't expire if not set",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "description": "shown only once, send it in X-API-Key header",
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "start of key to recognize it in list",
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.DeleteAccount": {
+            "type": "object",
+            "required": [
+                "tournaments"
+            ],
+            "properties": {
+                "code": {
+                    "description": "TOTP or recovery code, required if user has 2FA",
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "password": {
+                    "description": "required if user has password",
+                    "type": "string"
+                },
+                "tournaments": {
+                    "type": "string",
+                    "enum": [
+                        "delete",
+                        "anonymise"
+                    ]
+                }
+            }
+        },
+        "dtos.EditTournament": {
+            "type": "object",
+            "required": [
+                "name",
+                "photoURL",
+                "tiktoks"
+            ],
+            "properties": {
+                "bestOf": {
+                    "description": "1 by default, votes to decide match of single elimination and king of the hill",
+                    "type": "integer",
+                    "enum": [
+                        1,
+                        3,
+                        5
+                    ]
+                },
+                "isPrivate": {
+                    "description": "by default public, so we don't need this field to be required",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "photoURL": {
+                    "type": "string"
+                },
+                "ratingSystem": {
+                    "description": "elo by default",
+                    "type": "string",
+                    "enum": [
+                        "elo",
+                        "glicko2"
+                    ]
+                },
+                "size": {
+                    "description": "tiktoks drawn for every play, all tiktoks if not set",
+                    "type": "integer",
+                    "maximum": 512,
+                    "minimum": 2
+                },
+                "tiers": {
+                    "description": "S, A, B, C, D by default, tiers of tier lists ordered from best to worst",
+                    "type": "array",
+                    "maxItems": 10,
+                    "minItems": 2,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tiktoks": {
+                    "description": "pool of tiktoks",
+                    "type": "array",
+                    "maxItems": 512,
+                    "minItems": 2,
+                    "items": {
+                        "$ref": "#/definitions/dtos.CreateTiktok"
+                    }
+                }
+            }
+        },
+        "dtos.ExportedIdentity": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ExportedPlaySession": {
+            "type": "object",
+            "properties": {
+                "contestType": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "decisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.MatchDecisionView"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "isCompleted": {
+                    "type": "boolean"
+                },
+                "secondURL": {
+                    "type": "string"
+                },
+                "thirdURL": {
+                    "type": "string"
+                },
+                "tournamentID": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userID": {
+                    "description": "empty for anonymous players",
+                    "type": "string"
+                },
+                "winnerURL": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ExportedProfile": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "photoURL": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "twoFactorEnabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dtos.ExportedTiktok": {
+            "type": "object",
+            "properties": {
+                "appearances": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "secondPlaces": {
+                    "type": "integer"
+                },
+                "thirdPlaces": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "wins": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.ExportedTournament": {
+            "type": "object",
+            "properties": {
+                "bestOf": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "isPrivate": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "photoURL": {
+                    "type": "string"
+                },
+                "ratingSystem": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "tiers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tiktoks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.ExportedTiktok"
+                    }
+                },
+                "timesPlayed": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                },
+                "y": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.JWKS": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.JWK"
+                    }
+                }
+            }
+        },
+        "dtos.LoginDetails": {
+            "type": "object",
+            "properties": {
+                "challengeToken": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "photoURL": {
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "twoFactorRequired": {
+                    "description": "set instead of tokens if user has 2FA, challenge token is exchanged for tokens with TOTP or recovery code",
+                    "type": "boolean"
+                }
+            }
+        },
+        "dtos.LoginInput": {
+            "type": "object",
+            "required": [
+                "name",
+                "password"
             ],
             "properties": {
                 "name": {
@@ -714,165 +3021,574 @@ const docTemplate = `{
                 }
             }
         },
-        "dtos.ChangePhotoURL": {
+        "dtos.LogoutInput": {
+            "type": "object",
+            "properties": {
+                "all": {
+                    "description": "revoke all refresh tokens of user, logout from every device",
+                    "type": "boolean"
+                },
+                "refreshToken": {
+                    "description": "optional, refresh token to revoke with access token",
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.Match": {
+            "type": "object",
+            "properties": {
+                "firstOption": {},
+                "ifNecessary": {
+                    "description": "played only if second option wins previous match (bracket reset)",
+                    "type": "boolean"
+                },
+                "matchID": {
+                    "type": "string"
+                },
+                "secondOption": {},
+                "winsRequired": {
+                    "description": "votes to win best-of-N match, 1 if not set",
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.MatchDecision": {
+            "type": "object",
+            "required": [
+                "matchID",
+                "winnerURL"
+            ],
+            "properties": {
+                "matchID": {
+                    "type": "string"
+                },
+                "winnerURL": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.MatchDecisionView": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "loserURL": {
+                    "type": "string"
+                },
+                "matchID": {
+                    "type": "string"
+                },
+                "winnerURL": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.MatchResult": {
+            "type": "object",
+            "required": [
+                "firstTiktokURL",
+                "secondTiktokURL",
+                "winnerURL"
+            ],
+            "properties": {
+                "firstTiktokURL": {
+                    "type": "string"
+                },
+                "matchID": {
+                    "type": "string"
+                },
+                "round": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "secondTiktokURL": {
+                    "type": "string"
+                },
+                "winnerURL": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.MatchVote": {
+            "type": "object",
+            "properties": {
+                "matchID": {
+                    "type": "string"
+                },
+                "tiktokURL": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.Matchup": {
+            "type": "object",
+            "properties": {
+                "firstTiktokURL": {
+                    "type": "string"
+                },
+                "firstWins": {
+                    "type": "integer"
+                },
+                "secondTiktokURL": {
+                    "type": "string"
+                },
+                "secondWins": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.MessageResponseType": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.OIDCLogin": {
+            "type": "object",
+            "properties": {
+                "authorizationURL": {
+                    "description": "user is redirected to provider with this URL",
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.OIDCProviders": {
+            "type": "object",
+            "properties": {
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dtos.PasswordReset": {
+            "type": "object",
+            "required": [
+                "newPassword",
+                "token"
+            ],
+            "properties": {
+                "newPassword": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.PasswordResetRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.PlaySessionDetails": {
+            "type": "object",
+            "properties": {
+                "contest": {
+                    "$ref": "#/definitions/dtos.Contest"
+                },
+                "decisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.MatchDecision"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "isCompleted": {
+                    "type": "boolean"
+                },
+                "secondURL": {
+                    "type": "string"
+                },
+                "thirdURL": {
+                    "description": "only for contests deciding third place",
+                    "type": "string"
+                },
+                "tournamentID": {
+                    "type": "string"
+                },
+                "votes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.MatchVote"
+                    }
+                },
+                "winnerURL": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.PublicUser": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "photoURL": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.RecoveryCodes": {
+            "type": "object",
+            "properties": {
+                "recoveryCodes": {
+                    "description": "shown only once, every code can be used once",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dtos.RefreshInput": {
+            "type": "object",
+            "required": [
+                "refreshToken"
+            ],
+            "properties": {
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.RegisterDetails": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.Round": {
+            "type": "object",
+            "properties": {
+                "bracket": {
+                    "type": "string"
+                },
+                "group": {
+                    "description": "only for group stage, rounds of all groups share round number",
+                    "type": "integer"
+                },
+                "matches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.Match"
+                    }
+                },
+                "round": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.Standing": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "description": "only for groups knockout, starting from 1",
+                    "type": "integer"
+                },
+                "losses": {
+                    "type": "integer"
+                },
+                "played": {
+                    "type": "integer"
+                },
+                "tiktokURL": {
+                    "type": "string"
+                },
+                "wins": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.TierListStats": {
+            "type": "object",
+            "properties": {
+                "countTierList": {
+                    "type": "integer"
+                },
+                "tiers": {
+                    "description": "ordered from best to worst",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tiktoksTiers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.TiktokTiers"
+                    }
+                }
+            }
+        },
+        "dtos.TierListSubmission": {
             "type": "object",
             "required": [
-                "photoURL"
+                "placements"
             ],
             "properties": {
-                "photoURL": {
-                    "type": "string"
+                "placements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.TierPlacement"
+                    }
                 }
             }
         },
-        "dtos.Contest": {
+        "dtos.TierListView": {
             "type": "object",
             "properties": {
-                "countMatches": {
-                    "type": "integer"
+                "createdAt": {
+                    "type": "string"
                 },
-                "rounds": {
+                "id": {
+                    "type": "string"
+                },
+                "placements": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dtos.Round"
+                        "$ref": "#/definitions/dtos.TierPlacementView"
                     }
+                },
+                "tournamentID": {
+                    "type": "string"
                 }
             }
         },
-        "dtos.CreateTiktok": {
+        "dtos.TierPlacement": {
             "type": "object",
             "required": [
-                "name",
-                "url"
+                "tier",
+                "tiktokURL"
             ],
             "properties": {
-                "name": {
+                "tier": {
                     "type": "string"
                 },
-                "url": {
+                "tiktokURL": {
                     "type": "string"
                 }
             }
         },
-        "dtos.CreateTournament": {
+        "dtos.TierPlacementView": {
             "type": "object",
-            "required": [
-                "isPrivate",
-                "name",
-                "photoURL",
-                "tiktoks"
-            ],
             "properties": {
-                "isPrivate": {
-                    "type": "boolean"
-                },
-                "name": {
+                "tier": {
                     "type": "string"
                 },
-                "photoURL": {
+                "tiktokURL": {
                     "type": "string"
-                },
-                "size": {
-                    "type": "integer",
-                    "maximum": 64,
-                    "minimum": 4
-                },
-                "tiktoks": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dtos.CreateTiktok"
-                    }
                 }
             }
         },
-        "dtos.EditTournament": {
+        "dtos.TiktokStats": {
             "type": "object",
-            "required": [
-                "isPrivate",
-                "name",
-                "photoURL",
-                "tiktoks"
-            ],
             "properties": {
-                "isPrivate": {
-                    "type": "boolean"
+                "appearances": {
+                    "description": "Count of tournament plays with tiktok",
+                    "type": "integer"
+                },
+                "averageRank": {
+                    "description": "Average place in complete rankings of full ranking play sessions, 0 if tiktok wasn't ranked",
+                    "type": "number"
+                },
+                "matchWins": {
+                    "type": "integer"
+                },
+                "matches": {
+                    "description": "Statistics of single matches of play sessions",
+                    "type": "integer"
                 },
                 "name": {
                     "type": "string"
                 },
-                "photoURL": {
+                "rankings": {
+                    "type": "integer"
+                },
+                "rating": {
+                    "description": "Rating after recorded matches, deviation is used only by Glicko-2",
+                    "type": "number"
+                },
+                "ratingDeviation": {
+                    "type": "number"
+                },
+                "secondPlaces": {
+                    "description": "Podium places of finished play sessions, first places are counted in wins",
+                    "type": "integer"
+                },
+                "thirdPlaces": {
+                    "type": "integer"
+                },
+                "url": {
                     "type": "string"
                 },
-                "size": {
-                    "type": "integer",
-                    "maximum": 64,
-                    "minimum": 4
+                "wins": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.TiktokTiers": {
+            "type": "object",
+            "properties": {
+                "consensusTier": {
+                    "description": "median tier, empty if tiktok wasn't placed",
+                    "type": "string"
                 },
-                "tiktoks": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dtos.CreateTiktok"
+                "distribution": {
+                    "description": "tier -\u003e count of placements",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
                     }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
                 }
             }
         },
-        "dtos.Match": {
+        "dtos.TokenDetails": {
             "type": "object",
             "properties": {
-                "firstOption": {},
-                "matchID": {
+                "refreshToken": {
                     "type": "string"
                 },
-                "secondOption": {}
+                "token": {
+                    "type": "string"
+                }
             }
         },
-        "dtos.MessageResponseType": {
+        "dtos.TournamentIds": {
             "type": "object",
+            "required": [
+                "tournamentIds"
+            ],
             "properties": {
-                "message": {
-                    "type": "string"
+                "tournamentIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "dtos.RegisterDetails": {
+        "dtos.TournamentMatrix": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "string"
+                "matchups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.Matchup"
+                    }
                 },
-                "name": {
-                    "type": "string"
+                "tiktokURLs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "token": {
+                "tournamentId": {
                     "type": "string"
+                },
+                "winRates": {
+                    "description": "part of matches between tiktoks i and j won by i, -1 if they never met",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number"
+                        }
+                    }
                 }
             }
         },
-        "dtos.Round": {
+        "dtos.TournamentStats": {
             "type": "object",
             "properties": {
-                "matches": {
+                "ratingSystem": {
+                    "type": "string"
+                },
+                "tierList": {
+                    "$ref": "#/definitions/dtos.TierListStats"
+                },
+                "tiktoksStats": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dtos.Match"
+                        "$ref": "#/definitions/dtos.TiktokStats"
                     }
                 },
-                "round": {
-                    "type": "integer"
+                "tournamentId": {
+                    "type": "string"
                 }
             }
         },
-        "dtos.TournamentIds": {
+        "dtos.TournamentView": {
             "type": "object",
-            "required": [
-                "tournamentIds"
-            ],
             "properties": {
-                "tournamentIds": {
+                "bestOf": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "isPrivate": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "photoURL": {
+                    "type": "string"
+                },
+                "ratingSystem": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "tiers": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
+                },
+                "timesPlayed": {
+                    "type": "integer"
+                },
+                "user": {
+                    "$ref": "#/definitions/dtos.PublicUser"
+                },
+                "userID": {
+                    "type": "string"
                 }
             }
         },
@@ -882,6 +3598,20 @@ const docTemplate = `{
                 "tiktokURL"
             ],
             "properties": {
+                "participators": {
+                    "description": "URLs of tiktoks drawn for play, used to count appearances without play session, all tiktoks if not set",
+                    "type": "array",
+                    "maxItems": 512,
+                    "minItems": 2,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "sessionID": {
+                    "description": "optional, winner must match completed play session",
+                    "type": "string"
+                },
                 "tiktokURL": {
                     "type": "string"
                 }
@@ -923,7 +3653,7 @@ const docTemplate = `{
                 "tournaments": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Tournament"
+                        "$ref": "#/definitions/dtos.TournamentView"
                     }
                 }
             }
@@ -946,95 +3676,98 @@ const docTemplate = `{
                     }
                 },
                 "user": {
-                    "$ref": "#/definitions/models.User"
+                    "$ref": "#/definitions/dtos.PublicUser"
                 }
             }
         },
-        "dtos.UsersResponse": {
+        "dtos.TwoFactorCode": {
             "type": "object",
             "required": [
-                "userCount",
-                "users"
+                "code"
             ],
             "properties": {
-                "userCount": {
-                    "type": "integer"
-                },
-                "users": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.User"
-                    }
+                "code": {
+                    "description": "TOTP code, recovery code is accepted where noted",
+                    "type": "string",
+                    "maxLength": 32
                 }
             }
         },
-        "dtos.WhoAmI": {
+        "dtos.TwoFactorDisable": {
             "type": "object",
+            "required": [
+                "code"
+            ],
             "properties": {
-                "id": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "photoURL": {
-                    "type": "string"
+                "code": {
+                    "description": "TOTP or recovery code",
+                    "type": "string",
+                    "maxLength": 32
                 },
-                "token": {
+                "password": {
+                    "description": "required if user has password",
                     "type": "string"
                 }
             }
         },
-        "models.Tiktok": {
+        "dtos.TwoFactorEnrollInput": {
             "type": "object",
             "properties": {
-                "name": {
-                    "type": "string"
-                },
-                "tournament": {
-                    "$ref": "#/definitions/models.Tournament"
-                },
-                "tournamentID": {
-                    "type": "string"
-                },
-                "url": {
+                "password": {
+                    "description": "required if user has password",
                     "type": "string"
-                },
-                "wins": {
-                    "type": "integer"
                 }
             }
         },
-        "models.Tournament": {
+        "dtos.TwoFactorEnrollment": {
             "type": "object",
             "properties": {
-                "id": {
+                "otpauthURI": {
+                    "description": "shown as QR code to add account to authenticator app",
                     "type": "string"
                 },
-                "isPrivate": {
-                    "type": "boolean"
-                },
-                "name": {
+                "secret": {
+                    "description": "base32 secret for manual entry",
                     "type": "string"
-                },
-                "photoURL": {
+                }
+            }
+        },
+        "dtos.TwoFactorLogin": {
+            "type": "object",
+            "required": [
+                "challengeToken",
+                "code"
+            ],
+            "properties": {
+                "challengeToken": {
                     "type": "string"
                 },
-                "size": {
-                    "type": "integer"
-                },
-                "timesPlayed": {
+                "code": {
+                    "description": "TOTP or recovery code",
+                    "type": "string",
+                    "maxLength": 32
+                }
+            }
+        },
+        "dtos.UsersResponse": {
+            "type": "object",
+            "required": [
+                "userCount",
+                "users"
+            ],
+            "properties": {
+                "userCount": {
                     "type": "integer"
                 },
-                "user": {
-                    "$ref": "#/definitions/models.User"
-                },
-                "userID": {
-                    "type": "string"
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.PublicUser"
+                    }
                 }
             }
         },
-        "models.User": {
+        "dtos.WhoAmI": {
             "type": "object",
             "properties": {
                 "id": {
@@ -1043,16 +3776,21 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
-                "password": {
+                "photoURL": {
                     "type": "string"
                 },
-                "photoURL": {
+                "token": {
                     "type": "string"
                 }
             }
         }
     },
     "securityDefinitions": {
+        "APIKey": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
         "JWT": {
             "type": "apiKey",
             "name": "Authorization",
@@ -4,13 +4,19 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/google/uuid"
 	"log"
 	"tiktok-arena/configuration"
 	"tiktok-arena/internal/api/controllers"
 	"tiktok-arena/internal/api/middleware"
 	"tiktok-arena/internal/api/routers"
+	"tiktok-arena/internal/core/keys"
+	"tiktok-arena/internal/core/models"
 	"tiktok-arena/internal/core/services"
+	"tiktok-arena/internal/core/validator"
 	"tiktok-arena/internal/data/database"
+	"tiktok-arena/internal/data/mailer"
+	"tiktok-arena/internal/data/oidc"
 	"tiktok-arena/internal/data/repository"
 )
 
@@ -18,25 +24,93 @@ func Run(c *configuration.EnvConfigModel) {
 	// Create connection to DB
 	db := database.ConnectDB(c)
 
+	// Password policy checked on registration and password change
+	validator.SetPasswordPolicy(validator.PasswordPolicy{
+		MinLength:        c.PasswordMinLength,
+		RequireDigit:     c.PasswordRequireDigit,
+		RequireUppercase: c.PasswordRequireUppercase,
+		RequireSymbol:    c.PasswordRequireSymbol,
+	})
+
+	// Load keys to sign and verify JWT
+	_, err := keys.Get()
+	if err != nil {
+		log.Fatal("Failed to load JWT keys!\n", err.Error())
+	}
+
 	// Create repositories to access DB
 	userRepository := repository.NewUserRepository(db)
 	tiktokRepository := repository.NewTiktokRepository(db)
 	tournamentRepository := repository.NewTournamentRepository(db)
+	playSessionRepository := repository.NewPlaySessionRepository(db)
+	matchupRepository := repository.NewMatchupRepository(db)
+	tierListRepository := repository.NewTierListRepository(db)
+	tokenRepository := repository.NewTokenRepository(db)
+	identityRepository := repository.NewIdentityRepository(db)
+	apiKeyRepository := repository.NewAPIKeyRepository(db)
+	accountRepository := repository.NewAccountRepository(db)
+
+	// Create clients of OpenID Connect providers
+	oidcProviders, err := oidc.NewProviders(c.OIDCProviders)
+	if err != nil {
+		log.Fatal("Failed to configure OIDC providers!\n", err.Error())
+	}
+	identityProviders := make(map[string]services.IdentityProvider, len(oidcProviders))
+	for name, provider := range oidcProviders {
+		identityProviders[name] = provider
+	}
+
+	// Grant admin role to explicitly configured user, so admins can be managed through API,
+	// user is found by ID, because names of deleted users can be registered again
+	if c.AdminUserID != "" {
+		adminId, err := uuid.Parse(c.AdminUserID)
+		if err != nil {
+			log.Fatal("Invalid ADMIN_USER_ID!\n", err.Error())
+		}
+		granted, err := userRepository.SetUserRole(adminId, models.RoleAdmin)
+		if err != nil {
+			log.Fatal("Failed to grant admin role!\n", err.Error())
+		}
+		if !granted {
+			log.Printf("Admin %s is not registered yet", adminId)
+		}
+	}
 
 	// Create service layer
 	userService := services.NewUserService(userRepository, tournamentRepository)
-	authService := services.NewAuthService(userRepository)
-	tournamentService := services.NewTournamentService(tournamentRepository, tiktokRepository, userRepository)
+	authService := services.NewAuthService(userRepository, tokenRepository, mailer.NewLogMailer(c.MailFilePath))
+	tournamentService := services.NewTournamentService(tournamentRepository, tiktokRepository, userRepository, playSessionRepository, matchupRepository, tierListRepository)
+	playSessionService := services.NewPlaySessionService(playSessionRepository, tiktokRepository, tournamentRepository)
+	adminService := services.NewAdminService(tournamentRepository, userRepository, tokenRepository)
+	oidcService := services.NewOIDCService(identityProviders, identityRepository, authService)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepository, userRepository)
+	accountService := services.NewAccountService(accountRepository, authService)
 
 	// Create controller layer
 	authController := controllers.NewAuthController(authService)
 	userController := controllers.NewUserController(userService)
 	tournamentController := controllers.NewTournamentController(tournamentService)
+	playSessionController := controllers.NewPlaySessionController(playSessionService)
+	adminController := controllers.NewAdminController(adminService)
+	oidcController := controllers.NewOIDCController(oidcService)
+	apiKeyController := controllers.NewAPIKeyController(apiKeyService)
+	accountController := controllers.NewAccountController(accountService)
 
 	// Create routers for unprotected and protected routes
 	authRouter := routers.NewAuthRouter(authController)
+	oidcRouter := routers.NewOIDCRouter(oidcController)
+	apiKeyRouter := routers.NewAPIKeyRouter(apiKeyController)
+	accountRouter := routers.NewAccountRouter(accountController)
 	tournamentRouter := routers.NewTournamentRouter(tournamentController)
 	userRouter := routers.NewUserRouter(userController)
+	playSessionRouter := routers.NewPlaySessionRouter(playSessionController)
+	adminRouter := routers.NewAdminRouter(adminController)
+	wellKnownRouter := routers.NewWellKnownRouter(authController)
+
+	// Revoked access tokens are rejected by JWT middleware
+	middleware.SetRevocationList(authService)
+	// API keys are accepted instead of JWT on routes which allow their scope
+	middleware.SetAPIKeyAuthenticator(apiKeyService)
 
 	// ErrorHandler middleware
 	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
@@ -44,19 +118,35 @@ func Run(c *configuration.EnvConfigModel) {
 	//	Logger middleware for logging HTTP request/response details
 	app.Use(logger.New())
 
-	//	CORS middleware
-	app.Use(cors.New(cors.Config{
+	//	CORS middleware, browsers send credentials only to explicit origins and headers, not to wildcards
+	corsConfig := cors.Config{
 		AllowOrigins: "*",
 		AllowHeaders: "*",
-	}))
+	}
+	if origins := configuration.EnvConfig.CORSOrigins; origins != "" {
+		corsConfig = cors.Config{
+			AllowOrigins:     origins,
+			AllowHeaders:     "Origin, Content-Type, Accept, Authorization, " + middleware.APIKeyHeader,
+			AllowCredentials: true,
+		}
+	}
+	app.Use(cors.New(corsConfig))
 
 	// Get group routes
 	groupRoutes := routers.GetGroupRoutes(app)
 
 	// Setup unprotected routes
 	authRouter(groupRoutes.AuthGroup)
+	oidcRouter(groupRoutes.OIDCGroup)
+	apiKeyRouter(groupRoutes.APIKeyGroup)
 	userRouter(groupRoutes.UserGroup)
+	accountRouter(groupRoutes.UserGroup)
 	tournamentRouter(groupRoutes.TournamentGroup)
+	playSessionRouter(groupRoutes.SessionGroup)
+	wellKnownRouter(groupRoutes.WellKnownGroup)
+
+	// Setup routes protected by role
+	adminRouter(groupRoutes.AdminGroup)
 
 	log.Fatal(app.Listen(":8000"))
 }
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"tiktok-arena/internal/api/controllers/response"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/validator"
+)
+
+type AccountService interface {
+	DeleteAccount(token jwt.Token, input dtos.DeleteAccount) error
+	ExportAccount(userId uuid.UUID, queries dtos.ExportQueries) (export dtos.AccountExport, err error)
+	ExportArchive(export dtos.AccountExport) ([]byte, error)
+}
+
+type AccountController struct {
+	AccountService AccountService
+}
+
+func NewAccountController(accountService AccountService) *AccountController {
+	return &AccountController{AccountService: accountService}
+}
+
+// DeleteAccount
+//
+//	@Summary		Delete account
+//	@Description	Delete current user with credentials, password and 2FA code are required.
+//	@Description	User without password sends 2FA code or logs in with provider again right before deletion.
+//	@Description	Tournaments are deleted with their tiktoks or anonymised, so they stay playable under deleted user.
+//	@Description	Play sessions and tier lists of user are kept as anonymous
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			payload			body		dtos.DeleteAccount			true	"Credentials and policy of tournaments"
+//	@Success		200				{object}	dtos.MessageResponseType	"Account deleted"
+//	@Failure		400				{object}	dtos.MessageResponseType	"Error deleting account"
+//	@Failure		401				{object}	dtos.MessageResponseType	"Invalid 2FA code or login with provider is required"
+//	@Router			/api/user/me	[delete]
+func (cr *AccountController) DeleteAccount(c *fiber.Ctx) error {
+	token := c.Locals("user").(*jwt.Token)
+
+	var payload dtos.DeleteAccount
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	err = cr.AccountService.DeleteAccount(*token, payload)
+	if err != nil {
+		return err
+	}
+
+	return response.MessageResponse(c, fiber.StatusOK, "Successfully deleted account")
+}
+
+// ExportAccount
+//
+//	@Summary		Export personal data
+//	@Description	Download profile, tournaments with tiktoks, play history and linked accounts of current user
+//	@Description	as JSON or as ZIP archive of JSON files
+//	@Tags			user
+//	@Produce		json
+//	@Produce		application/zip
+//	@Security		JWT
+//	@Param			format				query		string						false	"json (default) or zip"
+//	@Success		200					{object}	dtos.AccountExport			"Personal data"
+//	@Failure		400					{object}	dtos.MessageResponseType	"Error exporting data"
+//	@Router			/api/user/me/export	[get]
+func (cr *AccountController) ExportAccount(c *fiber.Ctx) error {
+	userId, err := validator.GetUserIdAndCheckJWT(c.Locals("user"))
+	if err != nil {
+		return err
+	}
+
+	var queries dtos.ExportQueries
+	err = c.QueryParser(&queries)
+	if err != nil {
+		return err
+	}
+
+	export, err := cr.AccountService.ExportAccount(userId, queries)
+	if err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("tiktok-arena-export-%s", export.ExportedAt.Format("20060102"))
+	if queries.Format != dtos.ExportZIP {
+		c.Attachment(filename + ".json")
+		return c.Status(fiber.StatusOK).JSON(export)
+	}
+
+	archive, err := cr.AccountService.ExportArchive(export)
+	if err != nil {
+		return err
+	}
+	c.Attachment(filename + ".zip")
+	c.Set(fiber.HeaderContentType, "application/zip")
+	return c.Status(fiber.StatusOK).Send(archive)
+}
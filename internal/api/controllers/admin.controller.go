@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"tiktok-arena/internal/api/controllers/response"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/validator"
+)
+
+type AdminService interface {
+	GetTournaments(queries dtos.PaginationQueries) (response dtos.TournamentsResponse, err error)
+	DeleteTournament(tournamentIdString string) error
+	ResetTournamentStats(tournamentIdString string) error
+	BanUser(actorRole string, userIdString string, ban dtos.BanUser) error
+	ChangeUserRole(userIdString string, change dtos.ChangeRole) error
+}
+
+type AdminController struct {
+	AdminService AdminService
+}
+
+func NewAdminController(adminService AdminService) *AdminController {
+	return &AdminController{AdminService: adminService}
+}
+
+// GetAllTournaments
+//
+//	@Summary		All tournaments for moderation
+//	@Description	Get tournaments of all users including private ones, moderator role is required
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			page						query		string						false	"page number"
+//	@Param			count						query		string						false	"page size"
+//	@Param			search						query		string						false	"search"
+//	@Success		200							{object}	dtos.TournamentsResponse	"All tournaments"
+//	@Failure		403							{object}	dtos.MessageResponseType	"Role is not allowed"
+//	@Router			/api/admin/tournaments		[get]
+func (cr *AdminController) GetAllTournaments(c *fiber.Ctx) error {
+	q := new(dtos.PaginationQueries)
+	if err := c.QueryParser(q); err != nil {
+		return err
+	}
+	dtos.ValidatePaginationQueries(q)
+	tournamentResponse, err := cr.AdminService.GetTournaments(*q)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(tournamentResponse)
+}
+
+// DeleteTournament
+//
+//	@Summary		Delete any tournament
+//	@Description	Delete tournament of any user with its play sessions and statistics, moderator role is required
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			tournamentId							path		string						true	"Tournament id"
+//	@Success		200										{object}	dtos.MessageResponseType	"Tournament deleted"
+//	@Failure		400										{object}	dtos.MessageResponseType	"Error during tournament deletion"
+//	@Failure		403										{object}	dtos.MessageResponseType	"Role is not allowed"
+//	@Router			/api/admin/tournaments/{tournamentId}	[delete]
+func (cr *AdminController) DeleteTournament(c *fiber.Ctx) error {
+	tournamentIdString := c.Params("tournamentId")
+	err := cr.AdminService.DeleteTournament(tournamentIdString)
+	if err != nil {
+		return err
+	}
+
+	return response.MessageResponse(c, fiber.StatusOK,
+		fmt.Sprintf("Successfully deleted tournament %s", tournamentIdString))
+}
+
+// ResetTournamentStats
+//
+//	@Summary		Reset tournament stats
+//	@Description	Reset wins, places and ratings of tiktoks and delete matchups, rankings and tier lists of tournament, admin role is required
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			tournamentId									path		string						true	"Tournament id"
+//	@Success		200												{object}	dtos.MessageResponseType	"Stats reset"
+//	@Failure		400												{object}	dtos.MessageResponseType	"Error during stats reset"
+//	@Failure		403												{object}	dtos.MessageResponseType	"Role is not allowed"
+//	@Router			/api/admin/tournaments/{tournamentId}/stats		[delete]
+func (cr *AdminController) ResetTournamentStats(c *fiber.Ctx) error {
+	tournamentIdString := c.Params("tournamentId")
+	err := cr.AdminService.ResetTournamentStats(tournamentIdString)
+	if err != nil {
+		return err
+	}
+
+	return response.MessageResponse(c, fiber.StatusOK,
+		fmt.Sprintf("Successfully reset stats of tournament %s", tournamentIdString))
+}
+
+// BanUser
+//
+//	@Summary		Ban user
+//	@Description	Ban or unban user with lower role, tokens of banned user are revoked, moderator role is required
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			userId							path		string						true	"User id"
+//	@Param			payload							body		dtos.BanUser				true	"Ban or unban"
+//	@Success		200								{object}	dtos.MessageResponseType	"User banned or unbanned"
+//	@Failure		400								{object}	dtos.MessageResponseType	"Error during ban"
+//	@Failure		403								{object}	dtos.MessageResponseType	"Role is not allowed"
+//	@Router			/api/admin/users/{userId}/ban	[put]
+func (cr *AdminController) BanUser(c *fiber.Ctx) error {
+	var payload dtos.BanUser
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	userIdString := c.Params("userId")
+	err = cr.AdminService.BanUser(validator.GetUserRole(c.Locals("user")), userIdString, payload)
+	if err != nil {
+		return err
+	}
+
+	if !payload.IsBanned {
+		return response.MessageResponse(c, fiber.StatusOK, fmt.Sprintf("Successfully unbanned user %s", userIdString))
+	}
+	return response.MessageResponse(c, fiber.StatusOK, fmt.Sprintf("Successfully banned user %s", userIdString))
+}
+
+// ChangeUserRole
+//
+//	@Summary		Change user role
+//	@Description	Set role of user (user, moderator or admin), access tokens with old role are revoked, admin role is required
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			userId							path		string						true	"User id"
+//	@Param			payload							body		dtos.ChangeRole				true	"New role"
+//	@Success		200								{object}	dtos.MessageResponseType	"Role changed"
+//	@Failure		400								{object}	dtos.MessageResponseType	"Error during role change"
+//	@Failure		403								{object}	dtos.MessageResponseType	"Role is not allowed"
+//	@Router			/api/admin/users/{userId}/role	[put]
+func (cr *AdminController) ChangeUserRole(c *fiber.Ctx) error {
+	var payload dtos.ChangeRole
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	userIdString := c.Params("userId")
+	err = cr.AdminService.ChangeUserRole(userIdString, payload)
+	if err != nil {
+		return err
+	}
+
+	return response.MessageResponse(c, fiber.StatusOK,
+		fmt.Sprintf("Successfully changed role of user %s to %s", userIdString, payload.Role))
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"tiktok-arena/internal/api/controllers/response"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/validator"
+)
+
+type APIKeyService interface {
+	CreateAPIKey(userId uuid.UUID, input dtos.CreateAPIKey) (created dtos.CreatedAPIKey, err error)
+	GetAPIKeys(userId uuid.UUID) ([]dtos.APIKeyView, error)
+	RevokeAPIKey(userId uuid.UUID, keyIdString string) error
+}
+
+type APIKeyController struct {
+	APIKeyService APIKeyService
+}
+
+func NewAPIKeyController(apiKeyService APIKeyService) *APIKeyController {
+	return &APIKeyController{APIKeyService: apiKeyService}
+}
+
+// CreateAPIKey
+//
+//	@Summary		Create API key
+//	@Description	Create personal API key for scripts, key is sent in X-API-Key header and is shown only once.
+//	@Description	Scope read allows reading, scope tournament:write also allows to create, edit and delete tournaments
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			payload			body		dtos.CreateAPIKey			true	"Name and scope of key"
+//	@Success		201				{object}	dtos.CreatedAPIKey			"Created key"
+//	@Failure		400				{object}	dtos.MessageResponseType	"Error creating key"
+//	@Router			/api/auth/keys	[post]
+func (cr *APIKeyController) CreateAPIKey(c *fiber.Ctx) error {
+	userId, err := validator.GetUserIdAndCheckJWT(c.Locals("user"))
+	if err != nil {
+		return err
+	}
+
+	var payload dtos.CreateAPIKey
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	created, err := cr.APIKeyService.CreateAPIKey(userId, payload)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
+
+// GetAPIKeys
+//
+//	@Summary		API keys of user
+//	@Description	Get API keys of current user without keys themselves
+//	@Tags			auth
+//	@Produce		json
+//	@Security		JWT
+//	@Success		200				{array}		dtos.APIKeyView				"Keys of user"
+//	@Failure		400				{object}	dtos.MessageResponseType	"Error getting keys"
+//	@Router			/api/auth/keys	[get]
+func (cr *APIKeyController) GetAPIKeys(c *fiber.Ctx) error {
+	userId, err := validator.GetUserIdAndCheckJWT(c.Locals("user"))
+	if err != nil {
+		return err
+	}
+
+	keys, err := cr.APIKeyService.GetAPIKeys(userId)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(keys)
+}
+
+// RevokeAPIKey
+//
+//	@Summary		Revoke API key
+//	@Description	Delete API key of current user, key is not accepted anymore
+//	@Tags			auth
+//	@Produce		json
+//	@Security		JWT
+//	@Param			keyId					path		string						true	"Key id"
+//	@Success		200						{object}	dtos.MessageResponseType	"Key revoked"
+//	@Failure		404						{object}	dtos.MessageResponseType	"Key doesn't exist"
+//	@Router			/api/auth/keys/{keyId}	[delete]
+func (cr *APIKeyController) RevokeAPIKey(c *fiber.Ctx) error {
+	userId, err := validator.GetUserIdAndCheckJWT(c.Locals("user"))
+	if err != nil {
+		return err
+	}
+
+	err = cr.APIKeyService.RevokeAPIKey(userId, c.Params("keyId"))
+	if err != nil {
+		return err
+	}
+
+	return response.MessageResponse(c, fiber.StatusOK, "Successfully revoked API key")
+}
@@ -3,13 +3,29 @@ package controllers
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"tiktok-arena/internal/api/controllers/response"
 	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/validator"
 )
 
 type AuthService interface {
 	NewUser(auth dtos.AuthInput) (details dtos.RegisterDetails, err error)
-	GetUserByNameAndPassword(input dtos.AuthInput) (details dtos.LoginDetails, err error)
+	GetUserByNameAndPassword(input dtos.LoginInput) (details dtos.LoginDetails, err error)
 	WhoAmI(token jwt.Token) (whoami dtos.WhoAmI, err error)
+	RefreshToken(input dtos.RefreshInput) (details dtos.TokenDetails, err error)
+	Logout(token jwt.Token, input dtos.LogoutInput) error
+	ChangePassword(token jwt.Token, input dtos.ChangePasswordInput) (details dtos.TokenDetails, err error)
+	RequestEmailChange(userId uuid.UUID, input dtos.ChangeEmailInput) error
+	ConfirmEmailChange(input dtos.ConfirmEmailChange) error
+	RequestPasswordReset(input dtos.PasswordResetRequest) error
+	ResetPassword(input dtos.PasswordReset) error
+	JWKS() (jwks dtos.JWKS, err error)
+	LoginTwoFactor(input dtos.TwoFactorLogin) (details dtos.LoginDetails, err error)
+	EnrollTwoFactor(userId uuid.UUID, input dtos.TwoFactorEnrollInput) (enrollment dtos.TwoFactorEnrollment, err error)
+	VerifyTwoFactor(userId uuid.UUID, input dtos.TwoFactorCode) (codes dtos.RecoveryCodes, err error)
+	DisableTwoFactor(userId uuid.UUID, input dtos.TwoFactorDisable) error
+	RegenerateRecoveryCodes(userId uuid.UUID, input dtos.TwoFactorCode) (codes dtos.RecoveryCodes, err error)
 }
 
 type AuthController struct {
@@ -54,12 +70,13 @@ func (cr *AuthController) RegisterUser(c *fiber.Ctx) error {
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
-//	@Param			payload				body		dtos.AuthInput				true	"Data to login user"
-//	@Success		200					{object}	dtos.RegisterDetails		"Login success"
+//	@Param			payload				body		dtos.LoginInput				true	"Data to login user"
+//	@Success		200					{object}	dtos.LoginDetails			"Login success or challenge token if user has 2FA"
 //	@Failure		400					{object}	dtos.MessageResponseType	"Error logging in"
+//	@Failure		429					{object}	dtos.MessageResponseType	"Too many failed logins, retry after Retry-After seconds"
 //	@Router			/api/auth/login    	[post]
 func (cr *AuthController) LoginUser(c *fiber.Ctx) error {
-	var payload dtos.AuthInput
+	var payload dtos.LoginInput
 
 	err := c.BodyParser(&payload)
 	if err != nil {
@@ -96,3 +113,380 @@ func (cr *AuthController) WhoAmI(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(whoami)
 }
+
+// RefreshToken
+//
+//	@Summary		Refresh access token
+//	@Description	Get new access token with refresh token, refresh token is rotated and can't be used again
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload				body		dtos.RefreshInput			true	"Refresh token"
+//	@Success		200					{object}	dtos.TokenDetails			"New access and refresh tokens"
+//	@Failure		401					{object}	dtos.MessageResponseType	"Invalid or expired refresh token"
+//	@Router			/api/auth/refresh	[post]
+func (cr *AuthController) RefreshToken(c *fiber.Ctx) error {
+	var payload dtos.RefreshInput
+
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	details, err := cr.AuthService.RefreshToken(payload)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(details)
+}
+
+// Logout
+//
+//	@Summary		Logout user
+//	@Description	Revoke current access token and given refresh token or all refresh tokens of user
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			payload				body		dtos.LogoutInput			false	"Refresh tokens to revoke"
+//	@Success		200					{object}	dtos.MessageResponseType	"Logout success"
+//	@Failure		400					{object}	dtos.MessageResponseType	"Error logging out"
+//	@Router			/api/auth/logout	[post]
+func (cr *AuthController) Logout(c *fiber.Ctx) error {
+	token := c.Locals("user").(*jwt.Token)
+
+	var payload dtos.LogoutInput
+	if len(c.Body()) != 0 {
+		err := c.BodyParser(&payload)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := cr.AuthService.Logout(*token, payload)
+	if err != nil {
+		return err
+	}
+
+	return response.MessageResponse(c, fiber.StatusOK, "Successfully logged out")
+}
+
+// ChangePassword
+//
+//	@Summary		Change password
+//	@Description	Change password of current user, old password is required, user is logged out on all devices
+//	@Description	and gets new tokens for current device
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			payload				body		dtos.ChangePasswordInput	true	"Old and new password"
+//	@Success		200					{object}	dtos.TokenDetails			"Password changed, new tokens"
+//	@Failure		400					{object}	dtos.MessageResponseType	"Error changing password"
+//	@Router			/api/auth/password	[put]
+func (cr *AuthController) ChangePassword(c *fiber.Ctx) error {
+	token := c.Locals("user").(*jwt.Token)
+
+	var payload dtos.ChangePasswordInput
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	details, err := cr.AuthService.ChangePassword(*token, payload)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(details)
+}
+
+// RequestEmailChange
+//
+//	@Summary		Change email
+//	@Description	Send one-time confirmation token to new email of current user, password is required,
+//	@Description	email is changed when token is confirmed
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			payload				body		dtos.ChangeEmailInput		true	"New email, password and 2FA code"
+//	@Success		200					{object}	dtos.MessageResponseType	"Confirmation token sent"
+//	@Failure		400					{object}	dtos.MessageResponseType	"Error changing email"
+//	@Router			/api/auth/email		[put]
+func (cr *AuthController) RequestEmailChange(c *fiber.Ctx) error {
+	userId, err := validator.GetUserIdAndCheckJWT(c.Locals("user"))
+	if err != nil {
+		return err
+	}
+
+	var payload dtos.ChangeEmailInput
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	err = cr.AuthService.RequestEmailChange(userId, payload)
+	if err != nil {
+		return err
+	}
+
+	return response.MessageResponse(c, fiber.StatusOK, "Confirmation token is sent to new email")
+}
+
+// ConfirmEmailChange
+//
+//	@Summary		Confirm email change
+//	@Description	Set new email of user with one-time confirmation token sent to it
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload						body		dtos.ConfirmEmailChange		true	"Confirmation token"
+//	@Success		200							{object}	dtos.MessageResponseType	"Email changed"
+//	@Failure		400							{object}	dtos.MessageResponseType	"Invalid confirmation token"
+//	@Router			/api/auth/email/confirm		[post]
+func (cr *AuthController) ConfirmEmailChange(c *fiber.Ctx) error {
+	var payload dtos.ConfirmEmailChange
+
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	err = cr.AuthService.ConfirmEmailChange(payload)
+	if err != nil {
+		return err
+	}
+
+	return response.MessageResponse(c, fiber.StatusOK, "Successfully changed email")
+}
+
+// RequestPasswordReset
+//
+//	@Summary		Request password reset
+//	@Description	Send one-time password reset token to email of user, response doesn't expose if user exists
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload						body		dtos.PasswordResetRequest	true	"Name of user"
+//	@Success		200							{object}	dtos.MessageResponseType	"Reset token sent if user has email"
+//	@Failure		400							{object}	dtos.MessageResponseType	"Error requesting password reset"
+//	@Router			/api/auth/password/forgot	[post]
+func (cr *AuthController) RequestPasswordReset(c *fiber.Ctx) error {
+	var payload dtos.PasswordResetRequest
+
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	err = cr.AuthService.RequestPasswordReset(payload)
+	if err != nil {
+		return err
+	}
+
+	return response.MessageResponse(c, fiber.StatusOK, "Password reset token is sent if user has email")
+}
+
+// ResetPassword
+//
+//	@Summary		Reset password
+//	@Description	Set new password with one-time password reset token, user is logged out on all devices
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload						body		dtos.PasswordReset			true	"Reset token and new password"
+//	@Success		200							{object}	dtos.MessageResponseType	"Password reset"
+//	@Failure		400							{object}	dtos.MessageResponseType	"Invalid reset token or password"
+//	@Router			/api/auth/password/reset	[post]
+func (cr *AuthController) ResetPassword(c *fiber.Ctx) error {
+	var payload dtos.PasswordReset
+
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	err = cr.AuthService.ResetPassword(payload)
+	if err != nil {
+		return err
+	}
+
+	return response.MessageResponse(c, fiber.StatusOK, "Successfully reset password")
+}
+
+// JWKS
+//
+//	@Summary		JSON Web Key Set
+//	@Description	Get public keys which verify JWT issued by arena, key is selected by "kid" header of JWT
+//	@Tags			auth
+//	@Produce		json
+//	@Success		200						{object}	dtos.JWKS					"Public keys"
+//	@Failure		400						{object}	dtos.MessageResponseType	"Error loading keys"
+//	@Router			/.well-known/jwks.json	[get]
+func (cr *AuthController) JWKS(c *fiber.Ctx) error {
+	jwks, err := cr.AuthService.JWKS()
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(jwks)
+}
+
+// LoginTwoFactor
+//
+//	@Summary		Second login step
+//	@Description	Exchange challenge token returned by login of user with 2FA for tokens, TOTP or recovery code is required
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload					body		dtos.TwoFactorLogin			true	"Challenge token and code"
+//	@Success		200						{object}	dtos.LoginDetails			"Login success"
+//	@Failure		401						{object}	dtos.MessageResponseType	"Invalid code or expired challenge"
+//	@Failure		429						{object}	dtos.MessageResponseType	"Too many failed logins, retry after Retry-After seconds"
+//	@Router			/api/auth/2fa/login		[post]
+func (cr *AuthController) LoginTwoFactor(c *fiber.Ctx) error {
+	var payload dtos.TwoFactorLogin
+
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	details, err := cr.AuthService.LoginTwoFactor(payload)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(details)
+}
+
+// EnrollTwoFactor
+//
+//	@Summary		Enroll 2FA
+//	@Description	Generate TOTP secret and otpauth URI for authenticator app, 2FA is enabled after code is verified
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			payload					body		dtos.TwoFactorEnrollInput	true	"Password of user"
+//	@Success		200						{object}	dtos.TwoFactorEnrollment	"TOTP secret"
+//	@Failure		400						{object}	dtos.MessageResponseType	"Error enrolling 2FA"
+//	@Failure		409						{object}	dtos.MessageResponseType	"2FA is already enabled"
+//	@Router			/api/auth/2fa/enroll	[post]
+func (cr *AuthController) EnrollTwoFactor(c *fiber.Ctx) error {
+	userId, err := validator.GetUserIdAndCheckJWT(c.Locals("user"))
+	if err != nil {
+		return err
+	}
+
+	var payload dtos.TwoFactorEnrollInput
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	enrollment, err := cr.AuthService.EnrollTwoFactor(userId, payload)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(enrollment)
+}
+
+// VerifyTwoFactor
+//
+//	@Summary		Verify 2FA
+//	@Description	Enable 2FA with first TOTP code of enrolled secret, recovery codes are returned only once
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			payload					body		dtos.TwoFactorCode			true	"TOTP code"
+//	@Success		200						{object}	dtos.RecoveryCodes			"2FA enabled"
+//	@Failure		401						{object}	dtos.MessageResponseType	"Invalid code"
+//	@Router			/api/auth/2fa/verify	[post]
+func (cr *AuthController) VerifyTwoFactor(c *fiber.Ctx) error {
+	userId, err := validator.GetUserIdAndCheckJWT(c.Locals("user"))
+	if err != nil {
+		return err
+	}
+
+	var payload dtos.TwoFactorCode
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	codes, err := cr.AuthService.VerifyTwoFactor(userId, payload)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(codes)
+}
+
+// DisableTwoFactor
+//
+//	@Summary		Disable 2FA
+//	@Description	Disable 2FA of current user, password and TOTP or recovery code are required
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			payload					body		dtos.TwoFactorDisable		true	"Password and code"
+//	@Success		200						{object}	dtos.MessageResponseType	"2FA disabled"
+//	@Failure		401						{object}	dtos.MessageResponseType	"Invalid code"
+//	@Router			/api/auth/2fa/disable	[post]
+func (cr *AuthController) DisableTwoFactor(c *fiber.Ctx) error {
+	userId, err := validator.GetUserIdAndCheckJWT(c.Locals("user"))
+	if err != nil {
+		return err
+	}
+
+	var payload dtos.TwoFactorDisable
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	err = cr.AuthService.DisableTwoFactor(userId, payload)
+	if err != nil {
+		return err
+	}
+
+	return response.MessageResponse(c, fiber.StatusOK, "Successfully disabled two-factor authentication")
+}
+
+// RegenerateRecoveryCodes
+//
+//	@Summary		Regenerate recovery codes
+//	@Description	Replace recovery codes of current user, TOTP or recovery code is required
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			payload							body		dtos.TwoFactorCode			true	"TOTP or recovery code"
+//	@Success		200								{object}	dtos.RecoveryCodes			"New recovery codes"
+//	@Failure		401								{object}	dtos.MessageResponseType	"Invalid code"
+//	@Router			/api/auth/2fa/recovery-codes	[post]
+func (cr *AuthController) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userId, err := validator.GetUserIdAndCheckJWT(c.Locals("user"))
+	if err != nil {
+		return err
+	}
+
+	var payload dtos.TwoFactorCode
+	err = c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	codes, err := cr.AuthService.RegenerateRecoveryCodes(userId, payload)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(codes)
+}
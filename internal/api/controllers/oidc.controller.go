@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/validator"
+)
+
+// OIDCStateCookie binds state of login to browser which started login
+const OIDCStateCookie = "oidc_state"
+
+type OIDCService interface {
+	GetProviders() dtos.OIDCProviders
+	StartLogin(providerName string, userId *uuid.UUID) (login dtos.OIDCLogin, err error)
+	CompleteLogin(providerName string, callback dtos.OIDCCallback) (details dtos.LoginDetails, err error)
+}
+
+type OIDCController struct {
+	OIDCService OIDCService
+}
+
+func NewOIDCController(oidcService OIDCService) *OIDCController {
+	return &OIDCController{OIDCService: oidcService}
+}
+
+// GetProviders
+//
+//	@Summary		Login providers
+//	@Description	Get names of OpenID Connect providers users can login with
+//	@Tags			auth
+//	@Produce		json
+//	@Success		200						{object}	dtos.OIDCProviders	"Names of providers"
+//	@Router			/api/auth/oidc/providers	[get]
+func (cr *OIDCController) GetProviders(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(cr.OIDCService.GetProviders())
+}
+
+// StartLogin
+//
+//	@Summary		Start login with provider
+//	@Description	Get URL of provider where user logs in with authorization code flow and PKCE,
+//	@Description	if user is authenticated account of provider is linked to this user,
+//	@Description	state of login is set to HttpOnly cookie which must be sent with callback
+//	@Tags			auth
+//	@Produce		json
+//	@Security		JWT
+//	@Param			provider						path		string						true	"Provider name"
+//	@Success		200								{object}	dtos.OIDCLogin				"URL to redirect user to"
+//	@Failure		404								{object}	dtos.MessageResponseType	"Provider doesn't exist"
+//	@Failure		401								{object}	dtos.MessageResponseType	"Provider is not available"
+//	@Router			/api/auth/oidc/{provider}/login	[get]
+func (cr *OIDCController) StartLogin(c *fiber.Ctx) error {
+	var userId *uuid.UUID
+	if user := c.Locals("user"); user != nil {
+		id, err := validator.GetUserIdAndCheckJWT(user)
+		if err != nil {
+			return err
+		}
+		userId = &id
+	}
+
+	login, err := cr.OIDCService.StartLogin(c.Params("provider"), userId)
+	if err != nil {
+		return err
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     OIDCStateCookie,
+		Value:    login.State,
+		Path:     "/api/auth/oidc",
+		Secure:   true,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return c.Status(fiber.StatusOK).JSON(login)
+}
+
+// CompleteLogin
+//
+//	@Summary		Complete login with provider
+//	@Description	Exchange authorization code returned by provider and login user of provider account,
+//	@Description	user is registered on first login, state must be equal to state cookie set when login was started
+//	@Tags			auth
+//	@Produce		json
+//	@Param			provider							path		string						true	"Provider name"
+//	@Param			code								query		string						true	"Authorization code"
+//	@Param			state								query		string						true	"State of login"
+//	@Success		200									{object}	dtos.LoginDetails			"Login success"
+//	@Failure		400									{object}	dtos.MessageResponseType	"Invalid callback"
+//	@Failure		401									{object}	dtos.MessageResponseType	"Login with provider failed"
+//	@Failure		409									{object}	dtos.MessageResponseType	"Account is linked to another user"
+//	@Router			/api/auth/oidc/{provider}/callback	[get]
+func (cr *OIDCController) CompleteLogin(c *fiber.Ctx) error {
+	var callback dtos.OIDCCallback
+	err := c.QueryParser(&callback)
+	if err != nil {
+		return err
+	}
+	callback.BrowserState = c.Cookies(OIDCStateCookie)
+	c.ClearCookie(OIDCStateCookie)
+
+	details, err := cr.OIDCService.CompleteLogin(c.Params("provider"), callback)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(details)
+}
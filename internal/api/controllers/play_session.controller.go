@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/validator"
+)
+
+type PlaySessionService interface {
+	StartPlaySession(tournamentIdString string, payload dtos.ContestPayload, userId *uuid.UUID) (details dtos.PlaySessionDetails, err error)
+	GetPlaySession(sessionIdString string) (details dtos.PlaySessionDetails, err error)
+	DecideMatch(sessionIdString string, decision dtos.MatchDecision, userId *uuid.UUID) (details dtos.PlaySessionDetails, err error)
+}
+
+type PlaySessionController struct {
+	PlaySessionService PlaySessionService
+}
+
+func NewPlaySessionController(playSessionService PlaySessionService) *PlaySessionController {
+	return &PlaySessionController{PlaySessionService: playSessionService}
+}
+
+// StartPlaySession
+//
+//	@Summary		Start play session
+//	@Description	Generate tournament contest and store it in new play session
+//	@Tags			session
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			tournamentId	path		string						true	"Tournament id"
+//	@Param			payload			query		dtos.ContestPayload			true	"Contest type and options"
+//	@Success		201				{object}	dtos.PlaySessionDetails		"Play session"
+//	@Failure		400				{object}	dtos.MessageResponseType	"Failed to start play session"
+//	@Failure		403				{object}	dtos.MessageResponseType	"Tournament is private"
+//	@Router			/api/session/start/{tournamentId} [post]
+func (cr *PlaySessionController) StartPlaySession(c *fiber.Ctx) error {
+	var userId *uuid.UUID
+	user := c.Locals("user")
+	if id, err := validator.GetUserIdAndCheckJWT(user); err == nil { // JWT is OPTIONAL, anonymous sessions are allowed
+		userId = &id
+	}
+
+	tournamentIdString := c.Params("tournamentId")
+	payload := new(dtos.ContestPayload)
+	if err := c.QueryParser(payload); err != nil {
+		return err
+	}
+
+	details, err := cr.PlaySessionService.StartPlaySession(tournamentIdString, *payload, userId)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(details)
+}
+
+// GetPlaySession
+//
+//	@Summary		Play session details
+//	@Description	Get contest and decided matches of play session to resume it
+//	@Tags			session
+//	@Accept			json
+//	@Produce		json
+//	@Security		APIKey
+//	@Param			sessionId	path		string						true	"Session id"
+//	@Success		200			{object}	dtos.PlaySessionDetails		"Play session"
+//	@Failure		400			{object}	dtos.MessageResponseType	"Failed to get play session"
+//	@Router			/api/session/{sessionId} [get]
+func (cr *PlaySessionController) GetPlaySession(c *fiber.Ctx) error {
+	sessionIdString := c.Params("sessionId")
+	details, err := cr.PlaySessionService.GetPlaySession(sessionIdString)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(details)
+}
+
+// DecideMatch
+//
+//	@Summary		Decide match of play session
+//	@Description	Record vote for tiktok in match, match is decided when tiktok gets required count of votes (best-of-N),
+//	@Description	tournament statistics are updated when last match is decided.
+//	@Description	Session started by logged in user can be decided only with JWT of this user.
+//	@Tags			session
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			sessionId	path		string						true	"Session id"
+//	@Param			payload		body		dtos.MatchDecision			true	"Match and its winner"
+//	@Success		200			{object}	dtos.PlaySessionDetails		"Play session"
+//	@Failure		400			{object}	dtos.MessageResponseType	"Failed to decide match"
+//	@Failure		403			{object}	dtos.MessageResponseType	"Session belongs to other user"
+//	@Failure		409			{object}	dtos.MessageResponseType	"Match or session is already decided"
+//	@Failure		429			{object}	dtos.MessageResponseType	"Too many requests, retry after Retry-After seconds"
+//	@Router			/api/session/{sessionId}/match [put]
+func (cr *PlaySessionController) DecideMatch(c *fiber.Ctx) error {
+	var userId *uuid.UUID
+	if id, err := validator.GetUserIdAndCheckJWT(c.Locals("user")); err == nil { // JWT is required only for sessions of users
+		userId = &id
+	}
+
+	sessionIdString := c.Params("sessionId")
+
+	var payload dtos.MatchDecision
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	details, err := cr.PlaySessionService.DecideMatch(sessionIdString, payload, userId)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(details)
+}
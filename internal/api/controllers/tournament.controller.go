@@ -6,7 +6,6 @@ import (
 	"github.com/google/uuid"
 	"tiktok-arena/internal/api/controllers/response"
 	"tiktok-arena/internal/core/dtos"
-	"tiktok-arena/internal/core/models"
 	"tiktok-arena/internal/core/validator"
 )
 
@@ -16,10 +15,13 @@ type TournamentService interface {
 	DeleteTournament(userId uuid.UUID, tournamentIdString string) error
 	DeleteTournaments(userId uuid.UUID, tournamentIds dtos.TournamentIds) error
 	GetTournaments(queries dtos.PaginationQueries) (response dtos.TournamentsResponse, err error)
-	GetTournament(tournamentIdString string) (tournament models.Tournament, err error)
-	GetTournamentStats(tournamentIdString string) (tournamentStats dtos.TournamentStats, err error)
-	TournamentWinner(tournamentIdString string, winner dtos.TournamentWinner) error
-	GetTournamentContest(tournamentIdString string, contestType string) (bracket dtos.Contest, err error)
+	GetTournament(tournamentIdString string) (tournament dtos.TournamentView, err error)
+	GetTournamentStats(tournamentIdString string, queries dtos.StatsQueries) (tournamentStats dtos.TournamentStats, err error)
+	GetTournamentMatrix(tournamentIdString string) (matrix dtos.TournamentMatrix, err error)
+	TournamentWinner(tournamentIdString string, winner dtos.TournamentWinner, userId uuid.UUID) error
+	SubmitTierList(tournamentIdString string, submission dtos.TierListSubmission, userId uuid.UUID) error
+	GetTournamentContest(tournamentIdString string, payload dtos.ContestPayload, userId uuid.UUID) (bracket dtos.Contest, err error)
+	GetNextContestRound(tournamentIdString string, results dtos.ContestResults, userId uuid.UUID) (bracket dtos.Contest, err error)
 }
 
 type TournamentController struct {
@@ -38,6 +40,7 @@ func NewTournamentController(tournamentService TournamentService) *TournamentCon
 //	@Accept			json
 //	@Produce		json
 //	@Security		JWT
+//	@Security		APIKey
 //	@Param			payload	body		dtos.CreateTournament		true	"Data to create tournament"
 //	@Success		200		{object}	dtos.MessageResponseType	"Tournament created"
 //	@Failure		400		{object}	dtos.MessageResponseType	"Error during tournament creation"
@@ -71,6 +74,7 @@ func (cr *TournamentController) CreateTournament(c *fiber.Ctx) error {
 //	@Accept			json
 //	@Produce		json
 //	@Security		JWT
+//	@Security		APIKey
 //	@Param			tournamentId	path		string						true	"Tournament id"
 //	@Param			payload			body		dtos.EditTournament			true	"Data to edit tournament"
 //	@Success		200				{object}	dtos.MessageResponseType	"Tournament edited"
@@ -106,6 +110,7 @@ func (cr *TournamentController) EditTournament(c *fiber.Ctx) error {
 //	@Accept			json
 //	@Produce		json
 //	@Security		ApiKeyAuth
+//	@Security		APIKey
 //	@Success		200	{object}	dtos.MessageResponseType	"Tournament deleted"
 //	@Failure		400	{object}	dtos.MessageResponseType	"Error during tournament deletion"
 //	@Router			/api/tournament/delete/{tournamentId} [delete]
@@ -134,6 +139,7 @@ func (cr *TournamentController) DeleteTournament(c *fiber.Ctx) error {
 //	@Accept			json
 //	@Produce		json
 //	@Security		JWT
+//	@Security		APIKey
 //	@Param			payload	body		dtos.TournamentIds			true	"Data to delete tournaments"
 //	@Success		200		{object}	dtos.MessageResponseType	"Tournaments deleted"
 //	@Failure		400		{object}	dtos.MessageResponseType	"Error during tournaments deletion"
@@ -166,6 +172,7 @@ func (cr *TournamentController) DeleteTournaments(c *fiber.Ctx) error {
 //	@Tags			tournament
 //	@Accept			json
 //	@Produce		json
+//	@Security		APIKey
 //	@Param			page								query		string						false	"page number"
 //	@Param			count								query		string						false	"page size"
 //	@Param			search								query		string						false	"search"
@@ -188,19 +195,61 @@ func (cr *TournamentController) GetAllTournaments(c *fiber.Ctx) error {
 // GetTournamentContest
 //
 //	@Summary		Tournament contests
-//	@Description	Get tournament contests
+//	@Description	Get tournament contest of tiktoks drawn from tournament pool (size of payload or of tournament)
 //	@Tags			tournament
 //	@Accept			json
 //	@Produce		json
+//	@Security		APIKey
+//	@Security		JWT
 //	@Param			tournamentId	path		string						true	"Tournament id"
-//	@Param			payload			query		dtos.ContestPayload			true	"Contest type"
+//	@Param			payload			query		dtos.ContestPayload			true	"Contest type and options"
 //	@Success		200				{object}	dtos.Contest				"Contest bracket"
 //	@Failure		400				{object}	dtos.MessageResponseType	"Failed to return tournament contests"
+//	@Failure		403				{object}	dtos.MessageResponseType	"Tournament is private"
 //	@Router			/api/tournament/contest/{tournamentId} [get]
 func (cr *TournamentController) GetTournamentContest(c *fiber.Ctx) error {
 	tournamentIdString := c.Params("tournamentId")
-	contestType := c.Query("type")
-	bracket, err := cr.TournamentService.GetTournamentContest(tournamentIdString, contestType)
+	user := c.Locals("user")
+	userId, _ := validator.GetUserIdAndCheckJWT(user) // All errors are emitted because JWT is OPTIONAL
+
+	payload := new(dtos.ContestPayload)
+	if err := c.QueryParser(payload); err != nil {
+		return err
+	}
+	bracket, err := cr.TournamentService.GetTournamentContest(tournamentIdString, *payload, userId)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(bracket)
+}
+
+// NextContestRound
+//
+//	@Summary		Next round of tournament contest
+//	@Description	Submit results of played rounds and get pairing of next round for contests paired by server (swiss)
+//	@Tags			tournament
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			tournamentId	path		string						true	"Tournament id"
+//	@Param			payload			body		dtos.ContestResults			true	"Contest type and results of played rounds"
+//	@Success		200				{object}	dtos.Contest				"Next round and standings"
+//	@Failure		400				{object}	dtos.MessageResponseType	"Failed to return next round"
+//	@Failure		403				{object}	dtos.MessageResponseType	"Tournament is private"
+//	@Router			/api/tournament/contest/{tournamentId}/round [post]
+func (cr *TournamentController) NextContestRound(c *fiber.Ctx) error {
+	tournamentIdString := c.Params("tournamentId")
+
+	user := c.Locals("user")
+	userId, _ := validator.GetUserIdAndCheckJWT(user) // All errors are emitted because JWT is OPTIONAL
+
+	var payload dtos.ContestResults
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	bracket, err := cr.TournamentService.GetNextContestRound(tournamentIdString, payload, userId)
 	if err != nil {
 		return err
 	}
@@ -214,19 +263,46 @@ func (cr *TournamentController) GetTournamentContest(c *fiber.Ctx) error {
 //	@Tags			tournament
 //	@Accept			json
 //	@Produce		json
+//	@Security		APIKey
 //	@Param			tournamentId	path		string						true	"Tournament id"
-//	@Success		200				{array}		models.Tiktok				"Tournament tiktoks"
+//	@Param			sort			query		string						false	"sort tiktoks by wins or rating"
+//	@Success		200				{object}	dtos.TournamentStats		"Tournament tiktoks"
 //	@Failure		400				{object}	dtos.MessageResponseType	"Tournament not found"
 //	@Router			/api/tournament/tiktoks/{tournamentId} [get]
 func (cr *TournamentController) GetTournamentStats(c *fiber.Ctx) error {
 	tournamentIdString := c.Params("tournamentId")
-	tiktoks, err := cr.TournamentService.GetTournamentStats(tournamentIdString)
+	q := new(dtos.StatsQueries)
+	if err := c.QueryParser(q); err != nil {
+		return err
+	}
+	tiktoks, err := cr.TournamentService.GetTournamentStats(tournamentIdString, *q)
 	if err != nil {
 		return err
 	}
 	return c.Status(fiber.StatusOK).JSON(tiktoks)
 }
 
+// GetTournamentMatrix
+//
+//	@Summary		Tournament head-to-head matrix
+//	@Description	Get results of matches between every pair of tournament tiktoks
+//	@Tags			tournament
+//	@Accept			json
+//	@Produce		json
+//	@Security		APIKey
+//	@Param			tournamentId	path		string						true	"Tournament id"
+//	@Success		200				{object}	dtos.TournamentMatrix		"Head-to-head matrix"
+//	@Failure		400				{object}	dtos.MessageResponseType	"Tournament not found"
+//	@Router			/api/tournament/tiktoks/{tournamentId}/matrix [get]
+func (cr *TournamentController) GetTournamentMatrix(c *fiber.Ctx) error {
+	tournamentIdString := c.Params("tournamentId")
+	matrix, err := cr.TournamentService.GetTournamentMatrix(tournamentIdString)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(matrix)
+}
+
 // GetTournamentDetails
 //
 //	@Summary		Tournament details
@@ -234,8 +310,9 @@ func (cr *TournamentController) GetTournamentStats(c *fiber.Ctx) error {
 //	@Tags			tournament
 //	@Accept			json
 //	@Produce		json
+//	@Security		APIKey
 //	@Param			tournamentId	path		string						true	"Tournament id"
-//	@Success		200				{object}	models.Tournament			"Tournament"
+//	@Success		200				{object}	dtos.TournamentView			"Tournament"
 //	@Failure		400				{object}	dtos.MessageResponseType	"Tournament not found"
 //	@Router			/api/tournament/details/{tournamentId} [get]
 func (cr *TournamentController) GetTournamentDetails(c *fiber.Ctx) error {
@@ -250,25 +327,30 @@ func (cr *TournamentController) GetTournamentDetails(c *fiber.Ctx) error {
 // TournamentWinner
 //
 //	@Summary		Update tournament winner statistics
-//	@Description	Increment wins and increment times_played
+//	@Description	Increment wins and increment times_played, if play session is provided its winner is only verified
 //	@Tags			tournament
 //	@Accept			json
 //	@Produce		json
+//	@Security		JWT
 //	@Param			tournamentId	path		string						true	"Tournament id"
 //	@Param			payload			body		dtos.TournamentWinner		true	"Data to update tournament winner"
 //	@Success		200				{object}	dtos.MessageResponseType	"Winner updated"
 //	@Failure		400				{object}	dtos.MessageResponseType	"Error during winner updating"
+//	@Failure		429				{object}	dtos.MessageResponseType	"Too many requests, retry after Retry-After seconds"
 //	@Router			/api/tournament/winner/{tournamentId} [put]
 func (cr *TournamentController) TournamentWinner(c *fiber.Ctx) error {
 	tournamentIdString := c.Params("tournamentId")
 
+	user := c.Locals("user")
+	userId, _ := validator.GetUserIdAndCheckJWT(user) // All errors are emitted because JWT is OPTIONAL
+
 	var payload dtos.TournamentWinner
 	err := c.BodyParser(&payload)
 	if err != nil {
 		return err
 	}
 
-	err = cr.TournamentService.TournamentWinner(tournamentIdString, payload)
+	err = cr.TournamentService.TournamentWinner(tournamentIdString, payload, userId)
 	if err != nil {
 		return err
 	}
@@ -276,3 +358,37 @@ func (cr *TournamentController) TournamentWinner(c *fiber.Ctx) error {
 	return response.MessageResponse(c, fiber.StatusOK,
 		fmt.Sprintf("Successfully registered winner for tournament %s", tournamentIdString))
 }
+
+// SubmitTierList
+//
+//	@Summary		Submit tier list
+//	@Description	Place every tiktok of tournament into one of tournament tiers, tier lists are aggregated in tournament stats
+//	@Tags			tournament
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			tournamentId	path		string						true	"Tournament id"
+//	@Param			payload			body		dtos.TierListSubmission		true	"Placements of all tiktoks"
+//	@Success		201				{object}	dtos.MessageResponseType	"Tier list submitted"
+//	@Failure		400				{object}	dtos.MessageResponseType	"Error during tier list submitting"
+//	@Router			/api/tournament/tierlist/{tournamentId} [post]
+func (cr *TournamentController) SubmitTierList(c *fiber.Ctx) error {
+	tournamentIdString := c.Params("tournamentId")
+
+	user := c.Locals("user")
+	userId, _ := validator.GetUserIdAndCheckJWT(user) // All errors are emitted because JWT is OPTIONAL
+
+	var payload dtos.TierListSubmission
+	err := c.BodyParser(&payload)
+	if err != nil {
+		return err
+	}
+
+	err = cr.TournamentService.SubmitTierList(tournamentIdString, payload, userId)
+	if err != nil {
+		return err
+	}
+
+	return response.MessageResponse(c, fiber.StatusCreated,
+		fmt.Sprintf("Successfully submitted tier list for tournament %s", tournamentIdString))
+}
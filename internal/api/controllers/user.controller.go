@@ -29,6 +29,7 @@ func NewUserController(userService UserService) *UserController {
 //	@Tags			user
 //	@Accept			json
 //	@Produce		json
+//	@Security		APIKey
 //	@Param			page					query		string						false	"page number"
 //	@Param			count					query		string						false	"page size"
 //	@Param			search					query		string						false	"search"
@@ -56,6 +57,7 @@ func (cr *UserController) GetAllUsers(c *fiber.Ctx) error {
 //	@Accept			json
 //	@Produce		json
 //	@Security		JWT
+//	@Security		APIKey
 //	@Param			userId		path		string								true	"User id"
 //	@Param			page		query		string								false	"page number"
 //	@Param			count		query		string								false	"page size"
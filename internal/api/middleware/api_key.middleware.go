@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"tiktok-arena/internal/core/models"
+)
+
+// APIKeyHeader header with personal API key of user
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator checks API key and returns token with claims of its user
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(key string) (*jwt.Token, error)
+}
+
+var apiKeyAuthenticator APIKeyAuthenticator
+
+// SetAPIKeyAuthenticator sets authenticator of API keys accepted by APIKeyOr
+func SetAPIKeyAuthenticator(authenticator APIKeyAuthenticator) {
+	apiKeyAuthenticator = authenticator
+}
+
+// APIKeyOr authenticates request with API key from X-API-Key header, key must have scope,
+// requests without API key are passed to JWT middleware (Protected or OptionalJWT)
+func APIKeyOr(scope string, jwtMiddleware func(*fiber.Ctx) error) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(APIKeyHeader)
+		if key == "" || apiKeyAuthenticator == nil {
+			return jwtMiddleware(c)
+		}
+
+		token, err := apiKeyAuthenticator.AuthenticateAPIKey(key)
+		if err != nil {
+			return err
+		}
+		keyScope, _ := token.Claims.(jwt.MapClaims)["scope"].(string)
+		if !models.HasScope(keyScope, scope) {
+			c.Status(fiber.StatusForbidden)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("API key with scope %s is required", scope),
+				"data":    nil,
+			})
+		}
+
+		c.Locals("user", token)
+		return c.Next()
+	}
+}
+
+// Public passes request without authentication, APIKeyOr(scope, Public()) lets scripts read public data with API keys
+func Public() func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		return c.Next()
+	}
+}
@@ -1,15 +1,32 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gofiber/fiber/v2"
 	jwtware "github.com/gofiber/jwt/v3"
-	"tiktok-arena/configuration"
+	"github.com/golang-jwt/jwt/v4"
+	"tiktok-arena/internal/core/keys"
+	"tiktok-arena/internal/core/models"
 )
 
+// RevocationList of access tokens revoked before their expiration
+type RevocationList interface {
+	IsAccessTokenRevoked(claims jwt.MapClaims) (bool, error)
+}
+
+var revocationList RevocationList
+
+// SetRevocationList sets list which is checked for every valid JWT
+func SetRevocationList(list RevocationList) {
+	revocationList = list
+}
+
 func Protected() func(*fiber.Ctx) error {
 	return jwtware.New(jwtware.Config{
-		SigningKey:   []byte(configuration.EnvConfig.JwtSecret),
-		ErrorHandler: jwtError,
+		KeyFunc:        keyFunc,
+		ErrorHandler:   jwtError,
+		SuccessHandler: checkRevocation,
 	})
 }
 
@@ -24,13 +41,62 @@ func OptionalJWT() func(*fiber.Ctx) error {
 
 		// Validate and process the JWT if provided
 		return jwtware.New(jwtware.Config{
-			SigningKey:   []byte(configuration.EnvConfig.JwtSecret),
-			ErrorHandler: jwtError,
+			KeyFunc:        keyFunc,
+			ErrorHandler:   jwtError,
+			SuccessHandler: checkRevocation,
 		})(c)
 
 	}
 }
 
+// keyFunc finds key which verifies JWT in key set loaded from configuration
+func keyFunc(token *jwt.Token) (interface{}, error) {
+	keySet, err := keys.Get()
+	if err != nil {
+		return nil, err
+	}
+	return keySet.KeyFunc(token)
+}
+
+// checkRevocation rejects JWT without jti or with jti from revocation list
+func checkRevocation(c *fiber.Ctx) error {
+	token := c.Locals("user").(*jwt.Token)
+	claims := token.Claims.(jwt.MapClaims)
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return jwtError(c, errors.New("JWT without jti"))
+	}
+	if revocationList != nil {
+		revoked, err := revocationList.IsAccessTokenRevoked(claims)
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return jwtError(c, errors.New("revoked JWT"))
+		}
+	}
+	return c.Next()
+}
+
+// RequireRole allows only users with role or higher role in JWT claims, must be used after Protected
+func RequireRole(role string) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		token, ok := c.Locals("user").(*jwt.Token)
+		if !ok {
+			return jwtError(c, errors.New("Missing or malformed JWT"))
+		}
+		userRole, _ := token.Claims.(jwt.MapClaims)["role"].(string)
+		if !models.HasRole(userRole, role) {
+			c.Status(fiber.StatusForbidden)
+			return c.JSON(fiber.Map{
+				"message": fmt.Sprintf("Role %s is required", role),
+				"data":    nil,
+			})
+		}
+		return c.Next()
+	}
+}
+
 func jwtError(c *fiber.Ctx, err error) error {
 	if err.Error() == "Missing or malformed JWT" {
 		c.Status(fiber.StatusBadRequest)
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"math"
+	"strconv"
 	"tiktok-arena/internal/core/services"
 )
 
@@ -13,6 +15,19 @@ func ErrorHandler(c *fiber.Ctx, err error) error {
 	case services.ValidateError:
 		code = fiber.StatusBadRequest
 		message = e.Error()
+	case services.UserNotExistsError:
+		code = fiber.StatusNotFound
+		message = e.Error()
+	case services.UserBannedError:
+		code = fiber.StatusForbidden
+		message = e.Error()
+	case services.InsufficientRoleError:
+		code = fiber.StatusForbidden
+		message = e.Error()
+	case services.LoginLockedError:
+		code = fiber.StatusTooManyRequests
+		message = e.Error()
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(e.RetryAfter.Seconds()))))
 	case services.UserAlreadyExistsError:
 		code = fiber.StatusConflict
 		message = e.Error()
@@ -49,6 +64,93 @@ func ErrorHandler(c *fiber.Ctx, err error) error {
 	case services.NotAllowedContestTypeError:
 		code = fiber.StatusBadRequest
 		message = e.Error()
+	case services.ContestResultsError:
+		code = fiber.StatusBadRequest
+		message = e.Error()
+	case services.NotEnoughTiktoksError:
+		code = fiber.StatusBadRequest
+		message = e.Error()
+	case services.PlaySessionNotExistsError:
+		code = fiber.StatusNotFound
+		message = e.Error()
+	case services.PlaySessionAccessDeniedError:
+		code = fiber.StatusForbidden
+		message = e.Error()
+	case services.PlaySessionCompletedError:
+		code = fiber.StatusConflict
+		message = e.Error()
+	case services.MatchDecisionError:
+		code = fiber.StatusBadRequest
+		message = e.Error()
+	case services.MatchAlreadyDecidedError:
+		code = fiber.StatusConflict
+		message = e.Error()
+	case services.TournamentAccessDeniedError:
+		code = fiber.StatusForbidden
+		message = e.Error()
+	case services.TiktokNotInTournamentError:
+		code = fiber.StatusBadRequest
+		message = e.Error()
+	case services.PlaySessionNotCompletedError:
+		code = fiber.StatusConflict
+		message = e.Error()
+	case services.PlaySessionWinnerMismatchError:
+		code = fiber.StatusBadRequest
+		message = e.Error()
+	case services.TierListError:
+		code = fiber.StatusBadRequest
+		message = e.Error()
+	case services.InvalidRefreshTokenError:
+		code = fiber.StatusUnauthorized
+		message = e.Error()
+	case services.InvalidPasswordResetTokenError:
+		code = fiber.StatusBadRequest
+		message = e.Error()
+	case services.InvalidEmailChangeTokenError:
+		code = fiber.StatusBadRequest
+		message = e.Error()
+	case services.MailError:
+		code = fiber.StatusInternalServerError
+		message = e.Error()
+	case services.OIDCProviderNotFoundError:
+		code = fiber.StatusNotFound
+		message = e.Error()
+	case services.OIDCError:
+		code = fiber.StatusUnauthorized
+		message = e.Error()
+	case services.IdentityAlreadyLinkedError:
+		code = fiber.StatusConflict
+		message = e.Error()
+	case services.InvalidAPIKeyError:
+		code = fiber.StatusUnauthorized
+		message = e.Error()
+	case services.APIKeyNotExistsError:
+		code = fiber.StatusNotFound
+		message = e.Error()
+	case services.APIKeyLimitError:
+		code = fiber.StatusBadRequest
+		message = e.Error()
+	case services.TwoFactorAlreadyEnabledError:
+		code = fiber.StatusConflict
+		message = e.Error()
+	case services.TwoFactorNotEnabledError:
+		code = fiber.StatusBadRequest
+		message = e.Error()
+	case services.InvalidTwoFactorCodeError:
+		code = fiber.StatusUnauthorized
+		message = e.Error()
+	case services.InvalidTwoFactorChallengeError:
+		code = fiber.StatusUnauthorized
+		message = e.Error()
+	case services.ReauthenticationRequiredError:
+		code = fiber.StatusUnauthorized
+		message = e.Error()
+	case services.ExportError:
+		code = fiber.StatusInternalServerError
+		message = e.Error()
+	case services.ContestSerializationError:
+		code = fiber.StatusInternalServerError
+		message = e.Error()
 	default:
 		message = err.Error()
 	}
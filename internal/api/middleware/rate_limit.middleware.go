@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/golang-jwt/jwt/v4"
+	"strings"
+	"time"
+)
+
+// RateLimitWindow window in which count of requests is limited
+const RateLimitWindow = time.Minute
+
+var rateLimitStorage fiber.Storage
+
+// SetRateLimitStorage sets storage of request counters shared by all rate limiters,
+// any fiber.Storage (e.g. Redis storage of gofiber/storage) can be used to share limits between instances.
+// Must be called before routes are set up, every rate limiter keeps own in-memory counters by default.
+func SetRateLimitStorage(storage fiber.Storage) {
+	rateLimitStorage = storage
+}
+
+// RateLimit limits count of requests to max per RateLimitWindow, requests are counted per user
+// if request has valid JWT or per IP otherwise, name separates counters of limiters in shared storage.
+// Limit is disabled if max is not positive.
+func RateLimit(name string, max int) func(*fiber.Ctx) error {
+	return rateLimiter(name, max, rateLimitKey)
+}
+
+// IPRateLimit limits count of requests like RateLimit, but requests are always counted per IP,
+// so valid JWT of one account doesn't lift limit of endpoints which guess credentials (login, registration)
+func IPRateLimit(name string, max int) func(*fiber.Ctx) error {
+	return rateLimiter(name, max, ipRateLimitKey)
+}
+
+func rateLimiter(name string, max int, key func(c *fiber.Ctx) string) func(*fiber.Ctx) error {
+	return limiter.New(limiter.Config{
+		Next: func(c *fiber.Ctx) bool {
+			return max <= 0
+		},
+		Max:        max,
+		Expiration: RateLimitWindow,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return fmt.Sprintf("%s:%s", name, key(c))
+		},
+		LimitReached: rateLimitError,
+		Storage:      rateLimitStorage,
+	})
+}
+
+// rateLimitKey returns user of JWT validated by previous middleware or of valid Bearer JWT,
+// limiters run before authentication of route, so JWT is verified here. IP is returned without valid JWT.
+func rateLimitKey(c *fiber.Ctx) string {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		auth := c.Get(fiber.HeaderAuthorization)
+		if !strings.HasPrefix(auth, "Bearer ") {
+			return ipRateLimitKey(c)
+		}
+		var err error
+		token, err = jwt.Parse(strings.TrimPrefix(auth, "Bearer "), keyFunc)
+		if err != nil || !token.Valid {
+			return ipRateLimitKey(c)
+		}
+	}
+	if sub, ok := token.Claims.(jwt.MapClaims)["sub"].(string); ok {
+		return "user:" + sub
+	}
+	return ipRateLimitKey(c)
+}
+
+func ipRateLimitKey(c *fiber.Ctx) string {
+	return "ip:" + c.IP()
+}
+
+// rateLimitError responds with 429, Retry-After header is set by limiter
+func rateLimitError(c *fiber.Ctx) error {
+	c.Status(fiber.StatusTooManyRequests)
+	return c.JSON(fiber.Map{
+		"message": fmt.Sprintf("Too many requests, retry after %s seconds", c.GetRespHeader(fiber.HeaderRetryAfter)),
+		"data":    nil,
+	})
+}
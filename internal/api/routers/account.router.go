@@ -0,0 +1,14 @@
+package routers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tiktok-arena/internal/api/controllers"
+	"tiktok-arena/internal/api/middleware"
+)
+
+func NewAccountRouter(c *controllers.AccountController) func(router fiber.Router) {
+	return func(router fiber.Router) {
+		router.Delete("/me", middleware.Protected(), c.DeleteAccount)
+		router.Get("/me/export", middleware.Protected(), c.ExportAccount)
+	}
+}
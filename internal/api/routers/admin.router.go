@@ -0,0 +1,21 @@
+package routers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tiktok-arena/internal/api/controllers"
+	"tiktok-arena/internal/api/middleware"
+	"tiktok-arena/internal/core/models"
+)
+
+func NewAdminRouter(c *controllers.AdminController) func(router fiber.Router) {
+	return func(router fiber.Router) {
+		router.Use(middleware.Protected(), middleware.RequireRole(models.RoleModerator))
+
+		router.Get("/tournaments", c.GetAllTournaments)
+		router.Delete("/tournaments/:tournamentId", c.DeleteTournament)
+		router.Put("/users/:userId/ban", c.BanUser)
+
+		router.Delete("/tournaments/:tournamentId/stats", middleware.RequireRole(models.RoleAdmin), c.ResetTournamentStats)
+		router.Put("/users/:userId/role", middleware.RequireRole(models.RoleAdmin), c.ChangeUserRole)
+	}
+}
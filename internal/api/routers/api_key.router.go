@@ -0,0 +1,18 @@
+package routers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tiktok-arena/internal/api/controllers"
+	"tiktok-arena/internal/api/middleware"
+)
+
+// NewAPIKeyRouter keys are managed only with JWT, so leaked key can't create other keys
+func NewAPIKeyRouter(c *controllers.APIKeyController) func(router fiber.Router) {
+	return func(router fiber.Router) {
+		router.Use(middleware.Protected())
+
+		router.Post("/", c.CreateAPIKey)
+		router.Get("/", c.GetAPIKeys)
+		router.Delete("/:keyId", c.RevokeAPIKey)
+	}
+}
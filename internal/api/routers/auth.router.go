@@ -10,7 +10,19 @@ func NewAuthRouter(c *controllers.AuthController) func(router fiber.Router) {
 	return func(router fiber.Router) {
 		router.Post("/register", c.RegisterUser)
 		router.Post("/login", c.LoginUser)
+		router.Post("/refresh", c.RefreshToken)
+		router.Post("/password/forgot", c.RequestPasswordReset)
+		router.Post("/password/reset", c.ResetPassword)
+		router.Post("/email/confirm", c.ConfirmEmailChange)
+		router.Post("/2fa/login", c.LoginTwoFactor)
 
+		router.Post("/logout", middleware.Protected(), c.Logout)
 		router.Get("/whoami", middleware.Protected(), c.WhoAmI)
+		router.Put("/password", middleware.Protected(), c.ChangePassword)
+		router.Put("/email", middleware.Protected(), c.RequestEmailChange)
+		router.Post("/2fa/enroll", middleware.Protected(), c.EnrollTwoFactor)
+		router.Post("/2fa/verify", middleware.Protected(), c.VerifyTwoFactor)
+		router.Post("/2fa/disable", middleware.Protected(), c.DisableTwoFactor)
+		router.Post("/2fa/recovery-codes", middleware.Protected(), c.RegenerateRecoveryCodes)
 	}
 }
@@ -0,0 +1,15 @@
+package routers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tiktok-arena/internal/api/controllers"
+	"tiktok-arena/internal/api/middleware"
+)
+
+func NewOIDCRouter(c *controllers.OIDCController) func(router fiber.Router) {
+	return func(router fiber.Router) {
+		router.Get("/providers", c.GetProviders)
+		router.Get("/:provider/login", middleware.OptionalJWT(), c.StartLogin)
+		router.Get("/:provider/callback", c.CompleteLogin)
+	}
+}
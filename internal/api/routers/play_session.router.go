@@ -0,0 +1,18 @@
+package routers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tiktok-arena/configuration"
+	"tiktok-arena/internal/api/controllers"
+	"tiktok-arena/internal/api/middleware"
+	"tiktok-arena/internal/core/models"
+)
+
+func NewPlaySessionRouter(c *controllers.PlaySessionController) func(router fiber.Router) {
+	return func(router fiber.Router) {
+		router.Post("/start/:tournamentId", middleware.OptionalJWT(), c.StartPlaySession)
+		router.Get("/:sessionId", middleware.APIKeyOr(models.ScopeRead, middleware.Public()), c.GetPlaySession)
+		router.Put("/:sessionId/match", middleware.OptionalJWT(),
+			middleware.RateLimit("decide", configuration.EnvConfig.DecideRateLimit), c.DecideMatch)
+	}
+}
@@ -3,18 +3,25 @@ package routers
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/swagger"
+	"tiktok-arena/configuration"
 	_ "tiktok-arena/docs"
+	"tiktok-arena/internal/api/middleware"
 )
 
 type GroupRoutes struct {
 	AuthGroup       fiber.Router
+	OIDCGroup       fiber.Router
+	APIKeyGroup     fiber.Router
 	UserGroup       fiber.Router
 	TournamentGroup fiber.Router
+	SessionGroup    fiber.Router
+	AdminGroup      fiber.Router
+	WellKnownGroup  fiber.Router
 }
 
 func GetGroupRoutes(app *fiber.App) GroupRoutes {
 
-	api := app.Group("/api")
+	api := app.Group("/api", middleware.RateLimit("api", configuration.EnvConfig.RateLimit))
 
 	//	Use 'swag init' to generate new /docs files, details: https://github.com/gofiber/swagger#usage
 	api.Get("/docs/*", swagger.HandlerDefault)
@@ -23,13 +30,25 @@ func GetGroupRoutes(app *fiber.App) GroupRoutes {
 		return ctx.Redirect("/api/docs/")
 	})
 
-	authGroup := api.Group("/auth")
+	// Auth endpoints are limited per IP, JWT of one account must not lift limit of guessing credentials
+	authGroup := api.Group("/auth", middleware.IPRateLimit("auth", configuration.EnvConfig.AuthRateLimit))
+	oidcGroup := authGroup.Group("/oidc")
+	apiKeyGroup := authGroup.Group("/keys")
 	userGroup := api.Group("/user")
 	tournamentGroup := api.Group("/tournament")
+	sessionGroup := api.Group("/session")
+	adminGroup := api.Group("/admin")
+	// Well-known URIs are served outside of API
+	wellKnownGroup := app.Group("/.well-known")
 
 	return GroupRoutes{
 		AuthGroup:       authGroup,
+		OIDCGroup:       oidcGroup,
+		APIKeyGroup:     apiKeyGroup,
 		UserGroup:       userGroup,
 		TournamentGroup: tournamentGroup,
+		SessionGroup:    sessionGroup,
+		AdminGroup:      adminGroup,
+		WellKnownGroup:  wellKnownGroup,
 	}
 }
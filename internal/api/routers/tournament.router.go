@@ -2,21 +2,33 @@ package routers
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"tiktok-arena/configuration"
 	"tiktok-arena/internal/api/controllers"
 	"tiktok-arena/internal/api/middleware"
+	"tiktok-arena/internal/core/models"
 )
 
 func NewTournamentRouter(c *controllers.TournamentController) func(router fiber.Router) {
 	return func(router fiber.Router) {
-		router.Get("/tournaments", c.GetAllTournaments)
-		router.Get("/contest/:tournamentId", c.GetTournamentContest)
-		router.Get("/tiktoks/:tournamentId", c.GetTournamentStats)
-		router.Get("/details/:tournamentId", c.GetTournamentDetails)
-		router.Put("/winner/:tournamentId", c.TournamentWinner)
+		// Public data can be read by scripts with API keys of any scope
+		readAccess := middleware.APIKeyOr(models.ScopeRead, middleware.Public())
+		router.Get("/tournaments", readAccess, c.GetAllTournaments)
+		// Contests of private tournaments can be generated by their owners only
+		contestAccess := middleware.APIKeyOr(models.ScopeRead, middleware.OptionalJWT())
+		router.Get("/contest/:tournamentId", contestAccess, c.GetTournamentContest)
+		router.Post("/contest/:tournamentId/round", middleware.OptionalJWT(), c.NextContestRound)
+		router.Get("/tiktoks/:tournamentId", readAccess, c.GetTournamentStats)
+		router.Get("/tiktoks/:tournamentId/matrix", readAccess, c.GetTournamentMatrix)
+		router.Get("/details/:tournamentId", readAccess, c.GetTournamentDetails)
+		router.Put("/winner/:tournamentId", middleware.OptionalJWT(),
+			middleware.RateLimit("winner", configuration.EnvConfig.WinnerRateLimit), c.TournamentWinner)
+		router.Post("/tierlist/:tournamentId", middleware.OptionalJWT(), c.SubmitTierList)
 
-		router.Post("/create", middleware.Protected(), c.CreateTournament)
-		router.Put("/edit/:tournamentId", middleware.Protected(), c.EditTournament)
-		router.Delete("/delete/:tournamentId", middleware.Protected(), c.DeleteTournament)
-		router.Delete("/delete", middleware.Protected(), c.DeleteTournaments)
+		// Tournaments can be managed by scripts with API keys
+		writeAccess := middleware.APIKeyOr(models.ScopeTournamentWrite, middleware.Protected())
+		router.Post("/create", writeAccess, c.CreateTournament)
+		router.Put("/edit/:tournamentId", writeAccess, c.EditTournament)
+		router.Delete("/delete/:tournamentId", writeAccess, c.DeleteTournament)
+		router.Delete("/delete", writeAccess, c.DeleteTournaments)
 	}
 }
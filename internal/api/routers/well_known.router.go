@@ -0,0 +1,12 @@
+package routers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tiktok-arena/internal/api/controllers"
+)
+
+func NewWellKnownRouter(c *controllers.AuthController) func(router fiber.Router) {
+	return func(router fiber.Router) {
+		router.Get("/jwks.json", c.JWKS)
+	}
+}
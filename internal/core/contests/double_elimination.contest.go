@@ -0,0 +1,118 @@
+package contests
+
+import (
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+)
+
+// DoubleElimination
+// https://en.wikipedia.org/wiki/Double-elimination_tournament
+// Winners bracket is generated with SingleElimination, losers of every winners round drop to losers bracket.
+// Losers bracket survivors first play each other until their count is not bigger than count of dropped losers,
+// then play against dropped losers. Champion of losers bracket meets champion of winners bracket in grand final.
+// If BracketReset option is true, additional match is played when champion of losers bracket wins grand final.
+func DoubleElimination(t []models.Tiktok, options Options) dtos.Contest {
+	ids := newMatchIDs(options.Seed)
+	winnersRounds := eliminationRounds(tiktokOptions(t), ids)
+	countTiktok := len(t)
+
+	rounds := make([]dtos.Round, 0, len(winnersRounds)*3)
+	var survivors []dtos.Option // Participators of losers bracket waiting for next match
+
+	for i, winnersRound := range winnersRounds {
+		winnersRound.Round = len(rounds) + 1
+		winnersRound.Bracket = dtos.WinnersBracket
+		rounds = append(rounds, winnersRound)
+
+		dropped := make([]dtos.Option, 0, len(winnersRound.Matches))
+		for _, match := range winnersRound.Matches {
+			dropped = append(dropped, dtos.LoserOption{MatchID: match.MatchID})
+		}
+		if i == 0 {
+			survivors = dropped
+			continue
+		}
+
+		// Reducing survivors to count of dropped losers
+		for len(survivors) > len(dropped) {
+			countMatches := len(survivors) - len(dropped)
+			if countMatches > len(survivors)/2 {
+				countMatches = len(survivors) / 2
+			}
+			var round dtos.Round
+			round, survivors = losersRound(len(rounds)+1, ids, survivors[:countMatches*2], survivors[countMatches*2:])
+			rounds = append(rounds, round)
+		}
+
+		// Survivors against dropped losers, participators without pair get bye
+		countMatches := len(survivors)
+		if countMatches > len(dropped) {
+			countMatches = len(dropped)
+		}
+		pairs := make([]dtos.Option, 0, countMatches*2)
+		for j := 0; j < countMatches; j++ {
+			pairs = append(pairs, survivors[j], dropped[j])
+		}
+		var round dtos.Round
+		round, survivors = losersRound(len(rounds)+1, ids, pairs,
+			append(survivors[countMatches:], dropped[countMatches:]...))
+		rounds = append(rounds, round)
+	}
+
+	winnersFinal := winnersRounds[len(winnersRounds)-1].Matches[0]
+	grandFinal := dtos.Match{
+		MatchID:      ids.next(),
+		FirstOption:  dtos.MatchOption{MatchID: winnersFinal.MatchID},
+		SecondOption: survivors[0],
+	}
+	rounds = append(rounds, dtos.Round{
+		Round:   len(rounds) + 1,
+		Bracket: dtos.GrandFinal,
+		Matches: []dtos.Match{grandFinal},
+	})
+
+	// Winners bracket has countTiktok-1 matches, losers bracket has countTiktok-2 matches
+	countMatches := 2*countTiktok - 2
+	if options.BracketReset {
+		rounds = append(rounds, dtos.Round{
+			Round:   len(rounds) + 1,
+			Bracket: dtos.GrandFinal,
+			Matches: []dtos.Match{{
+				MatchID:      ids.next(),
+				FirstOption:  dtos.MatchOption{MatchID: grandFinal.MatchID},
+				SecondOption: dtos.LoserOption{MatchID: grandFinal.MatchID},
+				IfNecessary:  true,
+			}},
+		})
+		countMatches++
+	}
+
+	return dtos.Contest{
+		Type:         dtos.DoubleElimination,
+		Seed:         options.Seed,
+		CountMatches: countMatches,
+		Rounds:       rounds,
+	}
+}
+
+// losersRound pairs options one by one and returns losers bracket round
+// with survivors consisting of options with bye followed by winners of round
+func losersRound(roundID int, ids *matchIDs, pairs []dtos.Option, byes []dtos.Option) (dtos.Round, []dtos.Option) {
+	matches := make([]dtos.Match, 0, len(pairs)/2)
+	survivors := make([]dtos.Option, 0, len(byes)+len(pairs)/2)
+	survivors = append(survivors, byes...)
+	for i := 0; i < len(pairs); i += 2 {
+		matchID := ids.next()
+		matches = append(matches, dtos.Match{
+			MatchID:      matchID,
+			FirstOption:  pairs[i],
+			SecondOption: pairs[i+1],
+		})
+		survivors = append(survivors, dtos.MatchOption{MatchID: matchID})
+	}
+	return dtos.Round{
+		Round:   roundID,
+		Bracket: dtos.LosersBracket,
+		Matches: matches,
+	}, survivors
+}
@@ -0,0 +1,76 @@
+package contests
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tiktok-arena/internal/core/dtos"
+)
+
+func TestDoubleEliminationBrackets(t *testing.T) {
+	tests := []struct {
+		name           string
+		countTiktoks   int
+		bracketReset   bool
+		winnersMatches int
+		losersMatches  int
+		finalMatches   int
+	}{
+		{name: "two tiktoks", countTiktoks: 2, winnersMatches: 1, losersMatches: 0, finalMatches: 1},
+		{name: "power of two", countTiktoks: 8, winnersMatches: 7, losersMatches: 6, finalMatches: 1},
+		{name: "byes in first round", countTiktoks: 5, winnersMatches: 4, losersMatches: 3, finalMatches: 1},
+		{name: "bracket reset", countTiktoks: 4, bracketReset: true, winnersMatches: 3, losersMatches: 2,
+			finalMatches: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tiktoks := testTiktoks(test.countTiktoks)
+			contest := DoubleElimination(tiktoks, Options{Seed: 1, BracketReset: test.bracketReset})
+
+			countMatches := map[string]int{}
+			dropped := map[string]int{} // matchID of winners bracket -> times its loser is referenced
+			winnersMatches := map[string]bool{}
+			for i, round := range contest.Rounds {
+				assert.Equal(t, i+1, round.Round)
+				countMatches[round.Bracket] += len(round.Matches)
+				for _, match := range round.Matches {
+					if round.Bracket == dtos.WinnersBracket {
+						winnersMatches[match.MatchID] = true
+					}
+					for _, option := range []dtos.Option{match.FirstOption, match.SecondOption} {
+						if loser, ok := option.(dtos.LoserOption); ok && round.Bracket == dtos.LosersBracket {
+							dropped[loser.MatchID]++
+						}
+					}
+				}
+			}
+
+			assert.Equal(t, test.winnersMatches, countMatches[dtos.WinnersBracket])
+			assert.Equal(t, test.losersMatches, countMatches[dtos.LosersBracket])
+			assert.Equal(t, test.finalMatches, countMatches[dtos.GrandFinal])
+			assert.Equal(t, test.winnersMatches+test.losersMatches+test.finalMatches, contest.CountMatches)
+			assert.Equal(t, dtos.GrandFinal, contest.Rounds[len(contest.Rounds)-1].Bracket)
+			// Loser of every winners bracket match drops to losers bracket exactly once,
+			// without losers bracket loser of winners final goes straight to grand final
+			if test.losersMatches > 0 {
+				for matchID := range winnersMatches {
+					assert.Equal(t, 1, dropped[matchID])
+				}
+			}
+		})
+	}
+}
+
+func TestDoubleEliminationIsPlayedToWinner(t *testing.T) {
+	for _, countTiktoks := range []int{2, 3, 5, 8} {
+		tiktoks := testTiktoks(countTiktoks)
+		contest := DoubleElimination(tiktoks, Options{Seed: 1})
+		progress := NewProgress(contest)
+
+		decisions := playFirstOption(progress)
+
+		assert.Len(t, decisions, contest.CountMatches, "%d tiktoks", countTiktoks)
+		winner, finished := progress.Winner()
+		assert.True(t, finished, "%d tiktoks", countTiktoks)
+		assert.Equal(t, tiktoks[0].URL, winner, "%d tiktoks", countTiktoks)
+	}
+}
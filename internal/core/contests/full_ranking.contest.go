@@ -0,0 +1,141 @@
+package contests
+
+import (
+	"fmt"
+	"math"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+)
+
+// FullRanking
+// https://en.wikipedia.org/wiki/Insertion_sort#Variants
+// Contest ranks all tiktoks with binary insertion sort, every comparison of two tiktoks is a match.
+// Every tiktok is inserted into ranking of previous tiktoks with binary search, so only ceil(log2(K+1))
+// matches are needed to insert tiktok into ranking of K tiktoks.
+// Every round consists of one match, only first round is generated,
+// next rounds are generated by FullRankingNextRound from results of previous rounds.
+// CountMatches and CountRounds are upper bound, contest may finish earlier.
+// With less than two tiktoks there is nothing to compare, contest without rounds is returned.
+func FullRanking(t []models.Tiktok, options Options) dtos.Contest {
+	countTiktok := len(t)
+	countMatches := 0
+	for i := 1; i < countTiktok; i++ {
+		countMatches += int(math.Ceil(math.Log2(float64(i + 1))))
+	}
+
+	standings := make([]dtos.Standing, 0, countTiktok)
+	for _, tiktok := range t {
+		standings = append(standings, dtos.Standing{TiktokURL: tiktok.URL})
+	}
+
+	rounds := []dtos.Round{}
+	if countTiktok >= 2 {
+		rounds = append(rounds, swissRound(1, []string{t[1].URL, t[0].URL}, options))
+	}
+
+	return dtos.Contest{
+		Type:         dtos.FullRanking,
+		Seed:         options.Seed,
+		CountMatches: countMatches,
+		CountRounds:  countMatches,
+		Rounds:       rounds,
+		Standings:    standings,
+	}
+}
+
+// FullRankingNextRound
+// Validates results of already played rounds and continues binary insertion sort with them.
+// Contest with next comparison and current ranking in standings is returned,
+// tiktoks which are not inserted yet are placed after ranked ones.
+// If ranking is complete contest without rounds and with final ranking is returned.
+func FullRankingNextRound(t []models.Tiktok, results []dtos.MatchResult, options Options) (dtos.Contest, error) {
+	contest := FullRanking(t, options)
+
+	positions := make(map[string]int, len(t))
+	for i, tiktok := range t {
+		positions[tiktok.URL] = i
+	}
+
+	winners := make(map[[2]int]int, len(results)) // pair of tiktoks (lower index first) -> winner
+	for _, result := range results {
+		first, firstOk := positions[result.FirstTiktokURL]
+		second, secondOk := positions[result.SecondTiktokURL]
+		if !firstOk || !secondOk || first == second {
+			return contest, fmt.Errorf("round %d has match with unknown tiktoks", result.Round)
+		}
+		winner := first
+		if result.WinnerURL == result.SecondTiktokURL {
+			winner = second
+		} else if result.WinnerURL != result.FirstTiktokURL {
+			return contest, fmt.Errorf("winner %s didn't play in match", result.WinnerURL)
+		}
+		pair := orderedPair(first, second)
+		if _, ok := winners[pair]; ok {
+			return contest, fmt.Errorf("tiktoks %s and %s are compared more than once",
+				result.FirstTiktokURL, result.SecondTiktokURL)
+		}
+		winners[pair] = winner
+	}
+
+	wins := make([]int, len(t))
+	played := make([]int, len(t))
+	countPlayed := 0
+	ranking := []int{0}
+	next := -1 // opponent of tiktok which is being inserted in next match
+	inserted := 1
+	for ; inserted < len(t); inserted++ {
+		low, high := 0, len(ranking)
+		for low < high {
+			middle := (low + high) / 2
+			winner, ok := winners[orderedPair(inserted, ranking[middle])]
+			if !ok {
+				next = ranking[middle]
+				break
+			}
+			countPlayed++
+			played[inserted]++
+			played[ranking[middle]]++
+			wins[winner]++
+			if winner == inserted {
+				high = middle
+			} else {
+				low = middle + 1
+			}
+		}
+		if next != -1 {
+			break
+		}
+		ranking = append(ranking[:low], append([]int{inserted}, ranking[low:]...)...)
+	}
+	if countPlayed != len(winners) {
+		return contest, fmt.Errorf("%d of %d results don't continue ranking", len(winners)-countPlayed, len(winners))
+	}
+
+	contest.Standings = contest.Standings[:0]
+	for i := 0; i < len(t); i++ {
+		tiktok := i
+		if i < len(ranking) {
+			tiktok = ranking[i]
+		}
+		contest.Standings = append(contest.Standings, dtos.Standing{
+			TiktokURL: t[tiktok].URL,
+			Played:    played[tiktok],
+			Wins:      wins[tiktok],
+			Losses:    played[tiktok] - wins[tiktok],
+		})
+	}
+
+	if next == -1 {
+		contest.Rounds = []dtos.Round{}
+		return contest, nil
+	}
+	contest.Rounds = []dtos.Round{swissRound(countPlayed+1, []string{t[inserted].URL, t[next].URL}, options)}
+	return contest, nil
+}
+
+func orderedPair(first int, second int) [2]int {
+	if first > second {
+		return [2]int{second, first}
+	}
+	return [2]int{first, second}
+}
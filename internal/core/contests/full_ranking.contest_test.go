@@ -0,0 +1,113 @@
+package contests
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tiktok-arena/internal/core/dtos"
+)
+
+func TestFullRankingWithLessThanTwoTiktoks(t *testing.T) {
+	for _, countTiktoks := range []int{0, 1} {
+		tiktoks := testTiktoks(countTiktoks)
+
+		contest := FullRanking(tiktoks, Options{Seed: 1})
+		next, err := FullRankingNextRound(tiktoks, nil, Options{Seed: 1})
+
+		assert.Nil(t, err)
+		assert.Empty(t, contest.Rounds, "%d tiktoks", countTiktoks)
+		assert.Empty(t, next.Rounds, "%d tiktoks", countTiktoks)
+		assert.Zero(t, contest.CountMatches, "%d tiktoks", countTiktoks)
+		assert.Len(t, contest.Standings, countTiktoks)
+	}
+}
+
+func TestFullRankingInsertionOrder(t *testing.T) {
+	tiktoks := testTiktoks(4)
+	a, b, c, d := tiktoks[0].URL, tiktoks[1].URL, tiktoks[2].URL, tiktoks[3].URL
+
+	tests := []struct {
+		name      string
+		beats     func(first string, second string) bool // true if first tiktok wins match
+		pairs     [][2]string                            // compared tiktoks of every round in order
+		standings []dtos.Standing
+	}{
+		{
+			name:  "inserted tiktoks win, every comparison is needed",
+			beats: func(first string, second string) bool { return first > second },
+			pairs: [][2]string{{b, a}, {c, a}, {c, b}, {d, b}, {d, c}},
+			standings: []dtos.Standing{
+				{TiktokURL: d, Played: 2, Wins: 2},
+				{TiktokURL: c, Played: 3, Wins: 2, Losses: 1},
+				{TiktokURL: b, Played: 3, Wins: 1, Losses: 2},
+				{TiktokURL: a, Played: 2, Losses: 2},
+			},
+		},
+		{
+			name:  "inserted tiktoks lose, ranking finishes earlier",
+			beats: func(first string, second string) bool { return first < second },
+			pairs: [][2]string{{b, a}, {c, b}, {d, b}, {d, c}},
+			standings: []dtos.Standing{
+				{TiktokURL: a, Played: 1, Wins: 1},
+				{TiktokURL: b, Played: 3, Wins: 2, Losses: 1},
+				{TiktokURL: c, Played: 2, Wins: 1, Losses: 1},
+				{TiktokURL: d, Played: 2, Losses: 2},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			contest := FullRanking(tiktoks, Options{Seed: 1})
+			assert.Equal(t, 5, contest.CountMatches)
+
+			var pairs [][2]string
+			var results []dtos.MatchResult
+			for len(contest.Rounds) > 0 && len(pairs) <= contest.CountMatches {
+				round := contest.Rounds[0]
+				assert.Equal(t, len(results)+1, round.Round)
+				match := round.Matches[0]
+				first := match.FirstOption.(dtos.TiktokOption).TiktokURL
+				second := match.SecondOption.(dtos.TiktokOption).TiktokURL
+				winner := second
+				if test.beats(first, second) {
+					winner = first
+				}
+				pairs = append(pairs, [2]string{first, second})
+				results = append(results, dtos.MatchResult{
+					Round:           round.Round,
+					MatchID:         match.MatchID,
+					FirstTiktokURL:  first,
+					SecondTiktokURL: second,
+					WinnerURL:       winner,
+				})
+
+				var err error
+				contest, err = FullRankingNextRound(tiktoks, results, Options{Seed: 1})
+				assert.Nil(t, err)
+			}
+
+			assert.Equal(t, test.pairs, pairs)
+			assert.Equal(t, test.standings, contest.Standings)
+		})
+	}
+}
+
+func TestFullRankingNextRoundPlacesNotInsertedTiktoksLast(t *testing.T) {
+	tiktoks := testTiktoks(4)
+	a, b, c, d := tiktoks[0].URL, tiktoks[1].URL, tiktoks[2].URL, tiktoks[3].URL
+	results := []dtos.MatchResult{
+		{Round: 1, FirstTiktokURL: b, SecondTiktokURL: a, WinnerURL: b},
+	}
+
+	contest, err := FullRankingNextRound(tiktoks, results, Options{Seed: 1})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []dtos.Standing{
+		{TiktokURL: b, Played: 1, Wins: 1},
+		{TiktokURL: a, Played: 1, Losses: 1},
+		{TiktokURL: c},
+		{TiktokURL: d},
+	}, contest.Standings)
+	assert.Len(t, contest.Rounds, 1)
+	assert.Equal(t, dtos.TiktokOption{TiktokURL: c}, contest.Rounds[0].Matches[0].FirstOption)
+	assert.Equal(t, dtos.TiktokOption{TiktokURL: a}, contest.Rounds[0].Matches[0].SecondOption)
+}
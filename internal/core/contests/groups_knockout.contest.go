@@ -0,0 +1,128 @@
+package contests
+
+import (
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+)
+
+const (
+	groupSize      = 4
+	defaultAdvance = 2
+)
+
+// GroupsKnockout
+// https://en.wikipedia.org/wiki/Group_tournament_ranking_system
+// Tiktoks are expected in order of seeds and are distributed to groups of at most 4 by snake order,
+// so every group gets tiktoks of similar strength. Sizes of groups differ at most by one,
+// e.g. 5 tiktoks play in groups of 3 and 2, 13 tiktoks in three groups of 3 and one group of 4.
+// Every group plays round robin, top Advance tiktoks of every group advance to knockout stage.
+// Knockout stage is single elimination bracket, group winners are top seeds and get byes if needed,
+// its first round options reference positions of group standings.
+func GroupsKnockout(t []models.Tiktok, options Options) dtos.Contest {
+	ids := newMatchIDs(options.Seed)
+	countTiktok := len(t)
+	countGroups := (countTiktok + groupSize - 1) / groupSize
+
+	groups := make([][]models.Tiktok, countGroups)
+	for i, tiktok := range t {
+		pot, group := i/countGroups, i%countGroups
+		if pot%2 != 0 {
+			group = countGroups - 1 - group
+		}
+		groups[group] = append(groups[group], tiktok)
+	}
+
+	// Tiktoks advancing from group have to be less than group size, knockout stage needs at least 2 tiktoks
+	advance := options.Advance
+	if advance == 0 {
+		advance = defaultAdvance
+	}
+	smallestGroup := countTiktok / countGroups
+	if advance > smallestGroup-1 {
+		advance = smallestGroup - 1
+	}
+	if advance < 1 {
+		advance = 1
+	}
+	if advance*countGroups < 2 {
+		advance = 2
+	}
+
+	countMatches := 0
+	countGroupRounds := 0
+	standings := make([]dtos.Standing, 0, countTiktok)
+	groupRounds := make([][]dtos.Round, countGroups)
+	for g, group := range groups {
+		countMatches += len(group) * (len(group) - 1) / 2
+		for _, tiktok := range group {
+			standings = append(standings, dtos.Standing{TiktokURL: tiktok.URL, Group: g + 1})
+		}
+		groupRounds[g] = roundRobinRounds(group, ids)
+		if len(groupRounds[g]) > countGroupRounds {
+			countGroupRounds = len(groupRounds[g])
+		}
+	}
+
+	// Rounds of all groups with same number are placed together
+	rounds := make([]dtos.Round, 0, countGroupRounds*countGroups)
+	for i := 0; i < countGroupRounds; i++ {
+		for g := range groupRounds {
+			if i >= len(groupRounds[g]) {
+				continue
+			}
+			round := groupRounds[g][i]
+			round.Bracket = dtos.GroupStage
+			round.Group = g + 1
+			rounds = append(rounds, round)
+		}
+	}
+
+	participators := make([]dtos.Option, 0, advance*countGroups)
+	for position := 1; position <= advance; position++ {
+		for g := 1; g <= countGroups; g++ {
+			participators = append(participators, dtos.GroupPositionOption{Group: g, Position: position})
+		}
+	}
+	knockout := eliminationRounds(participators, ids)
+	countMatches += len(participators) - 1
+	if options.ThirdPlace && addThirdPlaceMatch(knockout, ids) {
+		countMatches++
+	}
+	for _, round := range knockout {
+		round.Round += countGroupRounds
+		round.Bracket = dtos.KnockoutStage
+		rounds = append(rounds, round)
+	}
+
+	return dtos.Contest{
+		Type:         dtos.GroupsKnockout,
+		Seed:         options.Seed,
+		CountMatches: countMatches,
+		Rounds:       rounds,
+		Standings:    standings,
+	}
+}
+
+// GroupStandings returns standings of group with results (matchID -> URL of winner) of its matches,
+// standings are final if all matches of group are decided
+func GroupStandings(contest dtos.Contest, group int, results map[string]string) (standings []dtos.Standing, final bool) {
+	groupContest := dtos.Contest{}
+	for _, standing := range contest.Standings {
+		if standing.Group == group {
+			groupContest.Standings = append(groupContest.Standings, standing)
+		}
+	}
+	final = true
+	for _, round := range contest.Rounds {
+		if round.Bracket != dtos.GroupStage || round.Group != group {
+			continue
+		}
+		groupContest.Rounds = append(groupContest.Rounds, round)
+		for _, match := range round.Matches {
+			if _, decided := results[match.MatchID]; !decided {
+				final = false
+			}
+		}
+	}
+	return RoundRobinStandings(groupContest, results), final
+}
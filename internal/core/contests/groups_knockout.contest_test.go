@@ -0,0 +1,74 @@
+package contests
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tiktok-arena/internal/core/dtos"
+)
+
+func TestGroupsKnockoutGroups(t *testing.T) {
+	tests := []struct {
+		name            string
+		countTiktoks    int
+		groups          []int // group of every tiktok in order of seeds
+		countKnockout   int   // count of tiktoks advancing to knockout stage
+		countMatches    int
+		countGroupStage int // count of group stage matches
+	}{
+		{name: "single group of two", countTiktoks: 2, groups: []int{1, 1},
+			countKnockout: 2, countMatches: 2, countGroupStage: 1},
+		{name: "uneven groups of 3 and 2", countTiktoks: 5, groups: []int{1, 2, 2, 1, 1},
+			countKnockout: 2, countMatches: 5, countGroupStage: 4},
+		{name: "even groups of 4", countTiktoks: 8, groups: []int{1, 2, 2, 1, 1, 2, 2, 1},
+			countKnockout: 4, countMatches: 15, countGroupStage: 12},
+		{name: "three groups of 3 and one group of 4", countTiktoks: 13,
+			groups:        []int{1, 2, 3, 4, 4, 3, 2, 1, 1, 2, 3, 4, 4},
+			countKnockout: 8, countMatches: 22, countGroupStage: 15},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tiktoks := testTiktoks(test.countTiktoks)
+			contest := GroupsKnockout(tiktoks, Options{Seed: 1})
+
+			groups := map[string]int{}
+			for _, standing := range contest.Standings {
+				groups[standing.TiktokURL] = standing.Group
+			}
+			for i, tiktok := range tiktoks {
+				assert.Equal(t, test.groups[i], groups[tiktok.URL], tiktok.URL)
+			}
+
+			countGroupStage := 0
+			advancing := map[dtos.GroupPositionOption]bool{}
+			for _, round := range contest.Rounds {
+				if round.Bracket == dtos.GroupStage {
+					countGroupStage += len(round.Matches)
+					continue
+				}
+				for _, match := range round.Matches {
+					for _, option := range []dtos.Option{match.FirstOption, match.SecondOption} {
+						if position, ok := option.(dtos.GroupPositionOption); ok {
+							advancing[position] = true
+						}
+					}
+				}
+			}
+			assert.Equal(t, test.countGroupStage, countGroupStage)
+			assert.Len(t, advancing, test.countKnockout)
+			assert.Equal(t, test.countMatches, contest.CountMatches)
+		})
+	}
+}
+
+func TestGroupsKnockoutIsPlayedToWinner(t *testing.T) {
+	for _, countTiktoks := range []int{2, 5, 8, 13} {
+		contest := GroupsKnockout(testTiktoks(countTiktoks), Options{Seed: 1, ThirdPlace: true})
+		progress := NewProgress(contest)
+
+		decisions := playFirstOption(progress)
+
+		assert.Len(t, decisions, contest.CountMatches, "%d tiktoks", countTiktoks)
+		_, finished := progress.Winner()
+		assert.True(t, finished, "%d tiktoks", countTiktoks)
+	}
+}
@@ -1,7 +1,6 @@
 package contests
 
 import (
-	"github.com/google/uuid"
 	"tiktok-arena/internal/core/dtos"
 	"tiktok-arena/internal/core/models"
 )
@@ -10,11 +9,13 @@ import (
 // First match decided randomly between two participators.
 // Loser of match leaves the game, winner will go to next match, next opponent decided randomly from standings.
 // Procedure continues until last standing.
-func KingOfTheHill(t []models.Tiktok) dtos.Contest {
+// If BestOf option is bigger than 1, every match is won by majority of BestOf votes.
+func KingOfTheHill(t []models.Tiktok, options Options) dtos.Contest {
+	ids := newMatchIDs(options.Seed)
 	countTiktok := len(t)
 	rounds := make([]dtos.Round, 0, countTiktok-1)
 	match := dtos.Match{
-		MatchID:      uuid.NewString(),
+		MatchID:      ids.next(),
 		FirstOption:  dtos.TiktokOption{TiktokURL: t[0].URL},
 		SecondOption: dtos.TiktokOption{TiktokURL: t[1].URL},
 	}
@@ -23,9 +24,9 @@ func KingOfTheHill(t []models.Tiktok) dtos.Contest {
 		Matches: []dtos.Match{match},
 	})
 	previousMatch := match
-	for i := 2; i < countTiktok-1; i++ {
+	for i := 2; i < countTiktok; i++ {
 		match = dtos.Match{
-			MatchID:      uuid.NewString(),
+			MatchID:      ids.next(),
 			FirstOption:  dtos.MatchOption{MatchID: previousMatch.MatchID},
 			SecondOption: dtos.TiktokOption{TiktokURL: t[i].URL},
 		}
@@ -35,7 +36,10 @@ func KingOfTheHill(t []models.Tiktok) dtos.Contest {
 		})
 		previousMatch = match
 	}
+	setWinsRequired(rounds, options.BestOf)
 	return dtos.Contest{
+		Type:         dtos.KingOfTheHill,
+		Seed:         options.Seed,
 		CountMatches: countTiktok - 1,
 		Rounds:       rounds,
 	}
@@ -20,7 +20,7 @@ func BenchmarkKingOfTheHill(b *testing.B) {
 	}
 
 	for n := 0; n < b.N; n++ {
-		result := KingOfTheHill(tiktoks)
+		result := KingOfTheHill(tiktoks, Options{})
 		_ = result
 	}
 }
@@ -0,0 +1,37 @@
+package contests
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tiktok-arena/internal/core/dtos"
+)
+
+func TestKingOfTheHillEveryTiktokPlays(t *testing.T) {
+	tests := []struct {
+		name         string
+		countTiktoks int
+	}{
+		{name: "two tiktoks", countTiktoks: 2},
+		{name: "three tiktoks", countTiktoks: 3},
+		{name: "eight tiktoks", countTiktoks: 8},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tiktoks := testTiktoks(test.countTiktoks)
+			contest := KingOfTheHill(tiktoks, Options{Seed: 1})
+
+			assert.Equal(t, test.countTiktoks-1, contest.CountMatches)
+			assert.Len(t, contest.Rounds, test.countTiktoks-1)
+			// Winner of every match plays next tiktok, last tiktok plays in last match
+			for i, round := range contest.Rounds {
+				assert.Len(t, round.Matches, 1)
+				match := round.Matches[0]
+				assert.Equal(t, dtos.TiktokOption{TiktokURL: tiktoks[i+1].URL}, match.SecondOption)
+				if i > 0 {
+					previous := contest.Rounds[i-1].Matches[0]
+					assert.Equal(t, dtos.MatchOption{MatchID: previous.MatchID}, match.FirstOption)
+				}
+			}
+		})
+	}
+}
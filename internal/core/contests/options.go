@@ -0,0 +1,43 @@
+package contests
+
+import (
+	"github.com/google/uuid"
+	"math/rand"
+	"tiktok-arena/internal/core/dtos"
+)
+
+// Options
+// Options of contest generation, contest generated twice from same tiktoks and options is the same.
+type Options struct {
+	Seed         int64 // seed of match IDs, returned in contest
+	BracketReset bool  // only for double elimination
+	ThirdPlace   bool  // only for single elimination and groups knockout
+	Advance      int   // count of tiktoks advancing from every group, only for groups knockout
+	BestOf       int   // count of votes in match, only for single elimination and king of the hill
+}
+
+// setWinsRequired sets count of votes required to win every match of rounds played as best-of-N
+func setWinsRequired(rounds []dtos.Round, bestOf int) {
+	if bestOf <= 1 {
+		return
+	}
+	for i := range rounds {
+		for j := range rounds[i].Matches {
+			rounds[i].Matches[j].WinsRequired = bestOf/2 + 1
+		}
+	}
+}
+
+// matchIDs generates match IDs from random source seeded with seed of contest
+type matchIDs struct {
+	rand *rand.Rand
+}
+
+func newMatchIDs(seed int64) *matchIDs {
+	return &matchIDs{rand: rand.New(rand.NewSource(seed))}
+}
+
+func (g *matchIDs) next() string {
+	id, _ := uuid.NewRandomFromReader(g.rand) // Reading from rand.Rand never fails
+	return id.String()
+}
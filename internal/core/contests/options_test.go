@@ -0,0 +1,94 @@
+package contests
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+)
+
+func testTiktoks(count int) []models.Tiktok {
+	var tiktoks []models.Tiktok
+	for i := 0; i < count; i++ {
+		tiktoks = append(tiktoks, models.Tiktok{
+			Name: fmt.Sprint("name", i),
+			URL:  fmt.Sprint("testurl", i),
+		})
+	}
+	return tiktoks
+}
+
+func testContests(t []models.Tiktok, options Options) map[string]dtos.Contest {
+	return map[string]dtos.Contest{
+		dtos.SingleElimination: SingleElimination(t, options),
+		dtos.KingOfTheHill:     KingOfTheHill(t, options),
+		dtos.DoubleElimination: DoubleElimination(t, Options{Seed: options.Seed, BracketReset: true}),
+		dtos.RoundRobin:        RoundRobin(t, options),
+		dtos.Swiss:             Swiss(t, options),
+		dtos.GroupsKnockout:    GroupsKnockout(t, options),
+		dtos.FullRanking:       FullRanking(t, options),
+	}
+}
+
+func TestContestsWithSameSeedAreEqual(t *testing.T) {
+	tiktoks := testTiktoks(13)
+	first := testContests(tiktoks, Options{Seed: 42})
+	second := testContests(tiktoks, Options{Seed: 42})
+	for contestType, contest := range first {
+		assert.Equal(t, int64(42), contest.Seed, contestType)
+		assert.Equal(t, contest, second[contestType], "%s: contests generated with same seed are different", contestType)
+	}
+}
+
+func TestContestsWithDifferentSeedsHaveDifferentMatchIDs(t *testing.T) {
+	tiktoks := testTiktoks(13)
+	first := testContests(tiktoks, Options{Seed: 1})
+	second := testContests(tiktoks, Options{Seed: 2})
+	for contestType, contest := range first {
+		assert.NotEqual(t, contest.Rounds[0].Matches[0].MatchID, second[contestType].Rounds[0].Matches[0].MatchID,
+			"%s: contests generated with different seeds have same match IDs", contestType)
+	}
+}
+
+func TestContestMatchIDsAreUnique(t *testing.T) {
+	for contestType, contest := range testContests(testTiktoks(13), Options{Seed: 7}) {
+		ids := make(map[string]bool)
+		for _, round := range contest.Rounds {
+			for _, match := range round.Matches {
+				assert.False(t, ids[match.MatchID], "%s: match ID %s is repeated", contestType, match.MatchID)
+				ids[match.MatchID] = true
+			}
+		}
+	}
+}
+
+func TestSwissNextRoundWithSameSeedIsEqual(t *testing.T) {
+	tiktoks := testTiktoks(8)
+	options := Options{Seed: 42}
+	contest := Swiss(tiktoks, options)
+	results := firstOptionWins(contest.Rounds[0])
+
+	first, err := SwissNextRound(tiktoks, results, options)
+	assert.Nil(t, err)
+	second, err := SwissNextRound(tiktoks, results, options)
+	assert.Nil(t, err)
+
+	assert.Equal(t, first, second, "next rounds paired with same seed are different")
+	assert.NotEqual(t, contest.Rounds[0].Matches[0].MatchID, first.Rounds[0].Matches[0].MatchID,
+		"next round repeats match IDs of first round")
+}
+
+func TestSingleEliminationByesGoToTopSeeds(t *testing.T) {
+	tiktoks := testTiktoks(6)
+	contest := SingleElimination(tiktoks, Options{Seed: 42})
+
+	seeded := make(map[string]bool)
+	for _, match := range contest.Rounds[1].Matches {
+		if option, ok := match.FirstOption.(dtos.TiktokOption); ok {
+			seeded[option.TiktokURL] = true
+		}
+	}
+	assert.Equal(t, map[string]bool{tiktoks[0].URL: true, tiktoks[1].URL: true}, seeded,
+		"expected byes for two top seeds")
+}
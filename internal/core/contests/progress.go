@@ -0,0 +1,365 @@
+package contests
+
+import (
+	"fmt"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+)
+
+// Progress
+// State of contest after decided matches, used to continue contest and to find its winner.
+type Progress struct {
+	contest dtos.Contest
+	matches map[string]dtos.Match
+	winners map[string]string         // matchID -> URL of winner
+	votes   map[string]map[string]int // matchID -> URL of tiktok -> count of votes, only for undecided matches
+}
+
+func NewProgress(contest dtos.Contest) *Progress {
+	p := &Progress{
+		contest: contest,
+		matches: make(map[string]dtos.Match),
+		winners: make(map[string]string),
+		votes:   make(map[string]map[string]int),
+	}
+	for _, round := range contest.Rounds {
+		for _, match := range round.Matches {
+			p.matches[match.MatchID] = match
+		}
+	}
+	return p
+}
+
+// Contest returns contest with all paired rounds
+func (p *Progress) Contest() dtos.Contest {
+	return p.contest
+}
+
+// TiktokURLs returns URLs of tiktoks participating in contest,
+// elimination contests have no standings, so their tiktoks are taken from match options
+func (p *Progress) TiktokURLs() []string {
+	urls := make([]string, 0, len(p.contest.Standings))
+	added := make(map[string]bool, len(p.contest.Standings))
+	add := func(url string) {
+		if !added[url] {
+			added[url] = true
+			urls = append(urls, url)
+		}
+	}
+	for _, standing := range p.contest.Standings {
+		add(standing.TiktokURL)
+	}
+	for _, round := range p.contest.Rounds {
+		for _, match := range round.Matches {
+			for _, option := range []dtos.Option{match.FirstOption, match.SecondOption} {
+				if tiktok, ok := option.(dtos.TiktokOption); ok {
+					add(tiktok.TiktokURL)
+				}
+			}
+		}
+	}
+	return urls
+}
+
+// PairNextRound pairs next round of contests paired by server (swiss, full ranking) when all paired rounds are decided,
+// returns true if round was added
+func (p *Progress) PairNextRound() (bool, error) {
+	if !dtos.CheckIfServerPairedContestType(p.contest.Type) ||
+		len(p.contest.Rounds) >= p.contest.CountRounds ||
+		!p.IsRoundsFinished() {
+		return false, nil
+	}
+	contest, err := p.nextRound()
+	if err != nil {
+		return false, err
+	}
+	if len(contest.Rounds) == 0 {
+		return false, nil
+	}
+	round := contest.Rounds[0]
+	p.contest.Rounds = append(p.contest.Rounds, round)
+	for _, match := range round.Matches {
+		p.matches[match.MatchID] = match
+	}
+	return true, nil
+}
+
+// Participators returns URLs of both options of match, if they are already known
+func (p *Progress) Participators(matchID string) (first string, second string, err error) {
+	match, ok := p.matches[matchID]
+	if !ok {
+		return first, second, fmt.Errorf("match %s doesn't exist in contest", matchID)
+	}
+	first, ok = p.resolve(match.FirstOption)
+	if !ok {
+		return first, second, fmt.Errorf("first option of match %s is not decided yet", matchID)
+	}
+	second, ok = p.resolve(match.SecondOption)
+	if !ok {
+		return first, second, fmt.Errorf("second option of match %s is not decided yet", matchID)
+	}
+	return
+}
+
+// Decide sets winner of match, match must be ready to play and not decided yet
+func (p *Progress) Decide(matchID string, winnerURL string) error {
+	if _, decided := p.winners[matchID]; decided {
+		return fmt.Errorf("match %s is already decided", matchID)
+	}
+	first, second, err := p.Participators(matchID)
+	if err != nil {
+		return err
+	}
+	if !p.isNecessary(p.matches[matchID]) {
+		return fmt.Errorf("match %s is not necessary", matchID)
+	}
+	if winnerURL != first && winnerURL != second {
+		return fmt.Errorf("tiktok %s doesn't play in match %s", winnerURL, matchID)
+	}
+	p.winners[matchID] = winnerURL
+	return nil
+}
+
+// Vote adds vote for tiktok in match, match must be ready to play and not decided yet.
+// Match is decided when tiktok gets required count of votes, returns true if match was decided by vote.
+func (p *Progress) Vote(matchID string, tiktokURL string) (bool, error) {
+	if _, decided := p.winners[matchID]; decided {
+		return false, fmt.Errorf("match %s is already decided", matchID)
+	}
+	first, second, err := p.Participators(matchID)
+	if err != nil {
+		return false, err
+	}
+	if tiktokURL != first && tiktokURL != second {
+		return false, fmt.Errorf("tiktok %s doesn't play in match %s", tiktokURL, matchID)
+	}
+	match := p.matches[matchID]
+	if !p.isNecessary(match) {
+		return false, fmt.Errorf("match %s is not necessary", matchID)
+	}
+	if p.votes[matchID] == nil {
+		p.votes[matchID] = make(map[string]int)
+	}
+	p.votes[matchID][tiktokURL]++
+	if p.votes[matchID][tiktokURL] < match.WinsRequired {
+		return false, nil
+	}
+	err = p.Decide(matchID, tiktokURL)
+	if err != nil {
+		p.votes[matchID][tiktokURL]--
+		return false, err
+	}
+	delete(p.votes, matchID)
+	return true, nil
+}
+
+// IsDecided checks if winner of match is known
+func (p *Progress) IsDecided(matchID string) bool {
+	_, decided := p.winners[matchID]
+	return decided
+}
+
+// Results returns decided matches in order of rounds
+func (p *Progress) Results() []dtos.MatchResult {
+	results := make([]dtos.MatchResult, 0, len(p.winners))
+	for _, round := range p.contest.Rounds {
+		for _, match := range round.Matches {
+			winner, ok := p.winners[match.MatchID]
+			if !ok {
+				continue
+			}
+			first, second, _ := p.Participators(match.MatchID)
+			results = append(results, dtos.MatchResult{
+				Round:           round.Round,
+				MatchID:         match.MatchID,
+				FirstTiktokURL:  first,
+				SecondTiktokURL: second,
+				WinnerURL:       winner,
+			})
+		}
+	}
+	return results
+}
+
+// IsRoundsFinished checks if all necessary matches of generated rounds are decided
+func (p *Progress) IsRoundsFinished() bool {
+	for id, match := range p.matches {
+		if _, decided := p.winners[id]; !decided && p.isNecessary(match) {
+			return false
+		}
+	}
+	return true
+}
+
+// Standings returns current standings for contests with standings table
+func (p *Progress) Standings() []dtos.Standing {
+	switch p.contest.Type {
+	case dtos.RoundRobin:
+		return RoundRobinStandings(p.contest, p.winners)
+	case dtos.Swiss, dtos.FullRanking:
+		contest, err := p.nextRound()
+		if err != nil {
+			return p.contest.Standings
+		}
+		return contest.Standings
+	case dtos.GroupsKnockout:
+		// Standings of groups one after another
+		standings := make([]dtos.Standing, 0, len(p.contest.Standings))
+		for group := 1; ; group++ {
+			groupStandings, _ := GroupStandings(p.contest, group, p.winners)
+			if len(groupStandings) == 0 {
+				return standings
+			}
+			standings = append(standings, groupStandings...)
+		}
+	}
+	return p.contest.Standings
+}
+
+// Winner returns URL of contest winner when contest is finished
+func (p *Progress) Winner() (string, bool) {
+	if len(p.contest.Rounds) == 0 || !p.IsRoundsFinished() {
+		return "", false
+	}
+	switch p.contest.Type {
+	case dtos.RoundRobin, dtos.Swiss, dtos.FullRanking:
+		if p.contest.Type == dtos.FullRanking {
+			// Ranking is finished when there is no next comparison
+			contest, err := p.nextRound()
+			if err != nil || len(contest.Rounds) != 0 {
+				return "", false
+			}
+		} else if len(p.contest.Rounds) < p.contest.CountRounds {
+			return "", false
+		}
+		standings := p.Standings()
+		if len(standings) == 0 {
+			return "", false
+		}
+		return standings[0].TiktokURL, true
+	}
+	// Winner of elimination contests is winner of last necessary match
+	winner, ok := p.winners[p.finalMatch().MatchID]
+	return winner, ok
+}
+
+// Podium returns tiktoks on first three places when contest is finished
+func (p *Progress) Podium() (podium dtos.Podium, finished bool) {
+	podium.First, finished = p.Winner()
+	if !finished {
+		return podium, false
+	}
+	switch p.contest.Type {
+	case dtos.RoundRobin, dtos.Swiss, dtos.FullRanking:
+		standings := p.Standings()
+		if len(standings) > 1 {
+			podium.Second = standings[1].TiktokURL
+		}
+		if len(standings) > 2 {
+			podium.Third = standings[2].TiktokURL
+		}
+		return podium, true
+	}
+
+	final := p.finalMatch()
+	podium.Second, _ = p.resolve(dtos.LoserOption{MatchID: final.MatchID})
+	switch p.contest.Type {
+	case dtos.SingleElimination, dtos.GroupsKnockout:
+		// Third place match is played in last round before final
+		lastRound := p.contest.Rounds[len(p.contest.Rounds)-1]
+		if len(lastRound.Matches) > 1 {
+			podium.Third = p.winners[lastRound.Matches[0].MatchID]
+		}
+	case dtos.KingOfTheHill:
+		// Third place is taken by last tiktok eliminated before final
+		if previous, ok := final.FirstOption.(dtos.MatchOption); ok {
+			podium.Third, _ = p.resolve(dtos.LoserOption{MatchID: previous.MatchID})
+		}
+	case dtos.DoubleElimination:
+		// Third place is taken by loser of losers bracket final
+		if final.IfNecessary {
+			final = p.matches[final.FirstOption.(dtos.MatchOption).MatchID]
+		}
+		if losersFinal, ok := final.SecondOption.(dtos.MatchOption); ok {
+			podium.Third, _ = p.resolve(dtos.LoserOption{MatchID: losersFinal.MatchID})
+		}
+	}
+	return podium, true
+}
+
+// finalMatch returns last necessary match of elimination contest
+func (p *Progress) finalMatch() dtos.Match {
+	lastRound := p.contest.Rounds[len(p.contest.Rounds)-1]
+	final := lastRound.Matches[len(lastRound.Matches)-1]
+	if !p.isNecessary(final) {
+		final = p.matches[final.FirstOption.(dtos.MatchOption).MatchID]
+	}
+	return final
+}
+
+// resolve returns URL of tiktok which option refers to, if it is already known
+func (p *Progress) resolve(option dtos.Option) (string, bool) {
+	switch o := option.(type) {
+	case dtos.TiktokOption:
+		return o.TiktokURL, true
+	case dtos.MatchOption:
+		winner, ok := p.winners[o.MatchID]
+		return winner, ok
+	case dtos.GroupPositionOption:
+		standings, final := GroupStandings(p.contest, o.Group, p.winners)
+		if !final || o.Position < 1 || o.Position > len(standings) {
+			return "", false
+		}
+		return standings[o.Position-1].TiktokURL, true
+	case dtos.LoserOption:
+		winner, ok := p.winners[o.MatchID]
+		if !ok {
+			return "", false
+		}
+		first, second, err := p.Participators(o.MatchID)
+		if err != nil {
+			return "", false
+		}
+		if winner == first {
+			return second, true
+		}
+		return first, true
+	}
+	return "", false
+}
+
+// isNecessary checks if match has to be played,
+// match marked with IfNecessary is played only if second option wins previous match
+func (p *Progress) isNecessary(match dtos.Match) bool {
+	if !match.IfNecessary {
+		return true
+	}
+	previous, ok := match.FirstOption.(dtos.MatchOption)
+	if !ok {
+		return true
+	}
+	winner, decided := p.winners[previous.MatchID]
+	if !decided {
+		return true
+	}
+	_, second, err := p.Participators(previous.MatchID)
+	return err == nil && winner == second
+}
+
+// nextRound pairs next round of contest paired by server with results of decided matches
+func (p *Progress) nextRound() (dtos.Contest, error) {
+	options := Options{Seed: p.contest.Seed}
+	if p.contest.Type == dtos.FullRanking {
+		return FullRankingNextRound(p.participators(), p.Results(), options)
+	}
+	return SwissNextRound(p.participators(), p.Results(), options)
+}
+
+// participators returns tiktoks from initial standings of contest
+func (p *Progress) participators() []models.Tiktok {
+	tiktoks := make([]models.Tiktok, 0, len(p.contest.Standings))
+	for _, standing := range p.contest.Standings {
+		tiktoks = append(tiktoks, models.Tiktok{URL: standing.TiktokURL})
+	}
+	return tiktoks
+}
@@ -0,0 +1,154 @@
+package contests
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tiktok-arena/internal/core/dtos"
+)
+
+// playFirstOption decides every playable match of contest with first option as winner
+// until no match can be decided, returns decisions in order they were made
+func playFirstOption(p *Progress) []dtos.MatchResult {
+	var decisions []dtos.MatchResult
+	for {
+		decided := false
+		for _, round := range p.Contest().Rounds {
+			for _, match := range round.Matches {
+				if p.IsDecided(match.MatchID) {
+					continue
+				}
+				first, second, err := p.Participators(match.MatchID)
+				if err != nil || p.Decide(match.MatchID, first) != nil {
+					continue
+				}
+				decisions = append(decisions, dtos.MatchResult{
+					Round:           round.Round,
+					MatchID:         match.MatchID,
+					FirstTiktokURL:  first,
+					SecondTiktokURL: second,
+					WinnerURL:       first,
+				})
+				decided = true
+			}
+		}
+		if !decided {
+			return decisions
+		}
+	}
+}
+
+func TestProgressReplaysDecisions(t *testing.T) {
+	tiktoks := testTiktoks(4)
+	contest := SingleElimination(tiktoks, Options{Seed: 1, ThirdPlace: true})
+	progress := NewProgress(contest)
+	decisions := playFirstOption(progress)
+
+	replayed := NewProgress(contest)
+	for _, decision := range decisions {
+		assert.Nil(t, replayed.Decide(decision.MatchID, decision.WinnerURL))
+	}
+
+	assert.Len(t, decisions, 4)
+	assert.Equal(t, progress.Results(), replayed.Results())
+	podium, finished := replayed.Podium()
+	assert.True(t, finished)
+	assert.Equal(t, dtos.Podium{First: tiktoks[0].URL, Second: tiktoks[1].URL, Third: tiktoks[3].URL}, podium)
+}
+
+func TestProgressDecideRejectsInvalidDecisions(t *testing.T) {
+	tiktoks := testTiktoks(4)
+	contest := SingleElimination(tiktoks, Options{Seed: 1})
+	semifinal := contest.Rounds[0].Matches[0]
+	final := contest.Rounds[1].Matches[0]
+
+	tests := []struct {
+		name    string
+		decided []dtos.MatchResult // decisions made before tested one
+		matchID string
+		winner  string
+	}{
+		{name: "unknown match", matchID: "unknown", winner: tiktoks[0].URL},
+		{name: "tiktok doesn't play in match", matchID: semifinal.MatchID, winner: tiktoks[1].URL},
+		{name: "participators are not known yet", matchID: final.MatchID, winner: tiktoks[0].URL},
+		{name: "match is already decided", decided: []dtos.MatchResult{
+			{MatchID: semifinal.MatchID, WinnerURL: tiktoks[0].URL},
+		}, matchID: semifinal.MatchID, winner: tiktoks[3].URL},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			progress := NewProgress(contest)
+			for _, decision := range test.decided {
+				assert.Nil(t, progress.Decide(decision.MatchID, decision.WinnerURL))
+			}
+
+			assert.NotNil(t, progress.Decide(test.matchID, test.winner))
+		})
+	}
+}
+
+func TestProgressVoteDecidesBestOfMatch(t *testing.T) {
+	tiktoks := testTiktoks(3)
+	contest := KingOfTheHill(tiktoks, Options{Seed: 1, BestOf: 3})
+	match := contest.Rounds[0].Matches[0]
+	progress := NewProgress(contest)
+
+	decided, err := progress.Vote(match.MatchID, tiktoks[1].URL)
+	assert.Nil(t, err)
+	assert.False(t, decided)
+	decided, err = progress.Vote(match.MatchID, tiktoks[0].URL)
+	assert.Nil(t, err)
+	assert.False(t, decided)
+	decided, err = progress.Vote(match.MatchID, tiktoks[1].URL)
+	assert.Nil(t, err)
+	assert.True(t, decided)
+
+	_, err = progress.Vote(match.MatchID, tiktoks[1].URL)
+	assert.NotNil(t, err)
+	first, second, err := progress.Participators(contest.Rounds[1].Matches[0].MatchID)
+	assert.Nil(t, err)
+	assert.Equal(t, tiktoks[1].URL, first)
+	assert.Equal(t, tiktoks[2].URL, second)
+}
+
+func TestProgressWinnerOfDoubleElimination(t *testing.T) {
+	tests := []struct {
+		name         string
+		bracketReset bool
+	}{
+		{name: "without bracket reset"},
+		{name: "winner of winners bracket wins grand final, reset is not played", bracketReset: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tiktoks := testTiktoks(4)
+			progress := NewProgress(DoubleElimination(tiktoks, Options{Seed: 1, BracketReset: test.bracketReset}))
+			decisions := playFirstOption(progress)
+
+			assert.Len(t, decisions, 6)
+			assert.True(t, progress.IsRoundsFinished())
+			winner, finished := progress.Winner()
+			assert.True(t, finished)
+			assert.Equal(t, tiktoks[0].URL, winner)
+		})
+	}
+}
+
+func TestProgressTiktokURLs(t *testing.T) {
+	tiktoks := testTiktoks(5)
+	options := Options{Seed: 1}
+	for contestType, contest := range map[string]dtos.Contest{
+		dtos.SingleElimination: SingleElimination(tiktoks, options),
+		dtos.KingOfTheHill:     KingOfTheHill(tiktoks, options),
+		dtos.DoubleElimination: DoubleElimination(tiktoks, options),
+		dtos.RoundRobin:        RoundRobin(tiktoks, options),
+		dtos.Swiss:             Swiss(tiktoks, options),
+		dtos.GroupsKnockout:    GroupsKnockout(tiktoks, options),
+		dtos.FullRanking:       FullRanking(tiktoks, options),
+	} {
+		urls := NewProgress(contest).TiktokURLs()
+
+		assert.ElementsMatch(t, []string{
+			tiktoks[0].URL, tiktoks[1].URL, tiktoks[2].URL, tiktoks[3].URL, tiktoks[4].URL,
+		}, urls, contestType)
+	}
+}
@@ -0,0 +1,172 @@
+package contests
+
+import (
+	"sort"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+)
+
+// RoundRobin
+// https://en.wikipedia.org/wiki/Round-robin_tournament#Circle_method
+// Every participator plays against every other participator once.
+// First participator is fixed, others rotate clockwise after each round.
+// With odd count of participators one of them rests in each round.
+func RoundRobin(t []models.Tiktok, options Options) dtos.Contest {
+	countTiktok := len(t)
+	standings := make([]dtos.Standing, 0, countTiktok)
+	for _, tiktok := range t {
+		standings = append(standings, dtos.Standing{TiktokURL: tiktok.URL})
+	}
+
+	return dtos.Contest{
+		Type:         dtos.RoundRobin,
+		Seed:         options.Seed,
+		CountMatches: countTiktok * (countTiktok - 1) / 2,
+		Rounds:       roundRobinRounds(t, newMatchIDs(options.Seed)),
+		Standings:    standings,
+	}
+}
+
+// roundRobinRounds generates rounds of round robin contest with circle method
+func roundRobinRounds(t []models.Tiktok, ids *matchIDs) []dtos.Round {
+	countTiktok := len(t)
+	participators := make([]*models.Tiktok, 0, countTiktok+1)
+	for i := range t {
+		participators = append(participators, &t[i])
+	}
+	if countTiktok%2 != 0 {
+		participators = append(participators, nil) // Pair with nil means rest
+	}
+	countParticipators := len(participators)
+
+	rounds := make([]dtos.Round, 0, countParticipators-1)
+	for roundID := 1; roundID < countParticipators; roundID++ {
+		matches := make([]dtos.Match, 0, countParticipators/2)
+		for i := 0; i < countParticipators/2; i++ {
+			first, second := participators[i], participators[countParticipators-1-i]
+			if first == nil || second == nil {
+				continue
+			}
+			matches = append(matches, dtos.Match{
+				MatchID:      ids.next(),
+				FirstOption:  dtos.TiktokOption{TiktokURL: first.URL},
+				SecondOption: dtos.TiktokOption{TiktokURL: second.URL},
+			})
+		}
+		rounds = append(rounds, dtos.Round{
+			Round:   roundID,
+			Matches: matches,
+		})
+		// Rotating all participators except first
+		last := participators[countParticipators-1]
+		copy(participators[2:], participators[1:countParticipators-1])
+		participators[1] = last
+	}
+	return rounds
+}
+
+// RoundRobinStandings
+// Fills standings of contest with results (matchID -> URL of winner) of matches between tiktoks.
+// Standings are sorted by wins, ties are broken by head-to-head results (see breakTie),
+// remaining ties keep order of contest standings.
+func RoundRobinStandings(contest dtos.Contest, results map[string]string) []dtos.Standing {
+	standings := make([]dtos.Standing, len(contest.Standings))
+	positions := make(map[string]int, len(contest.Standings))
+	for i, standing := range contest.Standings {
+		standings[i] = dtos.Standing{TiktokURL: standing.TiktokURL, Group: standing.Group}
+		positions[standing.TiktokURL] = i
+	}
+
+	var played []playedMatch
+	for _, round := range contest.Rounds {
+		for _, match := range round.Matches {
+			winner, ok := results[match.MatchID]
+			if !ok {
+				continue
+			}
+			first, isFirstTiktok := match.FirstOption.(dtos.TiktokOption)
+			second, isSecondTiktok := match.SecondOption.(dtos.TiktokOption)
+			if !isFirstTiktok || !isSecondTiktok {
+				continue
+			}
+			loser := first.TiktokURL
+			if winner == first.TiktokURL {
+				loser = second.TiktokURL
+			} else if winner != second.TiktokURL {
+				continue
+			}
+			winnerPosition, winnerOk := positions[winner]
+			loserPosition, loserOk := positions[loser]
+			if !winnerOk || !loserOk {
+				continue
+			}
+			standings[winnerPosition].Played++
+			standings[winnerPosition].Wins++
+			standings[loserPosition].Played++
+			standings[loserPosition].Losses++
+			played = append(played, playedMatch{winner: winner, loser: loser})
+		}
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		return standings[i].Wins > standings[j].Wins
+	})
+
+	// Breaking ties with head-to-head results inside every group of tiktoks with equal wins
+	forEachTie(standings, func(standing dtos.Standing) int {
+		return standing.Wins
+	}, func(tied []dtos.Standing) {
+		breakTie(tied, played)
+	})
+
+	return standings
+}
+
+// breakTie sorts tied tiktoks by head-to-head results: two tiktoks by their direct matches,
+// larger groups by wins in matches between tied tiktoks (mini-league).
+// Tiktoks which are still tied inside mini-league are compared again only among themselves,
+// so of two remaining tiktoks the one which beat the other is placed higher.
+func breakTie(tied []dtos.Standing, played []playedMatch) {
+	headToHeadWins := make(map[string]int, len(tied))
+	for _, standing := range tied {
+		headToHeadWins[standing.TiktokURL] = 0
+	}
+	for _, match := range played {
+		_, winnerTied := headToHeadWins[match.winner]
+		_, loserTied := headToHeadWins[match.loser]
+		if winnerTied && loserTied {
+			headToHeadWins[match.winner]++
+		}
+	}
+	sort.SliceStable(tied, func(i, j int) bool {
+		return headToHeadWins[tied[i].TiktokURL] > headToHeadWins[tied[j].TiktokURL]
+	})
+
+	forEachTie(tied, func(standing dtos.Standing) int {
+		return headToHeadWins[standing.TiktokURL]
+	}, func(stillTied []dtos.Standing) {
+		// Whole group is still tied (e.g. cycle), comparing it again changes nothing
+		if len(stillTied) < len(tied) {
+			breakTie(stillTied, played)
+		}
+	})
+}
+
+// forEachTie calls breakTie with every group of neighbouring standings with equal score
+func forEachTie(standings []dtos.Standing, score func(dtos.Standing) int, breakTie func(tied []dtos.Standing)) {
+	for start := 0; start < len(standings); {
+		end := start + 1
+		for end < len(standings) && score(standings[end]) == score(standings[start]) {
+			end++
+		}
+		if end-start > 1 {
+			breakTie(standings[start:end])
+		}
+		start = end
+	}
+}
+
+type playedMatch struct {
+	winner string
+	loser  string
+}
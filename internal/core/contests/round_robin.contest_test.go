@@ -0,0 +1,144 @@
+package contests
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tiktok-arena/internal/core/dtos"
+)
+
+func TestRoundRobinEveryPairPlaysOnce(t *testing.T) {
+	tests := []struct {
+		name         string
+		countTiktoks int
+		countRounds  int
+	}{
+		{name: "two tiktoks", countTiktoks: 2, countRounds: 1},
+		{name: "even count", countTiktoks: 6, countRounds: 5},
+		{name: "odd count, one tiktok rests every round", countTiktoks: 5, countRounds: 5},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tiktoks := testTiktoks(test.countTiktoks)
+			contest := RoundRobin(tiktoks, Options{Seed: 1})
+
+			assert.Len(t, contest.Rounds, test.countRounds)
+			assert.Len(t, contest.Standings, test.countTiktoks)
+			pairs := map[[2]string]int{}
+			countMatches := 0
+			for _, round := range contest.Rounds {
+				assert.Len(t, round.Matches, test.countTiktoks/2)
+				inRound := map[string]bool{}
+				for _, match := range round.Matches {
+					first := match.FirstOption.(dtos.TiktokOption).TiktokURL
+					second := match.SecondOption.(dtos.TiktokOption).TiktokURL
+					assert.False(t, inRound[first] || inRound[second], "tiktok plays twice in round %d", round.Round)
+					inRound[first], inRound[second] = true, true
+					if first > second {
+						first, second = second, first
+					}
+					pairs[[2]string{first, second}]++
+					countMatches++
+				}
+			}
+			assert.Equal(t, contest.CountMatches, countMatches)
+			assert.Len(t, pairs, test.countTiktoks*(test.countTiktoks-1)/2)
+			for pair, count := range pairs {
+				assert.Equal(t, 1, count, "%v", pair)
+			}
+		})
+	}
+}
+
+func TestRoundRobinStandings(t *testing.T) {
+	tiktoks := testTiktoks(4)
+	a, b, c, d := tiktoks[0].URL, tiktoks[1].URL, tiktoks[2].URL, tiktoks[3].URL
+
+	tests := []struct {
+		name      string
+		winners   [][2]string // winner and loser of decided matches
+		standings []dtos.Standing
+	}{
+		{
+			name: "sorted by wins",
+			winners: [][2]string{
+				{d, a}, {d, b}, {d, c}, {c, a}, {c, b}, {b, a},
+			},
+			standings: []dtos.Standing{
+				{TiktokURL: d, Played: 3, Wins: 3},
+				{TiktokURL: c, Played: 3, Wins: 2, Losses: 1},
+				{TiktokURL: b, Played: 3, Wins: 1, Losses: 2},
+				{TiktokURL: a, Played: 3, Losses: 3},
+			},
+		},
+		{
+			name: "tie of two broken by their match",
+			winners: [][2]string{
+				{b, a}, {c, b}, {c, d}, {b, d},
+			},
+			standings: []dtos.Standing{
+				{TiktokURL: c, Played: 2, Wins: 2},
+				{TiktokURL: b, Played: 3, Wins: 2, Losses: 1},
+				{TiktokURL: a, Played: 1, Losses: 1},
+				{TiktokURL: d, Played: 2, Losses: 2},
+			},
+		},
+		{
+			name: "three-way tie broken by mini-league, then by match of two still tied",
+			winners: [][2]string{
+				{b, a}, {a, c}, {d, b},
+			},
+			standings: []dtos.Standing{
+				{TiktokURL: d, Played: 1, Wins: 1},
+				{TiktokURL: b, Played: 2, Wins: 1, Losses: 1},
+				{TiktokURL: a, Played: 2, Wins: 1, Losses: 1},
+				{TiktokURL: c, Played: 1, Losses: 1},
+			},
+		},
+		{
+			name: "cyclic tie keeps initial order",
+			winners: [][2]string{
+				{a, c}, {b, d}, {c, b}, {d, a},
+			},
+			standings: []dtos.Standing{
+				{TiktokURL: a, Played: 2, Wins: 1, Losses: 1},
+				{TiktokURL: b, Played: 2, Wins: 1, Losses: 1},
+				{TiktokURL: c, Played: 2, Wins: 1, Losses: 1},
+				{TiktokURL: d, Played: 2, Wins: 1, Losses: 1},
+			},
+		},
+		{
+			name:    "no results keep initial order",
+			winners: [][2]string{},
+			standings: []dtos.Standing{
+				{TiktokURL: a}, {TiktokURL: b}, {TiktokURL: c}, {TiktokURL: d},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			contest := RoundRobin(tiktoks, Options{Seed: 1})
+			results := map[string]string{}
+			for _, winner := range test.winners {
+				results[findMatch(t, contest, winner[0], winner[1])] = winner[0]
+			}
+
+			assert.Equal(t, test.standings, RoundRobinStandings(contest, results))
+		})
+	}
+}
+
+// findMatch returns ID of match between two tiktoks
+func findMatch(t *testing.T, contest dtos.Contest, first string, second string) string {
+	for _, round := range contest.Rounds {
+		for _, match := range round.Matches {
+			firstOption, _ := match.FirstOption.(dtos.TiktokOption)
+			secondOption, _ := match.SecondOption.(dtos.TiktokOption)
+			if firstOption.TiktokURL == first && secondOption.TiktokURL == second ||
+				firstOption.TiktokURL == second && secondOption.TiktokURL == first {
+				return match.MatchID
+			}
+		}
+	}
+	t.Fatalf("match between %s and %s doesn't exist", first, second)
+	return ""
+}
@@ -1,7 +1,6 @@
 package contests
 
 import (
-	"github.com/google/uuid"
 	"math"
 	"tiktok-arena/internal/core/dtos"
 	"tiktok-arena/internal/core/models"
@@ -9,88 +8,121 @@ import (
 
 // SingleElimination
 // https://en.wikipedia.org/wiki/Single-elimination_tournament
-func SingleElimination(t []models.Tiktok) dtos.Contest {
-	countTiktok := len(t)
-	countRound := int(math.Ceil(math.Log2(float64(countTiktok))))
-	countSecondRoundParticipators := 1 << (countRound - 1) // Equivalent to int(math.Pow(2, float64(countRound)) / 2)
-	countFirstRoundMatches := countTiktok - int(math.Pow(2, float64(countRound)-1))
-	countFirstRoundTiktoks := countFirstRoundMatches * 2
+// Tiktoks are expected in order of seeds, first tiktok is top seed.
+// Bracket is filled with standard seeding (1 vs N, 2 vs N-1, ...), so top seeds can meet only in late rounds,
+// if count of tiktoks is not power of two, top seeds get byes to second round.
+// If ThirdPlace option is true, losers of semifinals play third place match in last round before final,
+// match is not added if one of semifinalists got bye to final (3 tiktoks).
+// If BestOf option is bigger than 1, every match is won by majority of BestOf votes.
+func SingleElimination(t []models.Tiktok, options Options) dtos.Contest {
+	ids := newMatchIDs(options.Seed)
+	rounds := eliminationRounds(tiktokOptions(t), ids)
+	countMatches := len(t) - 1
+	if options.ThirdPlace && addThirdPlaceMatch(rounds, ids) {
+		countMatches++
+	}
+	setWinsRequired(rounds, options.BestOf)
 
-	rounds := make([]dtos.Round, 0, countRound)
+	return dtos.Contest{
+		Type:         dtos.SingleElimination,
+		Seed:         options.Seed,
+		CountMatches: countMatches,
+		Rounds:       rounds,
+	}
+}
 
-	firstRoundMatches := make([]dtos.Match, 0, countFirstRoundMatches)
-	secondRoundMatches := make([]dtos.Match, 0, countSecondRoundParticipators/2)
+func tiktokOptions(t []models.Tiktok) []dtos.Option {
+	participators := make([]dtos.Option, 0, len(t))
+	for _, tiktok := range t {
+		participators = append(participators, dtos.TiktokOption{TiktokURL: tiktok.URL})
+	}
+	return participators
+}
+
+// eliminationRounds generates rounds of single elimination bracket for participators in order of seeds
+func eliminationRounds(participators []dtos.Option, ids *matchIDs) []dtos.Round {
+	countParticipators := len(participators)
+	countRound := int(math.Ceil(math.Log2(float64(countParticipators))))
+	slots := seedingOrder(1 << countRound)
+
+	rounds := make([]dtos.Round, 0, countRound)
 
-	secondRoundParticipators := make([]dtos.Option, 0, countSecondRoundParticipators) // This slice should store MatchOption or TiktokOption
+	firstRoundMatches := make([]dtos.Match, 0, countParticipators-len(slots)/2)
+	nextRoundParticipators := make([]dtos.Option, 0, len(slots)/2) // This slice should store MatchOption or TiktokOption
 
-	// Filling first round with firstRoundMatches and appending MatchOptions to second round participators
-	for j := 0; j < countFirstRoundTiktoks; j += 2 {
-		matchID := uuid.NewString()
+	// Filling first round with matches of existing seeds, seeds without opponent get bye to second round
+	for i := 0; i < len(slots); i += 2 {
+		first, second := slots[i], slots[i+1]
+		if second > countParticipators {
+			nextRoundParticipators = append(nextRoundParticipators, participators[first-1])
+			continue
+		}
+		if first > countParticipators {
+			nextRoundParticipators = append(nextRoundParticipators, participators[second-1])
+			continue
+		}
+		matchID := ids.next()
 		firstRoundMatches = append(firstRoundMatches, dtos.Match{
-			MatchID: matchID,
-			FirstOption: dtos.TiktokOption{
-				TiktokURL: t[j].URL,
-			},
-			SecondOption: dtos.TiktokOption{
-				TiktokURL: t[j+1].URL,
-			},
+			MatchID:      matchID,
+			FirstOption:  participators[first-1],
+			SecondOption: participators[second-1],
 		})
-		secondRoundParticipators = append(secondRoundParticipators,
-			dtos.MatchOption{MatchID: matchID})
+		nextRoundParticipators = append(nextRoundParticipators, dtos.MatchOption{MatchID: matchID})
 	}
-	// Appending first round firstRoundMatches to rounds
 	rounds = append(rounds, dtos.Round{
 		Round:   1,
 		Matches: firstRoundMatches,
 	})
-	// Appending TiktokOptions to second round participators
-	for _, tiktok := range t[countFirstRoundTiktoks:] {
-		secondRoundParticipators = append(secondRoundParticipators,
-			dtos.TiktokOption{TiktokURL: tiktok.URL})
-	}
-	// Generating second round firstRoundMatches
-	for i := 0; i < int(countSecondRoundParticipators); i += 2 {
-		match := dtos.Match{
-			MatchID:      uuid.NewString(),
-			FirstOption:  secondRoundParticipators[i],
-			SecondOption: secondRoundParticipators[i+1],
-		}
-		secondRoundMatches = append(secondRoundMatches, match)
-	}
-	// Generating second round
-	secondRound := dtos.Round{
-		Round:   2,
-		Matches: secondRoundMatches}
-	rounds = append(rounds, secondRound)
 
-	previousRoundMatches := secondRoundMatches
-	for roundID := 3; roundID <= countRound; roundID++ {
-		// Generating Nth round matches (where N > 2)
-		var currentRoundMatches []dtos.Match
-		for matchID := 0; matchID < len(previousRoundMatches); matchID += 2 {
-			match := dtos.Match{
-				MatchID: uuid.NewString(),
-				FirstOption: dtos.MatchOption{
-					MatchID: previousRoundMatches[matchID].MatchID,
-				},
-				SecondOption: dtos.MatchOption{
-					MatchID: previousRoundMatches[matchID+1].MatchID,
-				},
-			}
-			currentRoundMatches = append(currentRoundMatches, match)
+	for roundID := 2; roundID <= countRound; roundID++ {
+		// Generating Nth round matches (where N > 1)
+		currentRoundMatches := make([]dtos.Match, 0, len(nextRoundParticipators)/2)
+		currentRoundParticipators := nextRoundParticipators
+		nextRoundParticipators = make([]dtos.Option, 0, len(currentRoundParticipators)/2)
+		for i := 0; i < len(currentRoundParticipators); i += 2 {
+			matchID := ids.next()
+			currentRoundMatches = append(currentRoundMatches, dtos.Match{
+				MatchID:      matchID,
+				FirstOption:  currentRoundParticipators[i],
+				SecondOption: currentRoundParticipators[i+1],
+			})
+			nextRoundParticipators = append(nextRoundParticipators, dtos.MatchOption{MatchID: matchID})
 		}
-		// Generating Nth round (where N > 2)
-		round := dtos.Round{
+		// Generating Nth round (where N > 1)
+		rounds = append(rounds, dtos.Round{
 			Round:   roundID,
 			Matches: currentRoundMatches,
-		}
-		rounds = append(rounds, round)
-
-		previousRoundMatches = currentRoundMatches
+		})
 	}
-	return dtos.Contest{
-		CountMatches: countTiktok - 1,
-		Rounds:       rounds,
+	return rounds
+}
+
+// addThirdPlaceMatch adds match between losers of semifinals to last round before final,
+// returns false if one of semifinalists got bye to final
+func addThirdPlaceMatch(rounds []dtos.Round, ids *matchIDs) bool {
+	if len(rounds) < 2 || len(rounds[len(rounds)-2].Matches) != 2 {
+		return false
 	}
+	final := &rounds[len(rounds)-1]
+	semifinals := rounds[len(rounds)-2].Matches
+	final.Matches = append([]dtos.Match{{
+		MatchID:      ids.next(),
+		FirstOption:  dtos.LoserOption{MatchID: semifinals[0].MatchID},
+		SecondOption: dtos.LoserOption{MatchID: semifinals[1].MatchID},
+	}}, final.Matches...)
+	return true
+}
 
+// seedingOrder returns seeds (starting from 1) in order of bracket slots,
+// every next pair of slots is first round match: 1 vs N, N/2 vs N/2+1, ...
+func seedingOrder(countSlots int) []int {
+	order := []int{1}
+	for len(order) < countSlots {
+		next := make([]int, 0, len(order)*2)
+		for _, seed := range order {
+			next = append(next, seed, len(order)*2+1-seed)
+		}
+		order = next
+	}
+	return order
 }
@@ -20,7 +20,7 @@ func BenchmarkSingleElimination(b *testing.B) {
 	}
 
 	for n := 0; n < b.N; n++ {
-		result := SingleElimination(tiktoks)
+		result := SingleElimination(tiktoks, Options{})
 		_ = result
 	}
 }
@@ -0,0 +1,245 @@
+package contests
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+)
+
+// Swiss
+// https://en.wikipedia.org/wiki/Swiss-system_tournament
+// Only first round is generated, next rounds are generated by SwissNextRound from results of previous rounds.
+// Contest lasts ceil(log2(N)) rounds, with odd count of participators one of them gets bye (free win) in each round.
+// Tiktoks are expected in order of seeds, in first round top half of seeds plays against bottom half (1 vs N/2+1, ...),
+// with odd count of tiktoks lowest seed gets bye.
+// Match IDs of every round are generated from seed of contest and number of round,
+// so same round is paired with same IDs regardless of when it is requested.
+func Swiss(t []models.Tiktok, options Options) dtos.Contest {
+	countTiktok := len(t)
+	countRound := int(math.Ceil(math.Log2(float64(countTiktok))))
+
+	half := countTiktok / 2
+	participators := make([]string, 0, half*2)
+	for i := 0; i < half; i++ {
+		participators = append(participators, t[i].URL, t[half+i].URL)
+	}
+	standings := make([]dtos.Standing, 0, countTiktok)
+	for _, tiktok := range t {
+		standings = append(standings, dtos.Standing{TiktokURL: tiktok.URL})
+	}
+
+	return dtos.Contest{
+		Type:         dtos.Swiss,
+		Seed:         options.Seed,
+		CountMatches: countRound * (countTiktok / 2),
+		CountRounds:  countRound,
+		Rounds:       []dtos.Round{swissRound(1, participators, options)},
+		Standings:    standings,
+	}
+}
+
+// SwissNextRound
+// Validates results of already played rounds and pairs next round from current standings.
+// Pairing of every played round is generated again from results of previous rounds,
+// results which don't follow it (other pairs or other tiktok with bye) are rejected.
+// Participators with equal wins are paired with each other when possible, repeated matches are avoided.
+// If all rounds are played contest without rounds and with final standings is returned.
+func SwissNextRound(t []models.Tiktok, results []dtos.MatchResult, options Options) (dtos.Contest, error) {
+	contest := Swiss(t, options)
+
+	positions := make(map[string]int, len(t))
+	for i, tiktok := range t {
+		positions[tiktok.URL] = i
+	}
+
+	wins := make([]int, len(t))
+	played := make([]int, len(t))
+	opponents := make([][]int, len(t))
+	byes := make([]bool, len(t))
+
+	countPlayedRounds := 0
+	for _, result := range results {
+		if result.Round > countPlayedRounds {
+			countPlayedRounds = result.Round
+		}
+	}
+	if countPlayedRounds > contest.CountRounds {
+		return contest, fmt.Errorf("contest has only %d rounds, got results of round %d",
+			contest.CountRounds, countPlayedRounds)
+	}
+
+	for round := 1; round <= countPlayedRounds; round++ {
+		paired := contest.Rounds[0]
+		if round > 1 {
+			paired = swissRound(round, swissParticipators(t, swissOrder(wins, opponents), opponents, byes), options)
+		}
+		pairs := make(map[[2]int]bool, len(paired.Matches))
+		for _, match := range paired.Matches {
+			first := positions[match.FirstOption.(dtos.TiktokOption).TiktokURL]
+			second := positions[match.SecondOption.(dtos.TiktokOption).TiktokURL]
+			pairs[orderedPair(first, second)] = true
+		}
+
+		inRound := make([]bool, len(t))
+		countMatches := 0
+		for _, result := range results {
+			if result.Round != round {
+				continue
+			}
+			first, firstOk := positions[result.FirstTiktokURL]
+			second, secondOk := positions[result.SecondTiktokURL]
+			if !firstOk || !secondOk || first == second {
+				return contest, fmt.Errorf("round %d has match with unknown tiktoks", round)
+			}
+			if !pairs[orderedPair(first, second)] {
+				return contest, fmt.Errorf("round %d has match of %s and %s which are not paired",
+					round, result.FirstTiktokURL, result.SecondTiktokURL)
+			}
+			if inRound[first] || inRound[second] {
+				return contest, fmt.Errorf("round %d has tiktok playing more than once", round)
+			}
+			inRound[first], inRound[second] = true, true
+			countMatches++
+
+			winner := first
+			if result.WinnerURL == result.SecondTiktokURL {
+				winner = second
+			} else if result.WinnerURL != result.FirstTiktokURL {
+				return contest, fmt.Errorf("winner %s didn't play in match", result.WinnerURL)
+			}
+			wins[winner]++
+			played[first]++
+			played[second]++
+			opponents[first] = append(opponents[first], second)
+			opponents[second] = append(opponents[second], first)
+		}
+		if countMatches != len(t)/2 {
+			return contest, fmt.Errorf("round %d has %d matches instead of %d", round, countMatches, len(t)/2)
+		}
+		// Tiktok without match in round got bye, bye is played and won
+		for i := range t {
+			if !inRound[i] {
+				wins[i]++
+				played[i]++
+				byes[i] = true
+			}
+		}
+	}
+
+	order := swissOrder(wins, opponents)
+	contest.Standings = contest.Standings[:0]
+	for _, i := range order {
+		contest.Standings = append(contest.Standings, dtos.Standing{
+			TiktokURL: t[i].URL,
+			Played:    played[i],
+			Wins:      wins[i],
+			Losses:    played[i] - wins[i],
+		})
+	}
+
+	if countPlayedRounds == contest.CountRounds {
+		contest.Rounds = []dtos.Round{}
+		return contest, nil
+	}
+
+	participators := swissParticipators(t, order, opponents, byes)
+	contest.Rounds = []dtos.Round{swissRound(countPlayedRounds+1, participators, options)}
+
+	return contest, nil
+}
+
+// swissOrder orders participators by wins, then by sum of opponents wins (Buchholz score)
+func swissOrder(wins []int, opponents [][]int) []int {
+	order := make([]int, len(wins))
+	buchholz := make([]int, len(wins))
+	for i := range wins {
+		order[i] = i
+		for _, opponent := range opponents[i] {
+			buchholz[i] += wins[opponent]
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if wins[order[i]] != wins[order[j]] {
+			return wins[order[i]] > wins[order[j]]
+		}
+		return buchholz[order[i]] > buchholz[order[j]]
+	})
+	return order
+}
+
+// swissParticipators returns URLs of participators of next round one pair after another,
+// lowest ranked participator without previous bye gets bye
+func swissParticipators(t []models.Tiktok, order []int, opponents [][]int, byes []bool) []string {
+	order = append([]int{}, order...)
+	if len(order)%2 != 0 {
+		bye := len(order) - 1
+		for j := len(order) - 1; j >= 0; j-- {
+			if !byes[order[j]] {
+				bye = j
+				break
+			}
+		}
+		order = append(order[:bye], order[bye+1:]...)
+	}
+
+	pairs, ok := swissPairs(order, opponents, false)
+	if !ok {
+		pairs, _ = swissPairs(order, opponents, true)
+	}
+	participators := make([]string, 0, len(pairs))
+	for _, i := range pairs {
+		participators = append(participators, t[i].URL)
+	}
+	return participators
+}
+
+// swissPairs pairs every participator with highest ranked participator it hasn't played with,
+// backtracking when rest of participators can't be paired.
+// Returned slice contains pairs one after another.
+func swissPairs(order []int, opponents [][]int, allowRematch bool) ([]int, bool) {
+	if len(order) == 0 {
+		return []int{}, true
+	}
+	first := order[0]
+	for j := 1; j < len(order); j++ {
+		second := order[j]
+		if !allowRematch && containsInt(opponents[first], second) {
+			continue
+		}
+		rest := make([]int, 0, len(order)-2)
+		rest = append(rest, order[1:j]...)
+		rest = append(rest, order[j+1:]...)
+		if pairs, ok := swissPairs(rest, opponents, allowRematch); ok {
+			return append([]int{first, second}, pairs...), true
+		}
+	}
+	return nil, false
+}
+
+// swissRound pairs participators one by one
+func swissRound(roundID int, participators []string, options Options) dtos.Round {
+	ids := newMatchIDs(options.Seed + int64(roundID))
+	matches := make([]dtos.Match, 0, len(participators)/2)
+	for i := 0; i+1 < len(participators); i += 2 {
+		matches = append(matches, dtos.Match{
+			MatchID:      ids.next(),
+			FirstOption:  dtos.TiktokOption{TiktokURL: participators[i]},
+			SecondOption: dtos.TiktokOption{TiktokURL: participators[i+1]},
+		})
+	}
+	return dtos.Round{
+		Round:   roundID,
+		Matches: matches,
+	}
+}
+
+func containsInt(slice []int, value int) bool {
+	for _, item := range slice {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package contests
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tiktok-arena/internal/core/dtos"
+)
+
+func TestSwissFirstRound(t *testing.T) {
+	tests := []struct {
+		name         string
+		countTiktoks int
+		countRounds  int
+		pairs        [][2]int // positions of tiktoks in matches of first round
+	}{
+		{name: "two tiktoks", countTiktoks: 2, countRounds: 1, pairs: [][2]int{{0, 1}}},
+		{name: "odd count, lowest seed gets bye", countTiktoks: 5, countRounds: 3, pairs: [][2]int{{0, 2}, {1, 3}}},
+		{name: "top half plays bottom half", countTiktoks: 8, countRounds: 3,
+			pairs: [][2]int{{0, 4}, {1, 5}, {2, 6}, {3, 7}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tiktoks := testTiktoks(test.countTiktoks)
+			contest := Swiss(tiktoks, Options{Seed: 1})
+
+			assert.Equal(t, dtos.Swiss, contest.Type)
+			assert.Equal(t, test.countRounds, contest.CountRounds)
+			assert.Equal(t, test.countRounds*(test.countTiktoks/2), contest.CountMatches)
+			assert.Len(t, contest.Standings, test.countTiktoks)
+			assert.Len(t, contest.Rounds, 1)
+			assert.Len(t, contest.Rounds[0].Matches, len(test.pairs))
+			for i, pair := range test.pairs {
+				match := contest.Rounds[0].Matches[i]
+				assert.Equal(t, dtos.TiktokOption{TiktokURL: tiktoks[pair[0]].URL}, match.FirstOption)
+				assert.Equal(t, dtos.TiktokOption{TiktokURL: tiktoks[pair[1]].URL}, match.SecondOption)
+			}
+		})
+	}
+}
+
+func TestSwissNextRoundStandings(t *testing.T) {
+	tiktoks := testTiktoks(3)
+	a, b, c := tiktoks[0].URL, tiktoks[1].URL, tiktoks[2].URL
+
+	tests := []struct {
+		name      string
+		results   []dtos.MatchResult
+		standings []dtos.Standing
+		finished  bool
+	}{
+		{
+			name: "bye is counted as played win",
+			results: []dtos.MatchResult{
+				{Round: 1, FirstTiktokURL: a, SecondTiktokURL: b, WinnerURL: a},
+			},
+			standings: []dtos.Standing{
+				{TiktokURL: a, Played: 1, Wins: 1},
+				{TiktokURL: c, Played: 1, Wins: 1},
+				{TiktokURL: b, Played: 1, Losses: 1},
+			},
+		},
+		{
+			name: "bye goes to tiktok without previous bye",
+			results: []dtos.MatchResult{
+				{Round: 1, FirstTiktokURL: a, SecondTiktokURL: b, WinnerURL: b},
+				{Round: 2, FirstTiktokURL: b, SecondTiktokURL: c, WinnerURL: c},
+			},
+			standings: []dtos.Standing{
+				{TiktokURL: c, Played: 2, Wins: 2},
+				{TiktokURL: b, Played: 2, Wins: 1, Losses: 1},
+				{TiktokURL: a, Played: 2, Wins: 1, Losses: 1},
+			},
+			finished: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			contest, err := SwissNextRound(tiktoks, test.results, Options{Seed: 1})
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.standings, contest.Standings)
+			if test.finished {
+				assert.Empty(t, contest.Rounds)
+			} else {
+				assert.Len(t, contest.Rounds, 1)
+			}
+		})
+	}
+}
+
+func TestSwissNextRoundAvoidsRematches(t *testing.T) {
+	tiktoks := testTiktoks(4)
+	contest := Swiss(tiktoks, Options{Seed: 1})
+	results := firstOptionWins(contest.Rounds[0])
+
+	next, err := SwissNextRound(tiktoks, results, Options{Seed: 1})
+
+	assert.Nil(t, err)
+	assert.Len(t, next.Rounds, 1)
+	// Winners play each other, losers play each other
+	matches := next.Rounds[0].Matches
+	assert.Equal(t, dtos.TiktokOption{TiktokURL: tiktoks[0].URL}, matches[0].FirstOption)
+	assert.Equal(t, dtos.TiktokOption{TiktokURL: tiktoks[1].URL}, matches[0].SecondOption)
+	assert.Equal(t, dtos.TiktokOption{TiktokURL: tiktoks[2].URL}, matches[1].FirstOption)
+	assert.Equal(t, dtos.TiktokOption{TiktokURL: tiktoks[3].URL}, matches[1].SecondOption)
+}
+
+func TestSwissNextRoundRejectsInvalidResults(t *testing.T) {
+	tiktoks := testTiktoks(4)
+	a, b, c, d := tiktoks[0].URL, tiktoks[1].URL, tiktoks[2].URL, tiktoks[3].URL
+
+	tests := []struct {
+		name    string
+		results []dtos.MatchResult
+	}{
+		{name: "unknown tiktok", results: []dtos.MatchResult{
+			{Round: 1, FirstTiktokURL: a, SecondTiktokURL: "unknown", WinnerURL: a},
+		}},
+		{name: "tiktok plays twice in round", results: []dtos.MatchResult{
+			{Round: 1, FirstTiktokURL: a, SecondTiktokURL: b, WinnerURL: a},
+			{Round: 1, FirstTiktokURL: a, SecondTiktokURL: c, WinnerURL: a},
+		}},
+		{name: "missing match", results: []dtos.MatchResult{
+			{Round: 1, FirstTiktokURL: a, SecondTiktokURL: b, WinnerURL: a},
+		}},
+		{name: "round after last round", results: []dtos.MatchResult{
+			{Round: 3, FirstTiktokURL: a, SecondTiktokURL: b, WinnerURL: a},
+		}},
+		{name: "pairs of first round are not generated ones", results: []dtos.MatchResult{
+			{Round: 1, FirstTiktokURL: a, SecondTiktokURL: b, WinnerURL: a},
+			{Round: 1, FirstTiktokURL: c, SecondTiktokURL: d, WinnerURL: c},
+		}},
+		{name: "pairs of next round are not generated from previous results", results: []dtos.MatchResult{
+			{Round: 1, FirstTiktokURL: a, SecondTiktokURL: c, WinnerURL: a},
+			{Round: 1, FirstTiktokURL: b, SecondTiktokURL: d, WinnerURL: b},
+			{Round: 2, FirstTiktokURL: a, SecondTiktokURL: d, WinnerURL: a},
+			{Round: 2, FirstTiktokURL: b, SecondTiktokURL: c, WinnerURL: b},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := SwissNextRound(tiktoks, test.results, Options{})
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestSwissNextRoundRejectsMadeUpBye(t *testing.T) {
+	tiktoks := testTiktoks(3)
+	a, b, c := tiktoks[0].URL, tiktoks[1].URL, tiktoks[2].URL
+
+	// Lowest seed c gets bye in first round, a can't get it instead
+	_, err := SwissNextRound(tiktoks, []dtos.MatchResult{
+		{Round: 1, FirstTiktokURL: b, SecondTiktokURL: c, WinnerURL: b},
+	}, Options{})
+
+	assert.NotNil(t, err)
+	_, err = SwissNextRound(tiktoks, []dtos.MatchResult{
+		{Round: 1, FirstTiktokURL: b, SecondTiktokURL: a, WinnerURL: b},
+	}, Options{})
+	assert.Nil(t, err)
+}
+
+func TestSwissNextRoundAcceptsGeneratedRounds(t *testing.T) {
+	tiktoks := testTiktoks(7)
+	options := Options{Seed: 3}
+	contest := Swiss(tiktoks, options)
+	var results []dtos.MatchResult
+
+	for round := 1; round <= contest.CountRounds; round++ {
+		results = append(results, firstOptionWins(contest.Rounds[0])...)
+		next, err := SwissNextRound(tiktoks, results, options)
+		assert.Nil(t, err, "round %d", round)
+		contest = next
+	}
+
+	assert.Empty(t, contest.Rounds)
+}
+
+// firstOptionWins returns results of round where first option of every match wins
+func firstOptionWins(round dtos.Round) []dtos.MatchResult {
+	results := make([]dtos.MatchResult, 0, len(round.Matches))
+	for _, match := range round.Matches {
+		first := match.FirstOption.(dtos.TiktokOption).TiktokURL
+		results = append(results, dtos.MatchResult{
+			Round:           round.Round,
+			MatchID:         match.MatchID,
+			FirstTiktokURL:  first,
+			SecondTiktokURL: match.SecondOption.(dtos.TiktokOption).TiktokURL,
+			WinnerURL:       first,
+		})
+	}
+	return results
+}
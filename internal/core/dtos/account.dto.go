@@ -0,0 +1,81 @@
+package dtos
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	TournamentsDelete    = "delete"    // tournaments are deleted with their tiktoks, play sessions and statistics
+	TournamentsAnonymise = "anonymise" // tournaments are moved to deleted user and stay playable
+)
+
+type DeleteAccount struct {
+	Password    string `json:"password"`                         // required if user has password
+	Code        string `validate:"omitempty,max=32" json:"code"` // TOTP or recovery code, required if user has 2FA
+	Tournaments string `validate:"required,oneof=delete anonymise" json:"tournaments"`
+}
+
+const (
+	ExportJSON = "json"
+	ExportZIP  = "zip"
+)
+
+type ExportQueries struct {
+	Format string `validate:"omitempty,oneof=json zip" query:"format" json:"format"` // json by default
+}
+
+// AccountExport
+// All personal data of user: profile, tournaments with tiktoks, play history and linked accounts.
+type AccountExport struct {
+	ExportedAt   time.Time             `json:"exportedAt"`
+	Profile      ExportedProfile       `json:"profile"`
+	Tournaments  []ExportedTournament  `json:"tournaments"`
+	PlaySessions []ExportedPlaySession `json:"playSessions"`
+	TierLists    []TierListView        `json:"tierLists"`
+	Identities   []ExportedIdentity    `json:"identities"`
+}
+
+type ExportedProfile struct {
+	ID               uuid.UUID `json:"id"`
+	Name             string    `json:"name"`
+	Email            string    `json:"email"`
+	PhotoURL         string    `json:"photoURL"`
+	Role             string    `json:"role"`
+	TwoFactorEnabled bool      `json:"twoFactorEnabled"`
+}
+
+type ExportedTournament struct {
+	ID           uuid.UUID        `json:"id"`
+	Name         string           `json:"name"`
+	Size         int              `json:"size"`
+	TimesPlayed  int              `json:"timesPlayed"`
+	IsPrivate    bool             `json:"isPrivate"`
+	PhotoURL     string           `json:"photoURL"`
+	RatingSystem string           `json:"ratingSystem"`
+	BestOf       int              `json:"bestOf"`
+	Tiers        []string         `json:"tiers"`
+	Tiktoks      []ExportedTiktok `json:"tiktoks"`
+}
+
+type ExportedTiktok struct {
+	Name         string  `json:"name"`
+	URL          string  `json:"url"`
+	Wins         int     `json:"wins"`
+	Appearances  int     `json:"appearances"`
+	SecondPlaces int     `json:"secondPlaces"`
+	ThirdPlaces  int     `json:"thirdPlaces"`
+	Rating       float64 `json:"rating"`
+}
+
+type ExportedPlaySession struct {
+	PlaySessionView
+	Decisions []MatchDecisionView `json:"decisions"`
+}
+
+type ExportedIdentity struct {
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+}
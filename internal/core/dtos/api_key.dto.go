@@ -0,0 +1,13 @@
+package dtos
+
+type CreateAPIKey struct {
+	Name  string `validate:"required,max=64" json:"name"`
+	Scope string `validate:"required,oneof=read tournament:write" json:"scope"`
+	// key doesn't expire if not set
+	ExpiresInDays int `validate:"omitempty,min=1,max=365" json:"expiresInDays"`
+}
+
+type CreatedAPIKey struct {
+	APIKeyView
+	Key string `json:"key"` // shown only once, send it in X-API-Key header
+}
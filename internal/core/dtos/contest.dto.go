@@ -1,28 +1,101 @@
 package dtos
 
+import (
+	"encoding/json"
+	"errors"
+)
+
 const (
 	SingleElimination = "single_elimination"
 	KingOfTheHill     = "king_of_the_hill"
+	DoubleElimination = "double_elimination"
+	RoundRobin        = "round_robin"
+	Swiss             = "swiss"
+	GroupsKnockout    = "groups_knockout"
+	FullRanking       = "full_ranking"
+)
+
+// Brackets of double elimination contest
+const (
+	WinnersBracket = "winners"
+	LosersBracket  = "losers"
+	GrandFinal     = "grand_final"
+)
+
+// Stages of groups knockout contest
+const (
+	GroupStage    = "group_stage"
+	KnockoutStage = "knockout"
+)
+
+// Podium
+// Tiktoks on first three places of finished contest, third place is empty if contest doesn't decide it.
+type Podium struct {
+	First  string `json:"first"`
+	Second string `json:"second"`
+	Third  string `json:"third,omitempty"`
+}
+
+// Seeding of tiktoks before contest generation
+const (
+	RandomSeeding  = "random"
+	WinsSeeding    = "wins"    // by count of tournament wins
+	WinrateSeeding = "winrate" // by tournament wins per tournament play with tiktok
 )
 
+// MaxSeed is the largest seed of contest, seeds are kept in 53 bits to be exact in JSON numbers of JavaScript clients
+const MaxSeed = 1<<53 - 1
+
 func GetAllowedContestType() map[string]bool {
 	return map[string]bool{
 		SingleElimination: true,
 		KingOfTheHill:     true,
+		DoubleElimination: true,
+		RoundRobin:        true,
+		Swiss:             true,
+		GroupsKnockout:    true,
+		FullRanking:       true,
+	}
+}
+
+// GetServerPairedContestType returns contests where only first round is generated up front,
+// next rounds are paired by server from results of previous rounds
+func GetServerPairedContestType() map[string]bool {
+	return map[string]bool{
+		Swiss:       true,
+		FullRanking: true,
 	}
 }
 
+func CheckIfServerPairedContestType(contestType string) bool {
+	return GetServerPairedContestType()[contestType]
+}
+
 func CheckIfAllowedContestType(contestType string) bool {
 	return GetAllowedContestType()[contestType]
 }
 
 type Contest struct {
-	CountMatches int     `json:"countMatches"`
-	Rounds       []Round `json:"rounds"`
+	Type         string     `json:"type"`
+	Seed         int64      `json:"seed"` // contest generated with same seed from same tiktoks is the same
+	CountMatches int        `json:"countMatches"`
+	CountRounds  int        `json:"countRounds,omitempty"` // only for contests paired by server, where not all rounds are returned
+	Rounds       []Round    `json:"rounds"`
+	Standings    []Standing `json:"standings,omitempty"` // only for contests with standings table (round robin, swiss, groups, full ranking)
+}
+
+type Standing struct {
+	TiktokURL string `json:"tiktokURL"`
+	Group     int    `json:"group,omitempty"` // only for groups knockout, starting from 1
+	Played    int    `json:"played"`
+	Wins      int    `json:"wins"`
+	Losses    int    `json:"losses"`
 }
 
 type Round struct {
 	Round   int     `json:"round"`
+	Bracket string  `json:"bracket,omitempty"`
+	Group   int     `json:"group,omitempty"` // only for group stage, rounds of all groups share round number
 	Matches []Match `json:"matches"`
 }
 
@@ -30,12 +103,63 @@ type Match struct {
 	MatchID      string `json:"matchID"`
 	FirstOption  Option `json:"firstOption"`
 	SecondOption Option `json:"secondOption"`
+	IfNecessary  bool   `json:"ifNecessary,omitempty"`  // played only if second option wins previous match (bracket reset)
+	WinsRequired int    `json:"winsRequired,omitempty"` // votes to win best-of-N match, 1 if not set
+}
+
+// UnmarshalJSON restores concrete types of match options
+func (m *Match) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		MatchID      string          `json:"matchID"`
+		FirstOption  json.RawMessage `json:"firstOption"`
+		SecondOption json.RawMessage `json:"secondOption"`
+		IfNecessary  bool            `json:"ifNecessary"`
+		WinsRequired int             `json:"winsRequired"`
+	}
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+	m.MatchID = raw.MatchID
+	m.IfNecessary = raw.IfNecessary
+	m.WinsRequired = raw.WinsRequired
+	m.FirstOption, err = unmarshalOption(raw.FirstOption)
+	if err != nil {
+		return err
+	}
+	m.SecondOption, err = unmarshalOption(raw.SecondOption)
+	return err
 }
 
 type Option interface {
 	isOption() bool
 }
 
+func unmarshalOption(data json.RawMessage) (Option, error) {
+	var raw struct {
+		MatchID        *string `json:"matchID"`
+		LoserOfMatchID *string `json:"loserOfMatchID"`
+		TiktokURL      *string `json:"tiktokURL"`
+		Group          *int    `json:"group"`
+		Position       *int    `json:"position"`
+	}
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case raw.MatchID != nil:
+		return MatchOption{MatchID: *raw.MatchID}, nil
+	case raw.LoserOfMatchID != nil:
+		return LoserOption{MatchID: *raw.LoserOfMatchID}, nil
+	case raw.TiktokURL != nil:
+		return TiktokOption{TiktokURL: *raw.TiktokURL}, nil
+	case raw.Group != nil && raw.Position != nil:
+		return GroupPositionOption{Group: *raw.Group, Position: *raw.Position}, nil
+	}
+	return nil, errors.New("unknown match option")
+}
+
 type MatchOption struct {
 	MatchID string `json:"matchID"`
 }
@@ -44,6 +168,15 @@ func (m MatchOption) isOption() bool {
 	return true
 }
 
+// LoserOption references loser of match, used to fill losers bracket
+type LoserOption struct {
+	MatchID string `json:"loserOfMatchID"`
+}
+
+func (m LoserOption) isOption() bool {
+	return true
+}
+
 type TiktokOption struct {
 	TiktokURL string `json:"tiktokURL"`
 }
@@ -52,6 +185,38 @@ func (m TiktokOption) isOption() bool {
 	return true
 }
 
+// GroupPositionOption references tiktok on position of group standings, used to fill knockout stage
+type GroupPositionOption struct {
+	Group    int `json:"group"`    // starting from 1
+	Position int `json:"position"` // starting from 1
+}
+
+func (m GroupPositionOption) isOption() bool {
+	return true
+}
+
 type ContestPayload struct {
+	Type         string `validate:"required" query:"type" json:"type"`
+	BracketReset bool   `query:"bracketReset" json:"bracketReset"`                        // only for double elimination
+	Advance      int    `validate:"omitempty,min=1,max=3" query:"advance" json:"advance"` // only for groups knockout, 2 if not set
+	ThirdPlace   bool   `query:"thirdPlace" json:"thirdPlace"`                            // only for single elimination
+	Seeding      string `validate:"omitempty,oneof=random wins winrate" query:"seeding" json:"seeding"`
+	Seed         *int64 `validate:"omitempty,min=0,max=9007199254740991" query:"seed" json:"seed"` // seed of shuffle and match IDs, random if not set
+	Size         int    `validate:"omitempty,gte=2,lte=512" query:"size" json:"size"`              // tiktoks drawn for play, size of tournament if not set
+}
+
+type ContestResults struct {
 	Type string `validate:"required" json:"type"`
+	Seed int64  `validate:"min=0,max=9007199254740991" json:"seed"` // seed of contest, next round is paired with match IDs derived from it
+	// URLs of tiktoks drawn for contest in order of its initial standings, all tournament tiktoks if not set
+	Participators []string      `validate:"omitempty,min=2,max=512,unique" json:"participators"`
+	Results       []MatchResult `validate:"dive" json:"results"`
+}
+
+type MatchResult struct {
+	Round           int    `validate:"gte=1" json:"round"`
+	MatchID         string `json:"matchID"`
+	FirstTiktokURL  string `validate:"required" json:"firstTiktokURL"`
+	SecondTiktokURL string `validate:"required" json:"secondTiktokURL"`
+	WinnerURL       string `validate:"required" json:"winnerURL"`
 }
@@ -0,0 +1,20 @@
+package dtos
+
+// JWK
+// https://www.rfc-editor.org/rfc/rfc7517
+// Public key to verify JWT, RSA keys have N and E, Ed25519 keys have Crv and X, EC keys have Crv, X and Y.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
@@ -0,0 +1,27 @@
+package dtos
+
+// ExternalIdentity
+// User authenticated by external OpenID Connect provider, taken from claims of verified ID token.
+type ExternalIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string // preferred username or name of user
+}
+
+type OIDCProviders struct {
+	Providers []string `json:"providers"`
+}
+
+type OIDCLogin struct {
+	AuthorizationURL string `json:"authorizationURL"` // user is redirected to provider with this URL
+	State            string `json:"-"`                // bound to browser which started login with cookie
+}
+
+type OIDCCallback struct {
+	Code             string `validate:"required" query:"code" json:"code"`
+	State            string `validate:"required" query:"state" json:"state"`
+	Error            string `query:"error" json:"error"` // set by provider if user denied login
+	ErrorDescription string `query:"error_description" json:"error_description"`
+	BrowserState     string `query:"-" json:"-"` // state from cookie of browser, must be equal to state
+}
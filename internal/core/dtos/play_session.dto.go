@@ -0,0 +1,25 @@
+package dtos
+
+import "github.com/google/uuid"
+
+type PlaySessionDetails struct {
+	ID           uuid.UUID       `json:"id"`
+	TournamentID uuid.UUID       `json:"tournamentID"`
+	Contest      Contest         `json:"contest"`
+	Decisions    []MatchDecision `json:"decisions"`
+	Votes        []MatchVote     `json:"votes"`
+	IsCompleted  bool            `json:"isCompleted"`
+	WinnerURL    string          `json:"winnerURL"`
+	SecondURL    string          `json:"secondURL,omitempty"`
+	ThirdURL     string          `json:"thirdURL,omitempty"` // only for contests deciding third place
+}
+
+type MatchDecision struct {
+	MatchID   string `validate:"required" json:"matchID"`
+	WinnerURL string `validate:"required" json:"winnerURL"`
+}
+
+type MatchVote struct {
+	MatchID   string `json:"matchID"`
+	TiktokURL string `json:"tiktokURL"`
+}
@@ -0,0 +1,23 @@
+package dtos
+
+type TierListSubmission struct {
+	Placements []TierPlacement `validate:"required,dive" json:"placements"`
+}
+
+type TierPlacement struct {
+	TiktokURL string `validate:"required" json:"tiktokURL"`
+	Tier      string `validate:"required" json:"tier"`
+}
+
+type TierListStats struct {
+	Tiers         []string      `json:"tiers"` // ordered from best to worst
+	CountTierList int           `json:"countTierList"`
+	TiktoksTiers  []TiktokTiers `json:"tiktoksTiers"`
+}
+
+type TiktokTiers struct {
+	Name          string         `json:"name"`
+	URL           string         `json:"url"`
+	Distribution  map[string]int `json:"distribution"`  // tier -> count of placements
+	ConsensusTier string         `json:"consensusTier"` // median tier, empty if tiktok wasn't placed
+}
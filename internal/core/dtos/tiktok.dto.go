@@ -9,4 +9,25 @@ type TiktokStats struct {
 	Name string `gorm:"not null;default:null" json:"name"`
 	URL  string `gorm:"not null;primaryKey;default:null" json:"url"`
 	Wins int    `json:"wins"`
+	// Count of tournament plays with tiktok
+	Appearances int `json:"appearances"`
+	// Podium places of finished play sessions, first places are counted in wins
+	SecondPlaces int `json:"secondPlaces"`
+	ThirdPlaces  int `json:"thirdPlaces"`
+	// Average place in complete rankings of full ranking play sessions, 0 if tiktok wasn't ranked
+	AverageRank float64 `json:"averageRank"`
+	Rankings    int     `json:"rankings"`
+	// Statistics of single matches of play sessions
+	Matches   int `json:"matches"`
+	MatchWins int `json:"matchWins"`
+	// Rating after recorded matches, deviation is used only by Glicko-2
+	Rating          float64 `json:"rating"`
+	RatingDeviation float64 `json:"ratingDeviation"`
+}
+
+type Matchup struct {
+	FirstTiktokURL  string `json:"firstTiktokURL"`
+	SecondTiktokURL string `json:"secondTiktokURL"`
+	FirstWins       int    `json:"firstWins"`
+	SecondWins      int    `json:"secondWins"`
 }
@@ -2,22 +2,24 @@ package dtos
 
 import (
 	"github.com/google/uuid"
-	"tiktok-arena/internal/core/models"
 )
 
 type TournamentsResponse struct {
-	TournamentCount int64               `validate:"required" json:"tournamentCount"`
-	Tournaments     []models.Tournament `validate:"required" json:"tournaments"`
+	TournamentCount int64            `validate:"required" json:"tournamentCount"`
+	Tournaments     []TournamentView `validate:"required" json:"tournaments"`
 }
 
 type TournamentsResponseWithUser struct {
 	TournamentCount int64                   `validate:"required" json:"tournamentCount"`
 	Tournaments     []TournamentWithoutUser `validate:"required" json:"tournaments"`
-	User            models.User             `validate:"required" json:"user"`
+	User            PublicUser              `validate:"required" json:"user"`
 }
 
 type TournamentWinner struct {
 	TiktokURL string `validate:"required" json:"tiktokURL"`
+	SessionID string `validate:"omitempty,uuid" json:"sessionID"` // optional, winner must match completed play session
+	// URLs of tiktoks drawn for play, used to count appearances without play session, all tiktoks if not set
+	Participators []string `validate:"omitempty,min=2,max=512,unique" json:"participators"`
 }
 
 type TournamentIds struct {
@@ -27,17 +29,29 @@ type TournamentIds struct {
 type CreateTournament struct {
 	Name      string         `validate:"required" json:"name"`
 	PhotoURL  string         `validate:"required" json:"photoURL"`
-	Size      int            `validate:"gte=4,lte=64" json:"size"`
-	Tiktoks   []CreateTiktok `validate:"required" json:"tiktoks"`
-	IsPrivate bool           `json:"isPrivate"` // by default public, so we don't need this field to be required
+	Size      int            `validate:"omitempty,gte=2,lte=512" json:"size"`   // tiktoks drawn for every play, all tiktoks if not set
+	Tiktoks   []CreateTiktok `validate:"required,min=2,max=512" json:"tiktoks"` // pool of tiktoks
+	IsPrivate bool           `json:"isPrivate"`                                 // by default public, so we don't need this field to be required
+	// elo by default
+	RatingSystem string `validate:"omitempty,oneof=elo glicko2" json:"ratingSystem"`
+	// 1 by default, votes to decide match of single elimination and king of the hill
+	BestOf int `validate:"omitempty,oneof=1 3 5" json:"bestOf"`
+	// S, A, B, C, D by default, tiers of tier lists ordered from best to worst
+	Tiers []string `validate:"omitempty,min=2,max=10,unique,dive,min=1,max=32" json:"tiers"`
 }
 
 type EditTournament struct {
 	Name      string         `validate:"required" json:"name"`
 	PhotoURL  string         `validate:"required" json:"photoURL"`
-	Size      int            `validate:"gte=4,lte=64" json:"size"`
-	Tiktoks   []CreateTiktok `validate:"required" json:"tiktoks"`
-	IsPrivate bool           `json:"isPrivate"` // by default public, so we don't need this field to be required
+	Size      int            `validate:"omitempty,gte=2,lte=512" json:"size"`   // tiktoks drawn for every play, all tiktoks if not set
+	Tiktoks   []CreateTiktok `validate:"required,min=2,max=512" json:"tiktoks"` // pool of tiktoks
+	IsPrivate bool           `json:"isPrivate"`                                 // by default public, so we don't need this field to be required
+	// elo by default
+	RatingSystem string `validate:"omitempty,oneof=elo glicko2" json:"ratingSystem"`
+	// 1 by default, votes to decide match of single elimination and king of the hill
+	BestOf int `validate:"omitempty,oneof=1 3 5" json:"bestOf"`
+	// S, A, B, C, D by default, tiers of tier lists ordered from best to worst
+	Tiers []string `validate:"omitempty,min=2,max=10,unique,dive,min=1,max=32" json:"tiers"`
 }
 
 type TournamentWithoutUser struct {
@@ -51,5 +65,24 @@ type TournamentWithoutUser struct {
 
 type TournamentStats struct {
 	TournamentId uuid.UUID     `json:"tournamentId"`
+	RatingSystem string        `json:"ratingSystem"`
 	TiktoksStats []TiktokStats `json:"tiktoksStats"`
+	TierList     TierListStats `json:"tierList"`
+}
+
+const (
+	SortByWins   = "wins"
+	SortByRating = "rating"
+	SortByRank   = "rank" // by average rank in full rankings
+)
+
+type StatsQueries struct {
+	Sort string `validate:"omitempty,oneof=wins rating rank" query:"sort" json:"sort"`
+}
+
+type TournamentMatrix struct {
+	TournamentId uuid.UUID   `json:"tournamentId"`
+	TiktokURLs   []string    `json:"tiktokURLs"`
+	Matchups     []Matchup   `json:"matchups"`
+	WinRates     [][]float64 `json:"winRates"` // part of matches between tiktoks i and j won by i, -1 if they never met
 }
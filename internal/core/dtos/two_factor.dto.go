@@ -0,0 +1,28 @@
+package dtos
+
+type TwoFactorEnrollInput struct {
+	Password string `json:"password"` // required if user has password
+}
+
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`     // base32 secret for manual entry
+	OtpauthURI string `json:"otpauthURI"` // shown as QR code to add account to authenticator app
+}
+
+type TwoFactorCode struct {
+	Code string `validate:"required,max=32" json:"code"` // TOTP code, recovery code is accepted where noted
+}
+
+type TwoFactorDisable struct {
+	Password string `json:"password"`                        // required if user has password
+	Code     string `validate:"required,max=32" json:"code"` // TOTP or recovery code
+}
+
+type TwoFactorLogin struct {
+	ChallengeToken string `validate:"required" json:"challengeToken"`
+	Code           string `validate:"required,max=32" json:"code"` // TOTP or recovery code
+}
+
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes"` // shown only once, every code can be used once
+}
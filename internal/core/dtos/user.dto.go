@@ -2,12 +2,11 @@ package dtos
 
 import (
 	"github.com/google/uuid"
-	"tiktok-arena/internal/core/models"
 )
 
 type UsersResponse struct {
-	UserCount int64         `validate:"required" json:"userCount"`
-	Users     []models.User `validate:"required" json:"users"`
+	UserCount int64        `validate:"required" json:"userCount"`
+	Users     []PublicUser `validate:"required" json:"users"`
 }
 
 type ChangePhotoURL struct {
@@ -15,21 +14,70 @@ type ChangePhotoURL struct {
 }
 
 type AuthInput struct {
+	Name     string `validate:"required" json:"name"`
+	Password string `validate:"required,password" json:"password"` // checked against password policy
+	Email    string `validate:"omitempty,email" json:"email"`      // optional, required to reset password
+}
+
+type LoginInput struct {
 	Name     string `validate:"required" json:"name"`
 	Password string `validate:"required" json:"password"`
 }
 
+type ChangePasswordInput struct {
+	OldPassword string `json:"oldPassword"` // required if user has password
+	NewPassword string `validate:"required,password" json:"newPassword"`
+}
+
+type ChangeEmailInput struct {
+	Email    string `validate:"required,email" json:"email"` // new email, changed after it is confirmed
+	Password string `json:"password"`                        // required if user has password
+	Code     string `json:"code"`                            // TOTP or recovery code, required if user has 2FA
+}
+
+type ConfirmEmailChange struct {
+	Token string `validate:"required" json:"token"`
+}
+
+type PasswordResetRequest struct {
+	Name string `validate:"required" json:"name"`
+}
+
+type PasswordReset struct {
+	Token       string `validate:"required" json:"token"`
+	NewPassword string `validate:"required,password" json:"newPassword"`
+}
+
 type RegisterDetails struct {
-	ID    uuid.UUID `json:"id"`
-	Name  string    `json:"name"`
-	Token string    `json:"token"`
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refreshToken"`
 }
 
 type LoginDetails struct {
-	ID       uuid.UUID `json:"id"`
-	Name     string    `json:"name"`
-	Token    string    `json:"token"`
-	PhotoURL string    `json:"photoURL"`
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refreshToken"`
+	PhotoURL     string    `json:"photoURL"`
+	// set instead of tokens if user has 2FA, challenge token is exchanged for tokens with TOTP or recovery code
+	TwoFactorRequired bool   `json:"twoFactorRequired"`
+	ChallengeToken    string `json:"challengeToken,omitempty"`
+}
+
+type RefreshInput struct {
+	RefreshToken string `validate:"required" json:"refreshToken"`
+}
+
+type LogoutInput struct {
+	RefreshToken string `json:"refreshToken"` // optional, refresh token to revoke with access token
+	All          bool   `json:"all"`          // revoke all refresh tokens of user, logout from every device
+}
+
+type TokenDetails struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
 }
 
 type WhoAmI struct {
@@ -38,3 +86,11 @@ type WhoAmI struct {
 	Token    string `json:"token"`
 	PhotoURL string `json:"photoURL"`
 }
+
+type BanUser struct {
+	IsBanned bool `json:"isBanned"` // false to unban user
+}
+
+type ChangeRole struct {
+	Role string `validate:"required,oneof=user moderator admin" json:"role"`
+}
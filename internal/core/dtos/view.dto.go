@@ -0,0 +1,182 @@
+package dtos
+
+import (
+	"github.com/google/uuid"
+	"tiktok-arena/internal/core/models"
+	"time"
+)
+
+// Views
+// Models are never serialized in responses, they are mapped to views first,
+// so internal fields of models (password hash, email, role, 2FA secrets, key hashes) can't reach JSON.
+
+// PublicUser view of user which is returned with users and tournaments
+type PublicUser struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	PhotoURL string    `json:"photoURL"`
+}
+
+// TournamentView view of tournament with its public user
+type TournamentView struct {
+	ID           uuid.UUID    `json:"id"`
+	Name         string       `json:"name"`
+	Size         int          `json:"size"`
+	TimesPlayed  int          `json:"timesPlayed"`
+	UserID       uuid.UUID    `json:"userID"`
+	User         PublicUser   `json:"user"`
+	IsPrivate    bool         `json:"isPrivate"`
+	PhotoURL     string       `json:"photoURL"`
+	RatingSystem string       `json:"ratingSystem"`
+	BestOf       int          `json:"bestOf"`
+	Tiers        models.Tiers `json:"tiers"`
+}
+
+// APIKeyView view of API key without its hash
+type APIKeyView struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // start of key to recognize it in list
+	Scope      string     `json:"scope"`
+	ExpiresAt  *time.Time `json:"expiresAt"` // key doesn't expire if not set
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// PlaySessionView view of play session without its serialized contest
+type PlaySessionView struct {
+	ID           uuid.UUID  `json:"id"`
+	TournamentID uuid.UUID  `json:"tournamentID"`
+	UserID       *uuid.UUID `json:"userID"` // empty for anonymous players
+	ContestType  string     `json:"contestType"`
+	IsCompleted  bool       `json:"isCompleted"`
+	WinnerURL    string     `json:"winnerURL"`
+	SecondURL    string     `json:"secondURL"`
+	ThirdURL     string     `json:"thirdURL"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+type MatchDecisionView struct {
+	MatchID   string    `json:"matchID"`
+	WinnerURL string    `json:"winnerURL"`
+	LoserURL  string    `json:"loserURL"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type TierListView struct {
+	ID           uuid.UUID           `json:"id"`
+	TournamentID uuid.UUID           `json:"tournamentID"`
+	Placements   []TierPlacementView `json:"placements"`
+	CreatedAt    time.Time           `json:"createdAt"`
+}
+
+type TierPlacementView struct {
+	TiktokURL string `json:"tiktokURL"`
+	Tier      string `json:"tier"`
+}
+
+func NewPublicUser(user models.User) PublicUser {
+	return PublicUser{
+		ID:       user.ID,
+		Name:     user.Name,
+		PhotoURL: user.PhotoURL,
+	}
+}
+
+func NewPublicUsers(users []models.User) []PublicUser {
+	views := make([]PublicUser, 0, len(users))
+	for _, user := range users {
+		views = append(views, NewPublicUser(user))
+	}
+	return views
+}
+
+func NewTournamentView(tournament models.Tournament) TournamentView {
+	return TournamentView{
+		ID:           tournament.ID,
+		Name:         tournament.Name,
+		Size:         tournament.Size,
+		TimesPlayed:  tournament.TimesPlayed,
+		UserID:       tournament.UserID,
+		User:         NewPublicUser(tournament.User),
+		IsPrivate:    tournament.IsPrivate,
+		PhotoURL:     tournament.PhotoURL,
+		RatingSystem: tournament.RatingSystem,
+		BestOf:       tournament.BestOf,
+		Tiers:        tournament.Tiers,
+	}
+}
+
+func NewTournamentViews(tournaments []models.Tournament) []TournamentView {
+	views := make([]TournamentView, 0, len(tournaments))
+	for _, tournament := range tournaments {
+		views = append(views, NewTournamentView(tournament))
+	}
+	return views
+}
+
+func NewAPIKeyView(key models.APIKey) APIKeyView {
+	return APIKeyView{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scope:      key.Scope,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+func NewAPIKeyViews(keys []models.APIKey) []APIKeyView {
+	views := make([]APIKeyView, 0, len(keys))
+	for _, key := range keys {
+		views = append(views, NewAPIKeyView(key))
+	}
+	return views
+}
+
+func NewPlaySessionView(session models.PlaySession) PlaySessionView {
+	return PlaySessionView{
+		ID:           session.ID,
+		TournamentID: session.TournamentID,
+		UserID:       session.UserID,
+		ContestType:  session.ContestType,
+		IsCompleted:  session.IsCompleted,
+		WinnerURL:    session.WinnerURL,
+		SecondURL:    session.SecondURL,
+		ThirdURL:     session.ThirdURL,
+		CreatedAt:    session.CreatedAt,
+		UpdatedAt:    session.UpdatedAt,
+	}
+}
+
+func NewMatchDecisionView(decision models.MatchDecision) MatchDecisionView {
+	return MatchDecisionView{
+		MatchID:   decision.MatchID,
+		WinnerURL: decision.WinnerURL,
+		LoserURL:  decision.LoserURL,
+		CreatedAt: decision.CreatedAt,
+	}
+}
+
+func NewTierListView(tierList models.TierList) TierListView {
+	placements := make([]TierPlacementView, 0, len(tierList.Placements))
+	for _, placement := range tierList.Placements {
+		placements = append(placements, TierPlacementView{TiktokURL: placement.TiktokURL, Tier: placement.Tier})
+	}
+	return TierListView{
+		ID:           tierList.ID,
+		TournamentID: tierList.TournamentID,
+		Placements:   placements,
+		CreatedAt:    tierList.CreatedAt,
+	}
+}
+
+func NewTierListViews(tierLists []models.TierList) []TierListView {
+	views := make([]TierListView, 0, len(tierLists))
+	for _, tierList := range tierLists {
+		views = append(views, NewTierListView(tierList))
+	}
+	return views
+}
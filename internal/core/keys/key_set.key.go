@@ -0,0 +1,274 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"tiktok-arena/configuration"
+	"tiktok-arena/internal/core/dtos"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+// Key
+// Key to sign or verify JWT, ID is sent in "kid" header of JWT.
+// For HS256 both keys are shared secret, only verification key is set for keys of previous rotations.
+type Key struct {
+	ID         string
+	Method     jwt.SigningMethod
+	signingKey interface{}
+	verifyKey  interface{}
+}
+
+// KeySet
+// Key which signs new JWT and all keys which verify JWT, so tokens signed with previous keys stay valid during rotation.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+var (
+	current *KeySet
+	loadErr error
+	once    sync.Once
+)
+
+// Get returns key set loaded from configuration on first call
+func Get() (*KeySet, error) {
+	once.Do(func() {
+		current, loadErr = Load(configuration.EnvConfig)
+	})
+	return current, loadErr
+}
+
+// Load creates key set from configuration.
+// Signing key is shared secret for HS256 or private key from PEM file for RS256 and EdDSA,
+// public keys from PEM files are used only to verify JWT signed with previous keys.
+// Shared secret verifies JWT with any signing algorithm if it is set, so HS256 tokens stay valid after migration.
+func Load(config configuration.EnvConfigModel) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*Key)}
+
+	if config.JwtSecret != "" || config.JwtAlgorithm == "" || config.JwtAlgorithm == HS256 {
+		secret := []byte(config.JwtSecret)
+		ks.add(&Key{ID: secretID(secret), Method: jwt.SigningMethodHS256, signingKey: secret, verifyKey: secret})
+	}
+
+	switch config.JwtAlgorithm {
+	case "", HS256:
+		ks.signing = ks.keys[secretID([]byte(config.JwtSecret))]
+	case RS256, EdDSA:
+		key, err := loadPrivateKey(config.JwtPrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if key.Method.Alg() != config.JwtAlgorithm {
+			return nil, fmt.Errorf("key %s is %s key, not %s key",
+				config.JwtPrivateKeyFile, key.Method.Alg(), config.JwtAlgorithm)
+		}
+		ks.add(key)
+		ks.signing = key
+	default:
+		return nil, fmt.Errorf("signing algorithm %s is not supported", config.JwtAlgorithm)
+	}
+
+	for _, file := range strings.Split(config.JwtPublicKeyFiles, ",") {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			continue
+		}
+		key, err := loadPublicKey(file)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := ks.keys[key.ID]; !exists {
+			ks.add(key)
+		}
+	}
+	return ks, nil
+}
+
+// Sign signs claims with signing key, ID of key is added to "kid" header
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	token.Header["kid"] = ks.signing.ID
+	return token.SignedString(ks.signing.signingKey)
+}
+
+// KeyFunc finds key to verify JWT by its "kid" header and checks that JWT is signed with algorithm of key,
+// JWT without "kid" is verified with shared secret (issued before keys had IDs)
+func (ks *KeySet) KeyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	var key *Key
+	if kid == "" {
+		for _, k := range ks.keys {
+			if k.Method == jwt.SigningMethodHS256 {
+				key = k
+			}
+		}
+	} else {
+		key = ks.keys[kid]
+	}
+	if key == nil {
+		return nil, fmt.Errorf("unknown key %s", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("key %s doesn't verify %s", kid, token.Method.Alg())
+	}
+	return key.verifyKey, nil
+}
+
+// JWKS returns public keys of key set, shared secrets are never exposed
+func (ks *KeySet) JWKS() dtos.JWKS {
+	jwks := dtos.JWKS{Keys: []dtos.JWK{}}
+	for _, key := range ks.keys {
+		jwk, ok := publicJWK(key.verifyKey)
+		if !ok {
+			continue
+		}
+		jwk.Kid = key.ID
+		jwk.Use = "sig"
+		jwk.Alg = key.Method.Alg()
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].Kid < jwks.Keys[j].Kid
+	})
+	return jwks
+}
+
+func (ks *KeySet) add(key *Key) {
+	ks.keys[key.ID] = key
+}
+
+func loadPrivateKey(file string) (*Key, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+	private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %s is not PKCS #8 or PKCS #1 private key: %v", file, err)
+	}
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("key %s can't sign", file)
+	}
+	key, err := publicKey(signer.Public())
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %v", file, err)
+	}
+	key.signingKey = private
+	return key, nil
+}
+
+func loadPublicKey(file string) (*Key, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		public, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %s is not PKIX or PKCS #1 public key: %v", file, err)
+	}
+	key, err := publicKey(public)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %v", file, err)
+	}
+	return key, nil
+}
+
+// publicKey creates verification key with ID derived from public key
+func publicKey(public crypto.PublicKey) (*Key, error) {
+	var method jwt.SigningMethod
+	switch public.(type) {
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("only RSA and Ed25519 keys are supported, got %T", public)
+	}
+	jwk, _ := publicJWK(public)
+	return &Key{ID: thumbprint(jwk), Method: method, verifyKey: public}, nil
+}
+
+func readPEM(file string) (*pem.Block, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %s is not PEM encoded", file)
+	}
+	return block, nil
+}
+
+func publicJWK(key interface{}) (dtos.JWK, bool) {
+	switch public := key.(type) {
+	case *rsa.PublicKey:
+		return dtos.JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return dtos.JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(public),
+		}, true
+	}
+	return dtos.JWK{}, false
+}
+
+// thumbprint
+// https://www.rfc-editor.org/rfc/rfc7638
+// Hash of required members of JWK in lexicographic order, used as ID of public keys.
+func thumbprint(jwk dtos.JWK) string {
+	var members []byte
+	if jwk.Kty == "RSA" {
+		members, _ = json.Marshal(struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N})
+	} else {
+		members, _ = json.Marshal(struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X})
+	}
+	hash := sha256.Sum256(members)
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// secretID derives ID of shared secret without exposing it
+func secretID(secret []byte) string {
+	hash := sha256.Sum256(append([]byte("tiktok-arena:"), secret...))
+	return "hs256-" + hex.EncodeToString(hash[:8])
+}
@@ -0,0 +1,113 @@
+package keys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"tiktok-arena/configuration"
+)
+
+func writePEM(t *testing.T, name string, blockType string, der []byte) string {
+	file := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600)
+	require.Nil(t, err)
+	return file
+}
+
+func rsaKeyFiles(t *testing.T) (private string, public string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+	privateDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.Nil(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.Nil(t, err)
+	return writePEM(t, "rsa.pem", "PRIVATE KEY", privateDER), writePEM(t, "rsa.pub.pem", "PUBLIC KEY", publicDER)
+}
+
+func ed25519KeyFile(t *testing.T) string {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.Nil(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.Nil(t, err)
+	return writePEM(t, "ed25519.pem", "PRIVATE KEY", der)
+}
+
+func verify(ks *KeySet, token string) error {
+	_, err := jwt.Parse(token, ks.KeyFunc)
+	return err
+}
+
+func TestSignedTokenIsVerifiedWithKid(t *testing.T) {
+	rsaPrivate, _ := rsaKeyFiles(t)
+	configs := map[string]configuration.EnvConfigModel{
+		HS256: {JwtSecret: "secret"},
+		RS256: {JwtAlgorithm: RS256, JwtPrivateKeyFile: rsaPrivate},
+		EdDSA: {JwtAlgorithm: EdDSA, JwtPrivateKeyFile: ed25519KeyFile(t)},
+	}
+	for alg, config := range configs {
+		t.Run(alg, func(t *testing.T) {
+			ks, err := Load(config)
+			require.Nil(t, err)
+			token, err := ks.Sign(jwt.MapClaims{"sub": "user"})
+			require.Nil(t, err)
+
+			parsed, _ := jwt.Parse(token, nil)
+			assert.Equal(t, alg, parsed.Header["alg"])
+			assert.NotEmpty(t, parsed.Header["kid"])
+			assert.Nil(t, verify(ks, token))
+		})
+	}
+}
+
+func TestPreviousKeyVerifiesTokenAfterRotation(t *testing.T) {
+	oldPrivate, oldPublic := rsaKeyFiles(t)
+	old, err := Load(configuration.EnvConfigModel{JwtAlgorithm: RS256, JwtPrivateKeyFile: oldPrivate})
+	require.Nil(t, err)
+	token, err := old.Sign(jwt.MapClaims{"sub": "user"})
+	require.Nil(t, err)
+
+	rotated, err := Load(configuration.EnvConfigModel{
+		JwtAlgorithm:      EdDSA,
+		JwtPrivateKeyFile: ed25519KeyFile(t),
+		JwtPublicKeyFiles: oldPublic,
+	})
+	require.Nil(t, err)
+	assert.Nil(t, verify(rotated, token), "token signed with previous key is not verified")
+	assert.Len(t, rotated.JWKS().Keys, 2)
+
+	withoutOld, err := Load(configuration.EnvConfigModel{JwtAlgorithm: EdDSA, JwtPrivateKeyFile: ed25519KeyFile(t)})
+	require.Nil(t, err)
+	assert.NotNil(t, verify(withoutOld, token), "token signed with removed key is verified")
+}
+
+func TestSecretIsNotExposedAndAlgorithmIsChecked(t *testing.T) {
+	rsaPrivate, _ := rsaKeyFiles(t)
+	ks, err := Load(configuration.EnvConfigModel{JwtSecret: "secret", JwtAlgorithm: RS256, JwtPrivateKeyFile: rsaPrivate})
+	require.Nil(t, err)
+	for _, jwk := range ks.JWKS().Keys {
+		assert.Equal(t, "RSA", jwk.Kty, "shared secret is exposed as %v", jwk)
+		assert.False(t, strings.HasPrefix(jwk.Kid, "hs256"), "shared secret is exposed as %v", jwk)
+	}
+
+	// Token signed with shared secret and kid of RSA key must not be verified
+	kid := ks.JWKS().Keys[0].Kid
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user"})
+	forged.Header["kid"] = kid
+	signed, err := forged.SignedString([]byte("secret"))
+	require.Nil(t, err)
+	assert.NotNil(t, verify(ks, signed), "token signed with algorithm of other key is verified")
+
+	// Tokens issued before keys had IDs are verified with shared secret
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user"}).SignedString([]byte("secret"))
+	require.Nil(t, err)
+	assert.Nil(t, verify(ks, legacy), "token without kid is not verified")
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// APIKey
+// Personal key of user for scripted access, only hash of key is stored.
+// Key is accepted in X-API-Key header on routes which allow its scope.
+type APIKey struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"userID"`
+	Name       string     `gorm:"not null;default:null" json:"name"`
+	Prefix     string     `gorm:"not null;default:null" json:"prefix"`        // start of key to recognize it in list
+	KeyHash    string     `gorm:"not null;default:null;uniqueIndex" json:"-"` // hex encoded sha256 of key
+	Scope      string     `gorm:"not null;default:read" json:"scope"`
+	ExpiresAt  *time.Time `json:"expiresAt"` // key doesn't expire if not set
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+const (
+	ScopeRead            = "read"
+	ScopeTournamentWrite = "tournament:write"
+)
+
+// scopeLevels every scope allows actions of scopes with lower level
+var scopeLevels = map[string]int{
+	ScopeRead:            0,
+	ScopeTournamentWrite: 1,
+}
+
+// HasScope checks if scope allows actions of required scope, unknown scopes allow nothing
+func HasScope(scope string, required string) bool {
+	level, ok := scopeLevels[scope]
+	return ok && level >= scopeLevels[required]
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// UserIdentity
+// Account of user at external OpenID Connect provider, subject is unique for provider.
+type UserIdentity struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"userID"`
+	Provider  string    `gorm:"not null;default:null;uniqueIndex:idx_identity_subject" json:"provider"`
+	Subject   string    `gorm:"not null;default:null;uniqueIndex:idx_identity_subject" json:"subject"`
+	Email     string    `json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// OIDCLoginState
+// Login at external provider which is not completed yet, only hash of state is stored.
+// State is deleted when provider redirects back, so it can't be used twice.
+type OIDCLoginState struct {
+	StateHash    string     `gorm:"primary_key" json:"-"` // hex encoded sha256 of state
+	Provider     string     `gorm:"not null;default:null" json:"provider"`
+	CodeVerifier string     `gorm:"not null;default:null" json:"-"` // PKCE code verifier
+	Nonce        string     `gorm:"not null;default:null" json:"-"`
+	UserID       *uuid.UUID `gorm:"type:uuid" json:"userID"` // set if identity is linked to already logged in user
+	ExpiresAt    time.Time  `gorm:"not null;index" json:"expiresAt"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// Matchup
+// Results of matches between two tiktoks of tournament, pair is stored once with FirstURL < SecondURL
+type Matchup struct {
+	TournamentID uuid.UUID `gorm:"type:uuid;not null;primaryKey;default:null" json:"tournamentID"`
+	FirstURL     string    `gorm:"not null;primaryKey;default:null" json:"firstURL"`
+	SecondURL    string    `gorm:"not null;primaryKey;default:null" json:"secondURL"`
+	FirstWins    int       `gorm:"not null;default:0" json:"firstWins"`
+	SecondWins   int       `gorm:"not null;default:0" json:"secondWins"`
+}
+
+// NewMatchup creates matchup with one win for winner, ordering pair of tiktoks
+func NewMatchup(tournamentId uuid.UUID, winnerURL string, loserURL string) Matchup {
+	if winnerURL < loserURL {
+		return Matchup{TournamentID: tournamentId, FirstURL: winnerURL, SecondURL: loserURL, FirstWins: 1}
+	}
+	return Matchup{TournamentID: tournamentId, FirstURL: loserURL, SecondURL: winnerURL, SecondWins: 1}
+}
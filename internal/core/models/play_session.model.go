@@ -0,0 +1,51 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type PlaySession struct {
+	ID           uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	TournamentID uuid.UUID  `gorm:"type:uuid;not null" json:"tournamentID"`
+	Tournament   Tournament `gorm:"foreignKey:TournamentID" json:"-"`
+	UserID       *uuid.UUID `gorm:"type:uuid" json:"userID"` // empty for anonymous players
+	ContestType  string     `gorm:"not null;default:null" json:"contestType"`
+	Contest      string     `gorm:"type:jsonb;not null;default:null" json:"-"` // serialized dtos.Contest
+	IsCompleted  bool       `gorm:"not null;default:false" json:"isCompleted"`
+	WinnerURL    string     `json:"winnerURL"`
+	SecondURL    string     `json:"secondURL"`
+	ThirdURL     string     `json:"thirdURL"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+// MatchDecision
+// Winner of match of play session, every match is decided only once.
+type MatchDecision struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	SessionID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_decision_match" json:"sessionID"`
+	MatchID   string    `gorm:"not null;default:null;uniqueIndex:idx_decision_match" json:"matchID"`
+	WinnerURL string    `gorm:"not null;default:null" json:"winnerURL"`
+	LoserURL  string    `gorm:"not null;default:null" json:"loserURL"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// MatchVote
+// Single vote of play session, match is decided when tiktok gets required count of votes.
+type MatchVote struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	SessionID uuid.UUID `gorm:"type:uuid;not null;index" json:"sessionID"`
+	MatchID   string    `gorm:"not null;default:null" json:"matchID"`
+	TiktokURL string    `gorm:"not null;default:null" json:"tiktokURL"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// SessionRanking
+// Place of tiktok in complete ranking of finished full ranking session.
+type SessionRanking struct {
+	SessionID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"sessionID"`
+	TournamentID uuid.UUID `gorm:"type:uuid;not null;index" json:"tournamentID"`
+	TiktokURL    string    `gorm:"primaryKey" json:"tiktokURL"`
+	Rank         int       `gorm:"not null" json:"rank"` // starting from 1
+}
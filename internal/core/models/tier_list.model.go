@@ -0,0 +1,55 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+// DefaultTiers are used when tournament creator doesn't configure tiers
+var DefaultTiers = Tiers{"S", "A", "B", "C", "D"}
+
+// Tiers
+// Tiers of tournament tier lists ordered from best to worst, stored as JSON array.
+type Tiers []string
+
+func (t Tiers) Value() (driver.Value, error) {
+	if t == nil {
+		return nil, nil
+	}
+	serialized, err := json.Marshal(t)
+	return string(serialized), err
+}
+
+func (t *Tiers) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	}
+	return errors.New("unsupported type of tiers")
+}
+
+// TierList
+// Placements of all tournament tiktoks into tiers submitted by player at once.
+type TierList struct {
+	ID           uuid.UUID       `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	TournamentID uuid.UUID       `gorm:"type:uuid;not null;index" json:"tournamentID"`
+	Tournament   Tournament      `gorm:"foreignKey:TournamentID" json:"-"`
+	UserID       *uuid.UUID      `gorm:"type:uuid" json:"userID"` // empty for anonymous players
+	Placements   []TierPlacement `gorm:"foreignKey:TierListID" json:"placements"`
+	CreatedAt    time.Time       `json:"createdAt"`
+}
+
+type TierPlacement struct {
+	TierListID   uuid.UUID `gorm:"type:uuid;not null;primaryKey;default:null" json:"tierListID"`
+	TournamentID uuid.UUID `gorm:"type:uuid;not null;index" json:"tournamentID"`
+	TiktokURL    string    `gorm:"not null;primaryKey;default:null" json:"tiktokURL"`
+	Tier         string    `gorm:"not null;default:null" json:"tier"`
+}
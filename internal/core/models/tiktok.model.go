@@ -3,7 +3,6 @@ package models
 import (
 	"github.com/google/uuid"
 	"math/rand"
-	"time"
 )
 
 type Tiktok struct {
@@ -12,6 +11,13 @@ type Tiktok struct {
 	Name         string     `gorm:"not null;default:null" json:"name"`
 	URL          string     `gorm:"not null;primaryKey;default:null" json:"url"`
 	Wins         int        `json:"wins"`
+	Appearances  int        `gorm:"not null;default:0" json:"appearances"` // count of tournament plays with tiktok
+	SecondPlaces int        `gorm:"not null;default:0" json:"secondPlaces"`
+	ThirdPlaces  int        `gorm:"not null;default:0" json:"thirdPlaces"`
+	// Rating after recorded matches, deviation and volatility are used only by Glicko-2
+	Rating           float64 `gorm:"not null;default:1500" json:"rating"`
+	RatingDeviation  float64 `gorm:"not null;default:350" json:"ratingDeviation"`
+	RatingVolatility float64 `gorm:"not null;default:0.06" json:"ratingVolatility"`
 }
 
 func FindDifferenceOfTwoTiktokSlices(s1 []Tiktok, s2 []Tiktok) []Tiktok {
@@ -40,7 +46,6 @@ func ContainsTiktok(slice []Tiktok, t Tiktok) bool {
 	return false
 }
 
-func ShuffleTiktok(t []Tiktok) {
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(t), func(i, j int) { t[i], t[j] = t[j], t[i] })
+func ShuffleTiktok(t []Tiktok, r *rand.Rand) {
+	r.Shuffle(len(t), func(i, j int) { t[i], t[j] = t[j], t[i] })
 }
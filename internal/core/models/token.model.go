@@ -0,0 +1,79 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// RefreshToken
+// Long-lived token used to get new access tokens, only hash of token is stored.
+// Token is rotated on every refresh, rotated tokens share FamilyID with token issued on login,
+// so reuse of already rotated token revokes the whole family.
+type RefreshToken struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"userID"`
+	FamilyID  uuid.UUID `gorm:"type:uuid;not null;index" json:"familyID"`
+	TokenHash string    `gorm:"not null;default:null;uniqueIndex" json:"-"` // hex encoded sha256 of token
+	IsRevoked bool      `gorm:"not null;default:false" json:"isRevoked"`
+	ExpiresAt time.Time `gorm:"not null" json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// RevokedToken
+// Access token revoked before its expiration, kept until it expires.
+type RevokedToken struct {
+	JTI       string    `gorm:"primary_key" json:"jti"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expiresAt"`
+}
+
+// RevokedUserTokens
+// All access tokens of user issued before RevokedAt are revoked, e.g. when user is banned or gets new role,
+// kept until the last of them expires.
+type RevokedUserTokens struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primary_key" json:"userID"`
+	RevokedAt time.Time `gorm:"not null" json:"revokedAt"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expiresAt"`
+}
+
+// PasswordResetToken
+// One-time token sent to user to reset password, only hash of token is stored.
+type PasswordResetToken struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"userID"`
+	TokenHash string    `gorm:"not null;default:null;uniqueIndex" json:"-"` // hex encoded sha256 of token
+	IsUsed    bool      `gorm:"not null;default:false" json:"isUsed"`
+	ExpiresAt time.Time `gorm:"not null" json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// EmailChangeToken
+// One-time token sent to new email of user, email is changed only after token is used, only hash of token is stored.
+type EmailChangeToken struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"userID"`
+	Email     string    `gorm:"not null;default:null" json:"-"`             // new email of user
+	TokenHash string    `gorm:"not null;default:null;uniqueIndex" json:"-"` // hex encoded sha256 of token
+	IsUsed    bool      `gorm:"not null;default:false" json:"isUsed"`
+	ExpiresAt time.Time `gorm:"not null" json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// RecoveryCode
+// One-time code which replaces TOTP code if user lost authenticator, only hash of code is stored.
+type RecoveryCode struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"userID"`
+	CodeHash  string    `gorm:"not null;default:null" json:"-"` // hex encoded sha256 of code
+	IsUsed    bool      `gorm:"not null;default:false" json:"isUsed"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TwoFactorChallenge
+// Short-lived token issued after password check of user with 2FA, it is exchanged
+// for access token with TOTP or recovery code, only hash of token is stored.
+type TwoFactorChallenge struct {
+	TokenHash string    `gorm:"primary_key" json:"-"` // hex encoded sha256 of token
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"userID"`
+	Attempts  int       `gorm:"not null;default:0" json:"attempts"` // failed attempts, challenge is deleted after too many
+	ExpiresAt time.Time `gorm:"not null;index" json:"expiresAt"`
+}
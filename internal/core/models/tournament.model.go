@@ -7,10 +7,24 @@ import (
 type Tournament struct {
 	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
 	Name        string    `gorm:"not null;default:null" json:"name"`
-	Size        int       `gorm:"not null" json:"size"`
+	Size        int       `gorm:"not null" json:"size"` // count of tiktoks drawn from pool for every play
 	TimesPlayed int       `gorm:"not null" json:"timesPlayed"`
 	UserID      uuid.UUID `gorm:"not null"  json:"userID"`
-	User        User      `gorm:"foreignKey:UserID" json:"user"`
+	User        User      `gorm:"foreignKey:UserID;constraint:OnDelete:RESTRICT" json:"user"` // tournaments are deleted or moved to DeletedUserID before user is deleted
 	IsPrivate   bool      `gorm:"not null;default:false" json:"isPrivate"`
 	PhotoURL    string    `json:"photoURL"`
+	// Rating system of tournament tiktoks (elo or glicko2)
+	RatingSystem string `gorm:"not null;default:elo" json:"ratingSystem"`
+	// Count of votes in matches of single elimination and king of the hill, match is won by majority of votes
+	BestOf int `gorm:"not null;default:1" json:"bestOf"`
+	// Tiers of tier lists ordered from best to worst, DefaultTiers if not set
+	Tiers Tiers `gorm:"type:jsonb" json:"tiers"`
+}
+
+// GetTiers returns tiers of tournament or default tiers if they are not configured
+func (t Tournament) GetTiers() Tiers {
+	if len(t.Tiers) == 0 {
+		return DefaultTiers
+	}
+	return t.Tiers
 }
@@ -2,11 +2,71 @@ package models
 
 import (
 	"github.com/google/uuid"
+	"strings"
+	"time"
 )
 
 type User struct {
 	ID       uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primary_key" json:"id"`
 	Name     string    `gorm:"not null;default:null" json:"name"`
-	Password string    `gorm:"not null;default:null" json:"password"`
+	Password string    `gorm:"not null;default:null" json:"-"` // bcrypt hash
 	PhotoURL string    `json:"photoURL"`
+	Email    string    `json:"-"` // optional, used to send password reset tokens
+	Role     string    `gorm:"not null;default:user" json:"-"`
+	IsBanned bool      `gorm:"not null;default:false" json:"-"` // banned users can't login
+	// Failed logins since last successful login, login is locked until LockedUntil after too many of them
+	FailedLogins int        `gorm:"not null;default:0" json:"-"`
+	LockedUntil  *time.Time `json:"-"`
+	// TOTP secret is set on enrollment, second login step is required after it is verified
+	TOTPSecret      string `json:"-"`
+	TOTPEnabled     bool   `gorm:"not null;default:false" json:"-"`
+	TOTPLastCounter int64  `gorm:"not null;default:0" json:"-"` // counter of last used code, so code can't be used twice
+	// Last login through provider, confirms identity of user without password before account is deleted
+	ProviderLoginAt *time.Time `json:"-"`
+}
+
+// NoPassword is stored instead of bcrypt hash for users who login only through provider
+const NoPassword = "!"
+
+// HasPassword checks if user can login with password
+func (u User) HasPassword() bool {
+	return u.Password != NoPassword
+}
+
+// Deleted user
+// Tournaments of deleted users are anonymised by moving them to this user, so they keep their statistics.
+// Deleted user is banned and has no password, so nobody can login as deleted user.
+var DeletedUserID = uuid.MustParse("ffffffff-ffff-ffff-ffff-ffffffffffff")
+
+const DeletedUserName = "[deleted]"
+
+// IsReservedName checks if name can't be taken by registered user
+func IsReservedName(name string) bool {
+	return strings.EqualFold(strings.TrimSpace(name), DeletedUserName)
+}
+
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// roleLevels every role has permissions of roles with lower level
+var roleLevels = map[string]int{
+	RoleUser:      0,
+	RoleModerator: 1,
+	RoleAdmin:     2,
+}
+
+// GetRole returns role of user or RoleUser if it is not set
+func (u User) GetRole() string {
+	if u.Role == "" {
+		return RoleUser
+	}
+	return u.Role
+}
+
+// HasRole checks if role has permissions of required role, unknown roles have permissions of user
+func HasRole(role string, required string) bool {
+	return roleLevels[role] >= roleLevels[required]
 }
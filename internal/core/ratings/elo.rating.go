@@ -0,0 +1,17 @@
+package ratings
+
+import "math"
+
+// Elo
+// https://en.wikipedia.org/wiki/Elo_rating_system
+type Elo struct {
+	K float64
+}
+
+func (e Elo) Update(winner Rating, loser Rating) (Rating, Rating) {
+	expectedWinner := 1 / (1 + math.Pow(10, (loser.Value-winner.Value)/400))
+	change := e.K * (1 - expectedWinner)
+	winner.Value += change
+	loser.Value -= change
+	return winner, loser
+}
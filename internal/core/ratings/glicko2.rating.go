@@ -0,0 +1,82 @@
+package ratings
+
+import "math"
+
+const (
+	glicko2Scale   = 173.7178
+	glicko2Epsilon = 0.000001
+)
+
+// Glicko2
+// http://www.glicko.net/glicko/glicko2.pdf
+// Every match is treated as separate rating period.
+type Glicko2 struct {
+	Tau float64 // Constrains change of volatility over time
+}
+
+func (g Glicko2) Update(winner Rating, loser Rating) (Rating, Rating) {
+	return g.update(winner, []Rating{loser}, []float64{1}),
+		g.update(loser, []Rating{winner}, []float64{0})
+}
+
+// update calculates rating after rating period with given opponents and scores (1 - win, 0 - loss)
+func (g Glicko2) update(player Rating, opponents []Rating, scores []float64) Rating {
+	mu := (player.Value - InitialValue) / glicko2Scale
+	phi := player.Deviation / glicko2Scale
+	sigma := player.Volatility
+
+	var vInverse, deltaSum float64
+	for i, opponent := range opponents {
+		muJ := (opponent.Value - InitialValue) / glicko2Scale
+		gJ := glicko2G(opponent.Deviation / glicko2Scale)
+		expected := 1 / (1 + math.Exp(-gJ*(mu-muJ)))
+		vInverse += gJ * gJ * expected * (1 - expected)
+		deltaSum += gJ * (scores[i] - expected)
+	}
+	v := 1 / vInverse
+	delta := v * deltaSum
+
+	// Finding new volatility with Illinois algorithm
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		return ex*(delta*delta-phi*phi-v-ex)/(2*math.Pow(phi*phi+v+ex, 2)) - (x-a)/(g.Tau*g.Tau)
+	}
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*g.Tau) < 0 {
+			k++
+		}
+		B = a - k*g.Tau
+	}
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > glicko2Epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	newSigma := math.Exp(A / 2)
+
+	phiStar := math.Sqrt(phi*phi + newSigma*newSigma)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	newMu := mu + newPhi*newPhi*deltaSum
+
+	return Rating{
+		Value:      newMu*glicko2Scale + InitialValue,
+		Deviation:  newPhi * glicko2Scale,
+		Volatility: newSigma,
+	}
+}
+
+func glicko2G(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
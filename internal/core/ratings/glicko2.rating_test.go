@@ -0,0 +1,30 @@
+package ratings
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// Example from http://www.glicko.net/glicko/glicko2.pdf
+func TestGlicko2Update(t *testing.T) {
+	player := Rating{Value: 1500, Deviation: 200, Volatility: 0.06}
+	opponents := []Rating{
+		{Value: 1400, Deviation: 30},
+		{Value: 1550, Deviation: 100},
+		{Value: 1700, Deviation: 300},
+	}
+	scores := []float64{1, 0, 0}
+
+	rating := Glicko2{Tau: 0.5}.update(player, opponents, scores)
+
+	assert.InDelta(t, 1464.06, rating.Value, 0.01)
+	assert.InDelta(t, 151.52, rating.Deviation, 0.01)
+	assert.InDelta(t, 0.05999, rating.Volatility, 0.00001)
+}
+
+func TestEloUpdate(t *testing.T) {
+	winner, loser := Elo{K: 32}.Update(Rating{Value: 1500}, Rating{Value: 1500})
+
+	assert.InDelta(t, 1516, winner.Value, 0.001)
+	assert.InDelta(t, 1484, loser.Value, 0.001)
+}
@@ -0,0 +1,46 @@
+package ratings
+
+const (
+	EloSystem     = "elo"
+	Glicko2System = "glicko2"
+)
+
+const (
+	InitialValue      = 1500
+	InitialDeviation  = 350
+	InitialVolatility = 0.06
+)
+
+type Rating struct {
+	Value      float64
+	Deviation  float64 // Only for Glicko-2
+	Volatility float64 // Only for Glicko-2
+}
+
+// System updates ratings of tiktoks after match
+type System interface {
+	Update(winner Rating, loser Rating) (Rating, Rating)
+}
+
+func GetAllowedRatingSystem() map[string]bool {
+	return map[string]bool{
+		EloSystem:     true,
+		Glicko2System: true,
+	}
+}
+
+// GetSystem returns rating system by name, Elo is used by default
+func GetSystem(name string) System {
+	if name == Glicko2System {
+		return Glicko2{Tau: 0.5}
+	}
+	return Elo{K: 32}
+}
+
+func Initial() Rating {
+	return Rating{
+		Value:      InitialValue,
+		Deviation:  InitialDeviation,
+		Volatility: InitialVolatility,
+	}
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"tiktok-arena/configuration"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+	"tiktok-arena/internal/core/validator"
+	"time"
+)
+
+// ReauthenticationMaxAge time after login through provider in which user without password can change credentials or delete account
+const ReauthenticationMaxAge = 5 * time.Minute
+
+type AccountServiceAccountRepository interface {
+	DeleteUser(id uuid.UUID, anonymiseTournaments bool) error
+	GetUserTournaments(userId uuid.UUID) ([]models.Tournament, error)
+	GetTournamentsTiktoks(tournamentIds []uuid.UUID) ([]models.Tiktok, error)
+	GetUserPlaySessions(userId uuid.UUID) ([]models.PlaySession, error)
+	GetSessionsDecisions(sessionIds []uuid.UUID) ([]models.MatchDecision, error)
+	GetUserTierLists(userId uuid.UUID) ([]models.TierList, error)
+	GetUserIdentities(userId uuid.UUID) ([]models.UserIdentity, error)
+}
+
+type AccountService struct {
+	AccountRepository AccountServiceAccountRepository
+	AuthService       *AuthService
+}
+
+func NewAccountService(accountRepository AccountServiceAccountRepository, authService *AuthService) *AccountService {
+	return &AccountService{AccountRepository: accountRepository, AuthService: authService}
+}
+
+// DeleteAccount deletes user of token after password and 2FA code are checked,
+// user without password confirms deletion with 2FA code or with recent login through provider.
+// Tournaments of user are deleted or anonymised by choice of user, all access tokens of user are revoked
+func (s *AccountService) DeleteAccount(token jwt.Token, input dtos.DeleteAccount) error {
+	err := validator.ValidateStruct(input)
+	if err != nil {
+		return ValidateError{err}
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	userId, err := uuid.Parse(claims["sub"].(string))
+	if err != nil {
+		return UUIDError{err}
+	}
+	user, err := s.AuthService.existingUser(userId)
+	if err != nil {
+		return err
+	}
+
+	if user.HasPassword() || !user.TOTPEnabled {
+		err = reauthenticate(user, input.Password)
+		if err != nil {
+			return err
+		}
+	}
+	if user.TOTPEnabled {
+		if input.Code == "" {
+			return InvalidTwoFactorCodeError{}
+		}
+		valid, err := s.AuthService.checkTwoFactorCode(user, input.Code)
+		if err != nil {
+			return err
+		}
+		if !valid {
+			return InvalidTwoFactorCodeError{}
+		}
+	}
+
+	err = s.AccountRepository.DeleteUser(user.ID, input.Tournaments == dtos.TournamentsAnonymise)
+	if err != nil {
+		return RepositoryError{err}
+	}
+
+	now := time.Now().UTC()
+	err = s.AuthService.TokenRepository.RevokeUserAccessTokens(user.ID, now, now.Add(configuration.EnvConfig.JwtExpiresIn))
+	if err != nil {
+		return RepositoryError{err}
+	}
+	return nil
+}
+
+// ExportAccount collects all personal data of user, format of export is chosen by caller
+func (s *AccountService) ExportAccount(userId uuid.UUID, queries dtos.ExportQueries) (export dtos.AccountExport, err error) {
+	err = validator.ValidateStruct(queries)
+	if err != nil {
+		return export, ValidateError{err}
+	}
+
+	user, err := s.AuthService.existingUser(userId)
+	if err != nil {
+		return export, err
+	}
+	export.ExportedAt = time.Now().UTC()
+	export.Profile = dtos.ExportedProfile{
+		ID:               user.ID,
+		Name:             user.Name,
+		Email:            user.Email,
+		PhotoURL:         user.PhotoURL,
+		Role:             user.GetRole(),
+		TwoFactorEnabled: user.TOTPEnabled,
+	}
+
+	tournaments, err := s.AccountRepository.GetUserTournaments(userId)
+	if err != nil {
+		return export, RepositoryError{err}
+	}
+	tournamentIds := make([]uuid.UUID, 0, len(tournaments))
+	for _, tournament := range tournaments {
+		tournamentIds = append(tournamentIds, tournament.ID)
+	}
+	tiktoks, err := s.AccountRepository.GetTournamentsTiktoks(tournamentIds)
+	if err != nil {
+		return export, RepositoryError{err}
+	}
+	tournamentTiktoks := make(map[uuid.UUID][]dtos.ExportedTiktok, len(tournaments))
+	for _, tiktok := range tiktoks {
+		tournamentTiktoks[tiktok.TournamentID] = append(tournamentTiktoks[tiktok.TournamentID], dtos.ExportedTiktok{
+			Name:         tiktok.Name,
+			URL:          tiktok.URL,
+			Wins:         tiktok.Wins,
+			Appearances:  tiktok.Appearances,
+			SecondPlaces: tiktok.SecondPlaces,
+			ThirdPlaces:  tiktok.ThirdPlaces,
+			Rating:       tiktok.Rating,
+		})
+	}
+	export.Tournaments = make([]dtos.ExportedTournament, 0, len(tournaments))
+	for _, tournament := range tournaments {
+		exported := dtos.ExportedTournament{
+			ID:           tournament.ID,
+			Name:         tournament.Name,
+			Size:         tournament.Size,
+			TimesPlayed:  tournament.TimesPlayed,
+			IsPrivate:    tournament.IsPrivate,
+			PhotoURL:     tournament.PhotoURL,
+			RatingSystem: tournament.RatingSystem,
+			BestOf:       tournament.BestOf,
+			Tiers:        tournament.GetTiers(),
+			Tiktoks:      tournamentTiktoks[tournament.ID],
+		}
+		if exported.Tiktoks == nil {
+			exported.Tiktoks = []dtos.ExportedTiktok{}
+		}
+		export.Tournaments = append(export.Tournaments, exported)
+	}
+
+	sessions, err := s.AccountRepository.GetUserPlaySessions(userId)
+	if err != nil {
+		return export, RepositoryError{err}
+	}
+	sessionIds := make([]uuid.UUID, 0, len(sessions))
+	for _, session := range sessions {
+		sessionIds = append(sessionIds, session.ID)
+	}
+	decisions, err := s.AccountRepository.GetSessionsDecisions(sessionIds)
+	if err != nil {
+		return export, RepositoryError{err}
+	}
+	sessionDecisions := make(map[uuid.UUID][]dtos.MatchDecisionView, len(sessions))
+	for _, decision := range decisions {
+		sessionDecisions[decision.SessionID] = append(sessionDecisions[decision.SessionID], dtos.NewMatchDecisionView(decision))
+	}
+	export.PlaySessions = make([]dtos.ExportedPlaySession, 0, len(sessions))
+	for _, session := range sessions {
+		exported := dtos.ExportedPlaySession{
+			PlaySessionView: dtos.NewPlaySessionView(session),
+			Decisions:       sessionDecisions[session.ID],
+		}
+		if exported.Decisions == nil {
+			exported.Decisions = []dtos.MatchDecisionView{}
+		}
+		export.PlaySessions = append(export.PlaySessions, exported)
+	}
+
+	tierLists, err := s.AccountRepository.GetUserTierLists(userId)
+	if err != nil {
+		return export, RepositoryError{err}
+	}
+	export.TierLists = dtos.NewTierListViews(tierLists)
+
+	identities, err := s.AccountRepository.GetUserIdentities(userId)
+	if err != nil {
+		return export, RepositoryError{err}
+	}
+	export.Identities = make([]dtos.ExportedIdentity, 0, len(identities))
+	for _, identity := range identities {
+		export.Identities = append(export.Identities, dtos.ExportedIdentity{
+			Provider:  identity.Provider,
+			Subject:   identity.Subject,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		})
+	}
+	return export, nil
+}
+
+// ExportArchive packs export into ZIP archive with JSON file for every part of export
+func (s *AccountService) ExportArchive(export dtos.AccountExport) ([]byte, error) {
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{"profile.json", export.Profile},
+		{"tournaments.json", export.Tournaments},
+		{"play_sessions.json", export.PlaySessions},
+		{"tier_lists.json", export.TierLists},
+		{"identities.json", export.Identities},
+	}
+	for _, file := range files {
+		writer, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return nil, ExportError{err}
+		}
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(file.content)
+		if err != nil {
+			return nil, ExportError{err}
+		}
+	}
+
+	err := archive.Close()
+	if err != nil {
+		return nil, ExportError{err}
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"testing"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+	"tiktok-arena/internal/core/totp"
+	"time"
+)
+
+// fakeAccountRepository keeps data of one user in memory and records deletion
+type fakeAccountRepository struct {
+	AccountServiceAccountRepository
+	deleted              bool
+	anonymiseTournaments bool
+	tournaments          []models.Tournament
+	tiktoks              []models.Tiktok
+	sessions             []models.PlaySession
+	decisions            []models.MatchDecision
+	tierLists            []models.TierList
+	identities           []models.UserIdentity
+}
+
+func (r *fakeAccountRepository) DeleteUser(_ uuid.UUID, anonymiseTournaments bool) error {
+	r.deleted, r.anonymiseTournaments = true, anonymiseTournaments
+	return nil
+}
+
+func (r *fakeAccountRepository) GetUserTournaments(uuid.UUID) ([]models.Tournament, error) {
+	return r.tournaments, nil
+}
+
+func (r *fakeAccountRepository) GetTournamentsTiktoks([]uuid.UUID) ([]models.Tiktok, error) {
+	return r.tiktoks, nil
+}
+
+func (r *fakeAccountRepository) GetUserPlaySessions(uuid.UUID) ([]models.PlaySession, error) {
+	return r.sessions, nil
+}
+
+func (r *fakeAccountRepository) GetSessionsDecisions([]uuid.UUID) ([]models.MatchDecision, error) {
+	return r.decisions, nil
+}
+
+func (r *fakeAccountRepository) GetUserTierLists(uuid.UUID) ([]models.TierList, error) {
+	return r.tierLists, nil
+}
+
+func (r *fakeAccountRepository) GetUserIdentities(uuid.UUID) ([]models.UserIdentity, error) {
+	return r.identities, nil
+}
+
+// fakeAccountUserRepository keeps one user in memory
+type fakeAccountUserRepository struct {
+	AuthServiceUserRepository
+	user models.User
+}
+
+func (r *fakeAccountUserRepository) GetUserByID(id uuid.UUID) (models.User, error) {
+	if id != r.user.ID {
+		return models.User{}, nil
+	}
+	return r.user, nil
+}
+
+func (r *fakeAccountUserRepository) UseUserTOTPCounter(_ uuid.UUID, counter int64) (bool, error) {
+	if counter <= r.user.TOTPLastCounter {
+		return false, nil
+	}
+	r.user.TOTPLastCounter = counter
+	return true, nil
+}
+
+func (r *fakeAccountUserRepository) SetUserTOTP(_ uuid.UUID, secret string, enabled bool) error {
+	r.user.TOTPSecret, r.user.TOTPEnabled = secret, enabled
+	return nil
+}
+
+// fakeAccountTokenRepository records users whose access tokens are revoked
+type fakeAccountTokenRepository struct {
+	AuthServiceTokenRepository
+	revokedUsers []uuid.UUID
+}
+
+func (r *fakeAccountTokenRepository) RevokeUserAccessTokens(userId uuid.UUID, _ time.Time, _ time.Time) error {
+	r.revokedUsers = append(r.revokedUsers, userId)
+	return nil
+}
+
+func (r *fakeAccountTokenRepository) UseRecoveryCode(uuid.UUID, string) (bool, error) {
+	return false, nil
+}
+
+func newTestAccountService(user models.User) (*AccountService, *fakeAccountRepository, *fakeAccountTokenRepository) {
+	accountRepository := &fakeAccountRepository{}
+	tokenRepository := &fakeAccountTokenRepository{}
+	authService := NewAuthService(&fakeAccountUserRepository{user: user}, tokenRepository, nil)
+	return NewAccountService(accountRepository, authService), accountRepository, tokenRepository
+}
+
+func TestDeleteAccount(t *testing.T) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	assert.Nil(t, err)
+	secret := "JBSWY3DPEHPK3PXP"
+	code, err := totp.Code(secret, totp.Counter(time.Now()))
+	assert.Nil(t, err)
+	recently, longAgo := time.Now().UTC().Add(-time.Minute), time.Now().UTC().Add(-time.Hour)
+
+	tests := []struct {
+		name  string
+		user  models.User
+		input dtos.DeleteAccount
+		err   error
+	}{
+		{
+			name:  "password",
+			user:  models.User{Password: string(hashedPassword)},
+			input: dtos.DeleteAccount{Password: "password"},
+		},
+		{
+			name:  "wrong password",
+			user:  models.User{Password: string(hashedPassword)},
+			input: dtos.DeleteAccount{Password: "wrong"},
+			err:   BcryptError{bcrypt.ErrMismatchedHashAndPassword},
+		},
+		{
+			name:  "password without required 2FA code",
+			user:  models.User{Password: string(hashedPassword), TOTPSecret: secret, TOTPEnabled: true},
+			input: dtos.DeleteAccount{Password: "password"},
+			err:   InvalidTwoFactorCodeError{},
+		},
+		{
+			name:  "password and 2FA code",
+			user:  models.User{Password: string(hashedPassword), TOTPSecret: secret, TOTPEnabled: true},
+			input: dtos.DeleteAccount{Password: "password", Code: code},
+		},
+		{
+			name: "without password after recent login with provider",
+			user: models.User{Password: models.NoPassword, ProviderLoginAt: &recently},
+		},
+		{
+			name: "without password after old login with provider",
+			user: models.User{Password: models.NoPassword, ProviderLoginAt: &longAgo},
+			err:  ReauthenticationRequiredError{MaxAge: ReauthenticationMaxAge},
+		},
+		{
+			name:  "without password with 2FA code",
+			user:  models.User{Password: models.NoPassword, TOTPSecret: secret, TOTPEnabled: true},
+			input: dtos.DeleteAccount{Code: code},
+		},
+		{
+			name:  "without password, password of another account is ignored",
+			user:  models.User{Password: models.NoPassword},
+			input: dtos.DeleteAccount{Password: "password"},
+			err:   ReauthenticationRequiredError{MaxAge: ReauthenticationMaxAge},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.user.ID = uuid.New()
+			test.input.Tournaments = dtos.TournamentsAnonymise
+			s, accountRepository, tokenRepository := newTestAccountService(test.user)
+			token := jwt.Token{Claims: jwt.MapClaims{
+				"sub": test.user.ID.String(),
+				"jti": uuid.NewString(),
+				"exp": float64(time.Now().Add(time.Hour).Unix()),
+			}}
+
+			err := s.DeleteAccount(token, test.input)
+
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.err == nil, accountRepository.deleted)
+			if test.err == nil {
+				assert.True(t, accountRepository.anonymiseTournaments)
+				assert.Equal(t, []uuid.UUID{test.user.ID}, tokenRepository.revokedUsers)
+			}
+		})
+	}
+}
+
+func TestExportAccount(t *testing.T) {
+	user := models.User{ID: uuid.New(), Name: "test", Email: "test@example.com", TOTPEnabled: true}
+	s, accountRepository, _ := newTestAccountService(user)
+	first, second := uuid.New(), uuid.New()
+	session := uuid.New()
+	accountRepository.tournaments = []models.Tournament{{ID: first, Name: "first"}, {ID: second, Name: "second"}}
+	accountRepository.tiktoks = []models.Tiktok{
+		{TournamentID: first, URL: "a", Wins: 2},
+		{TournamentID: first, URL: "b", Wins: 1},
+	}
+	accountRepository.sessions = []models.PlaySession{{ID: session}, {ID: uuid.New()}}
+	accountRepository.decisions = []models.MatchDecision{{SessionID: session, MatchID: "1"}}
+	tierList := uuid.New()
+	accountRepository.tierLists = []models.TierList{{ID: tierList, TournamentID: first, UserID: &user.ID,
+		Placements: []models.TierPlacement{{TierListID: tierList, TournamentID: first, TiktokURL: "a", Tier: "S"}}}}
+	accountRepository.identities = []models.UserIdentity{{Provider: "mock", Subject: "subject"}}
+
+	export, err := s.ExportAccount(user.ID, dtos.ExportQueries{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, dtos.ExportedProfile{
+		ID:               user.ID,
+		Name:             user.Name,
+		Email:            user.Email,
+		Role:             models.RoleUser,
+		TwoFactorEnabled: true,
+	}, export.Profile)
+	assert.Len(t, export.Tournaments, 2)
+	assert.Equal(t, []dtos.ExportedTiktok{{URL: "a", Wins: 2}, {URL: "b", Wins: 1}}, export.Tournaments[0].Tiktoks)
+	assert.NotNil(t, export.Tournaments[1].Tiktoks)
+	assert.Empty(t, export.Tournaments[1].Tiktoks)
+	assert.Len(t, export.PlaySessions, 2)
+	assert.Len(t, export.PlaySessions[0].Decisions, 1)
+	assert.NotNil(t, export.PlaySessions[1].Decisions)
+	assert.Empty(t, export.PlaySessions[1].Decisions)
+	assert.Equal(t, []dtos.TierListView{{ID: tierList, TournamentID: first,
+		Placements: []dtos.TierPlacementView{{TiktokURL: "a", Tier: "S"}}}}, export.TierLists)
+	assert.Equal(t, []dtos.ExportedIdentity{{Provider: "mock", Subject: "subject"}}, export.Identities)
+}
+
+func TestExportArchiveHasFileForEveryPart(t *testing.T) {
+	s, _, _ := newTestAccountService(models.User{})
+
+	archive, err := s.ExportArchive(dtos.AccountExport{ExportedAt: time.Now().UTC()})
+
+	assert.Nil(t, err)
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	assert.Nil(t, err)
+	names := make([]string, 0, len(reader.File))
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, []string{
+		"profile.json", "tournaments.json", "play_sessions.json", "tier_lists.json", "identities.json",
+	}, names)
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"tiktok-arena/configuration"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+	"tiktok-arena/internal/core/validator"
+	"time"
+)
+
+type AdminServiceTournamentRepository interface {
+	GetTournamentById(tournamentId uuid.UUID) (models.Tournament, error)
+	GetAllTournamentsWithUsers(queries dtos.PaginationQueries, isPrivate bool) ([]models.Tournament, error)
+	TotalTournaments(isPrivate bool) (int64, error)
+	DeleteTournamentWithStats(id uuid.UUID) error
+	ResetTournamentStats(id uuid.UUID) error
+}
+
+type AdminServiceUserRepository interface {
+	GetUserByID(id uuid.UUID) (user models.User, err error)
+	SetUserRole(id uuid.UUID, role string) (bool, error)
+	SetUserBanned(id uuid.UUID, isBanned bool) error
+}
+
+type AdminServiceTokenRepository interface {
+	RevokeUserRefreshTokens(userId uuid.UUID) error
+	RevokeUserAccessTokens(userId uuid.UUID, revokedAt time.Time, expiresAt time.Time) error
+}
+
+type AdminService struct {
+	TournamentRepository AdminServiceTournamentRepository
+	UserRepository       AdminServiceUserRepository
+	TokenRepository      AdminServiceTokenRepository
+}
+
+func NewAdminService(tournamentRepository AdminServiceTournamentRepository,
+	userRepository AdminServiceUserRepository,
+	tokenRepository AdminServiceTokenRepository) *AdminService {
+	return &AdminService{TournamentRepository: tournamentRepository, UserRepository: userRepository,
+		TokenRepository: tokenRepository}
+}
+
+// GetTournaments returns tournaments of all users including private ones
+func (s *AdminService) GetTournaments(queries dtos.PaginationQueries) (response dtos.TournamentsResponse, err error) {
+	countTournaments, err := s.TournamentRepository.TotalTournaments(true)
+	if err != nil {
+		return response, RepositoryError{err}
+	}
+	tournaments, err := s.TournamentRepository.GetAllTournamentsWithUsers(queries, true)
+	if err != nil {
+		return response, RepositoryError{err}
+	}
+	return dtos.TournamentsResponse{TournamentCount: countTournaments, Tournaments: dtos.NewTournamentViews(tournaments)}, nil
+}
+
+// DeleteTournament deletes tournament of any user with its play sessions and statistics
+func (s *AdminService) DeleteTournament(tournamentIdString string) error {
+	tournamentId, err := s.existingTournamentId(tournamentIdString)
+	if err != nil {
+		return err
+	}
+	err = s.TournamentRepository.DeleteTournamentWithStats(tournamentId)
+	if err != nil {
+		return RepositoryError{err}
+	}
+	return nil
+}
+
+// ResetTournamentStats resets statistics of tournament and its tiktoks
+func (s *AdminService) ResetTournamentStats(tournamentIdString string) error {
+	tournamentId, err := s.existingTournamentId(tournamentIdString)
+	if err != nil {
+		return err
+	}
+	err = s.TournamentRepository.ResetTournamentStats(tournamentId)
+	if err != nil {
+		return RepositoryError{err}
+	}
+	return nil
+}
+
+// BanUser bans or unbans user, only users with lower role than role of actor can be banned.
+// Access and refresh tokens of banned user are revoked, so user is logged out immediately.
+func (s *AdminService) BanUser(actorRole string, userIdString string, ban dtos.BanUser) error {
+	user, err := s.existingUser(userIdString)
+	if err != nil {
+		return err
+	}
+	if models.HasRole(user.GetRole(), actorRole) {
+		return InsufficientRoleError{Role: actorRole}
+	}
+
+	err = s.UserRepository.SetUserBanned(user.ID, ban.IsBanned)
+	if err != nil {
+		return RepositoryError{err}
+	}
+	if ban.IsBanned {
+		err = s.TokenRepository.RevokeUserRefreshTokens(user.ID)
+		if err != nil {
+			return RepositoryError{err}
+		}
+		err = s.revokeAccessTokens(user.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ChangeUserRole sets role of user, access tokens of user with old role are revoked,
+// new role is added to JWT on next login or token refresh
+func (s *AdminService) ChangeUserRole(userIdString string, change dtos.ChangeRole) error {
+	err := validator.ValidateStruct(change)
+	if err != nil {
+		return ValidateError{err}
+	}
+	user, err := s.existingUser(userIdString)
+	if err != nil {
+		return err
+	}
+	_, err = s.UserRepository.SetUserRole(user.ID, change.Role)
+	if err != nil {
+		return RepositoryError{err}
+	}
+	return s.revokeAccessTokens(user.ID)
+}
+
+// revokeAccessTokens revokes all access tokens issued to user until now
+func (s *AdminService) revokeAccessTokens(userId uuid.UUID) error {
+	now := time.Now().UTC()
+	err := s.TokenRepository.RevokeUserAccessTokens(userId, now, now.Add(configuration.EnvConfig.JwtExpiresIn))
+	if err != nil {
+		return RepositoryError{err}
+	}
+	return nil
+}
+
+func (s *AdminService) existingTournamentId(tournamentIdString string) (uuid.UUID, error) {
+	if tournamentIdString == "" {
+		return uuid.Nil, EmptyTournamentIdError{}
+	}
+	tournamentId, err := uuid.Parse(tournamentIdString)
+	if err != nil {
+		return uuid.Nil, UUIDError{err}
+	}
+	_, err = s.TournamentRepository.GetTournamentById(tournamentId)
+	if err == gorm.ErrRecordNotFound {
+		return uuid.Nil, TournamentNotExistsError{tournamentId}
+	}
+	if err != nil {
+		return uuid.Nil, RepositoryError{err}
+	}
+	return tournamentId, nil
+}
+
+func (s *AdminService) existingUser(userIdString string) (user models.User, err error) {
+	userId, err := uuid.Parse(userIdString)
+	if err != nil {
+		return user, UUIDError{err}
+	}
+	user, err = s.UserRepository.GetUserByID(userId)
+	if err != nil {
+		return user, RepositoryError{err}
+	}
+	if user.ID == uuid.Nil {
+		return user, UserNotExistsError{Username: userIdString}
+	}
+	return user, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+	"time"
+)
+
+// fakeAdminUserRepository keeps one user in memory
+type fakeAdminUserRepository struct {
+	user models.User
+}
+
+func (r *fakeAdminUserRepository) GetUserByID(id uuid.UUID) (models.User, error) {
+	if id != r.user.ID {
+		return models.User{}, nil
+	}
+	return r.user, nil
+}
+
+func (r *fakeAdminUserRepository) SetUserRole(_ uuid.UUID, role string) (bool, error) {
+	r.user.Role = role
+	return true, nil
+}
+
+func (r *fakeAdminUserRepository) SetUserBanned(_ uuid.UUID, isBanned bool) error {
+	r.user.IsBanned = isBanned
+	return nil
+}
+
+// fakeAdminTokenRepository records revoked tokens of users
+type fakeAdminTokenRepository struct {
+	revokedRefreshTokens map[uuid.UUID]bool
+	revokedAccessTokens  map[uuid.UUID]time.Time
+}
+
+func (r *fakeAdminTokenRepository) RevokeUserRefreshTokens(userId uuid.UUID) error {
+	r.revokedRefreshTokens[userId] = true
+	return nil
+}
+
+func (r *fakeAdminTokenRepository) RevokeUserAccessTokens(userId uuid.UUID, revokedAt time.Time, _ time.Time) error {
+	r.revokedAccessTokens[userId] = revokedAt
+	return nil
+}
+
+func TestAdminRevokesTokensOfUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		action         func(s *AdminService, userId string) error
+		refreshRevoked bool
+		accessRevoked  bool
+	}{
+		{
+			name: "ban revokes access and refresh tokens",
+			action: func(s *AdminService, userId string) error {
+				return s.BanUser(models.RoleAdmin, userId, dtos.BanUser{IsBanned: true})
+			},
+			refreshRevoked: true,
+			accessRevoked:  true,
+		},
+		{
+			name: "unban keeps tokens",
+			action: func(s *AdminService, userId string) error {
+				return s.BanUser(models.RoleAdmin, userId, dtos.BanUser{IsBanned: false})
+			},
+		},
+		{
+			name: "role change revokes access tokens with old role",
+			action: func(s *AdminService, userId string) error {
+				return s.ChangeUserRole(userId, dtos.ChangeRole{Role: models.RoleModerator})
+			},
+			accessRevoked: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			user := models.User{ID: uuid.New(), Name: "test", Role: models.RoleUser}
+			tokens := &fakeAdminTokenRepository{
+				revokedRefreshTokens: map[uuid.UUID]bool{},
+				revokedAccessTokens:  map[uuid.UUID]time.Time{},
+			}
+			service := NewAdminService(nil, &fakeAdminUserRepository{user: user}, tokens)
+
+			err := test.action(service, user.ID.String())
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.refreshRevoked, tokens.revokedRefreshTokens[user.ID])
+			_, accessRevoked := tokens.revokedAccessTokens[user.ID]
+			assert.Equal(t, test.accessRevoked, accessRevoked)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+	"tiktok-arena/internal/core/validator"
+	"time"
+)
+
+const (
+	// APIKeyPrefix starts every API key, so leaked keys are easy to find
+	APIKeyPrefix = "arena_"
+	// MaxAPIKeysPerUser limits keys every user can create
+	MaxAPIKeysPerUser = 20
+	// APIKeyLastUsedInterval time of last use is updated at most once per interval, not on every request
+	APIKeyLastUsedInterval = time.Minute
+)
+
+type APIKeyServiceAPIKeyRepository interface {
+	CreateAPIKey(key *models.APIKey) error
+	CountUserAPIKeys(userId uuid.UUID) (int64, error)
+	GetUserAPIKeys(userId uuid.UUID) ([]models.APIKey, error)
+	GetAPIKeyByHash(keyHash string) (models.APIKey, error)
+	UpdateAPIKeyLastUsed(id uuid.UUID, lastUsedAt time.Time) error
+	DeleteUserAPIKey(userId uuid.UUID, id uuid.UUID) (bool, error)
+}
+
+type APIKeyServiceUserRepository interface {
+	GetUserByID(id uuid.UUID) (user models.User, err error)
+}
+
+type APIKeyService struct {
+	APIKeyRepository APIKeyServiceAPIKeyRepository
+	UserRepository   APIKeyServiceUserRepository
+}
+
+func NewAPIKeyService(apiKeyRepository APIKeyServiceAPIKeyRepository,
+	userRepository APIKeyServiceUserRepository) *APIKeyService {
+	return &APIKeyService{APIKeyRepository: apiKeyRepository, UserRepository: userRepository}
+}
+
+// CreateAPIKey creates key of user with scope, key itself is returned only once
+func (s *APIKeyService) CreateAPIKey(userId uuid.UUID, input dtos.CreateAPIKey) (created dtos.CreatedAPIKey, err error) {
+	err = validator.ValidateStruct(input)
+	if err != nil {
+		return created, ValidateError{err}
+	}
+
+	count, err := s.APIKeyRepository.CountUserAPIKeys(userId)
+	if err != nil {
+		return created, RepositoryError{err}
+	}
+	if count >= MaxAPIKeysPerUser {
+		return created, APIKeyLimitError{Limit: MaxAPIKeysPerUser}
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return created, err
+	}
+	key := APIKeyPrefix + token
+
+	apiKey := models.APIKey{
+		UserID:  userId,
+		Name:    input.Name,
+		Prefix:  key[:len(APIKeyPrefix)+6],
+		KeyHash: hashToken(key),
+		Scope:   input.Scope,
+	}
+	if input.ExpiresInDays != 0 {
+		expiresAt := time.Now().UTC().AddDate(0, 0, input.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+	err = s.APIKeyRepository.CreateAPIKey(&apiKey)
+	if err != nil {
+		return created, RepositoryError{err}
+	}
+
+	return dtos.CreatedAPIKey{APIKeyView: dtos.NewAPIKeyView(apiKey), Key: key}, nil
+}
+
+func (s *APIKeyService) GetAPIKeys(userId uuid.UUID) ([]dtos.APIKeyView, error) {
+	keys, err := s.APIKeyRepository.GetUserAPIKeys(userId)
+	if err != nil {
+		return nil, RepositoryError{err}
+	}
+	return dtos.NewAPIKeyViews(keys), nil
+}
+
+// RevokeAPIKey deletes key of user, so it is not accepted anymore
+func (s *APIKeyService) RevokeAPIKey(userId uuid.UUID, keyIdString string) error {
+	keyId, err := uuid.Parse(keyIdString)
+	if err != nil {
+		return UUIDError{err}
+	}
+	deleted, err := s.APIKeyRepository.DeleteUserAPIKey(userId, keyId)
+	if err != nil {
+		return RepositoryError{err}
+	}
+	if !deleted {
+		return APIKeyNotExistsError{ID: keyIdString}
+	}
+	return nil
+}
+
+// AuthenticateAPIKey checks key and returns token with claims of its user like claims of JWT,
+// scope of key is added to claims, so routes can check it
+func (s *APIKeyService) AuthenticateAPIKey(key string) (*jwt.Token, error) {
+	apiKey, err := s.APIKeyRepository.GetAPIKeyByHash(hashToken(key))
+	if err == gorm.ErrRecordNotFound {
+		return nil, InvalidAPIKeyError{}
+	}
+	if err != nil {
+		return nil, RepositoryError{err}
+	}
+	now := time.Now().UTC()
+	if apiKey.ExpiresAt != nil && now.After(*apiKey.ExpiresAt) {
+		return nil, InvalidAPIKeyError{}
+	}
+
+	user, err := s.UserRepository.GetUserByID(apiKey.UserID)
+	if err != nil {
+		return nil, RepositoryError{err}
+	}
+	if user.ID == uuid.Nil {
+		return nil, InvalidAPIKeyError{}
+	}
+	if user.IsBanned {
+		return nil, UserBannedError{Username: user.Name}
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= APIKeyLastUsedInterval {
+		err = s.APIKeyRepository.UpdateAPIKeyLastUsed(apiKey.ID, now)
+		if err != nil {
+			return nil, RepositoryError{err}
+		}
+	}
+
+	return &jwt.Token{
+		Valid: true,
+		Claims: jwt.MapClaims{
+			"sub":   user.ID.String(),
+			"name":  user.Name,
+			"role":  user.GetRole(),
+			"scope": apiKey.Scope,
+			"key":   apiKey.ID.String(),
+		},
+	}, nil
+}
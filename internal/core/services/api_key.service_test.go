@@ -0,0 +1,190 @@
+package services
+
+import (
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+	"time"
+)
+
+// fakeAPIKeyRepository keeps keys in memory and counts updates of last use
+type fakeAPIKeyRepository struct {
+	keys             map[uuid.UUID]models.APIKey
+	countLastUpdates int
+}
+
+func (r *fakeAPIKeyRepository) CreateAPIKey(key *models.APIKey) error {
+	key.ID = uuid.New()
+	r.keys[key.ID] = *key
+	return nil
+}
+
+func (r *fakeAPIKeyRepository) CountUserAPIKeys(userId uuid.UUID) (int64, error) {
+	keys, _ := r.GetUserAPIKeys(userId)
+	return int64(len(keys)), nil
+}
+
+func (r *fakeAPIKeyRepository) GetUserAPIKeys(userId uuid.UUID) ([]models.APIKey, error) {
+	keys := make([]models.APIKey, 0)
+	for _, key := range r.keys {
+		if key.UserID == userId {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (r *fakeAPIKeyRepository) GetAPIKeyByHash(keyHash string) (models.APIKey, error) {
+	for _, key := range r.keys {
+		if key.KeyHash == keyHash {
+			return key, nil
+		}
+	}
+	return models.APIKey{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeAPIKeyRepository) UpdateAPIKeyLastUsed(id uuid.UUID, lastUsedAt time.Time) error {
+	key := r.keys[id]
+	key.LastUsedAt = &lastUsedAt
+	r.keys[id] = key
+	r.countLastUpdates++
+	return nil
+}
+
+func (r *fakeAPIKeyRepository) DeleteUserAPIKey(userId uuid.UUID, id uuid.UUID) (bool, error) {
+	key, ok := r.keys[id]
+	if !ok || key.UserID != userId {
+		return false, nil
+	}
+	delete(r.keys, id)
+	return true, nil
+}
+
+func newTestAPIKeyService(user models.User) (*APIKeyService, *fakeAPIKeyRepository) {
+	repository := &fakeAPIKeyRepository{keys: map[uuid.UUID]models.APIKey{}}
+	return NewAPIKeyService(repository, &fakeAdminUserRepository{user: user}), repository
+}
+
+func TestCreateAPIKeyStoresOnlyHash(t *testing.T) {
+	user := models.User{ID: uuid.New(), Name: "test"}
+	s, repository := newTestAPIKeyService(user)
+
+	created, err := s.CreateAPIKey(user.ID, dtos.CreateAPIKey{Name: "script", Scope: models.ScopeRead})
+
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, APIKeyPrefix))
+	assert.True(t, strings.HasPrefix(created.Key, created.Prefix))
+	stored := repository.keys[created.ID]
+	assert.Equal(t, hashToken(created.Key), stored.KeyHash)
+	assert.NotContains(t, stored.KeyHash, created.Key)
+	assert.Nil(t, stored.ExpiresAt)
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	yesterday := time.Now().UTC().AddDate(0, 0, -1)
+	tests := []struct {
+		name    string
+		scope   string
+		prepare func(s *APIKeyService, repository *fakeAPIKeyRepository, user *models.User, key dtos.CreatedAPIKey)
+		err     error
+	}{
+		{name: "valid key has claims of user and scope of key", scope: models.ScopeTournamentWrite},
+		{
+			name:  "expired key",
+			scope: models.ScopeRead,
+			prepare: func(_ *APIKeyService, repository *fakeAPIKeyRepository, _ *models.User, key dtos.CreatedAPIKey) {
+				stored := repository.keys[key.ID]
+				stored.ExpiresAt = &yesterday
+				repository.keys[key.ID] = stored
+			},
+			err: InvalidAPIKeyError{},
+		},
+		{
+			name:  "revoked key",
+			scope: models.ScopeRead,
+			prepare: func(s *APIKeyService, _ *fakeAPIKeyRepository, user *models.User, key dtos.CreatedAPIKey) {
+				assert.Nil(t, s.RevokeAPIKey(user.ID, key.ID.String()))
+			},
+			err: InvalidAPIKeyError{},
+		},
+		{
+			name:  "key of banned user",
+			scope: models.ScopeRead,
+			prepare: func(s *APIKeyService, _ *fakeAPIKeyRepository, _ *models.User, _ dtos.CreatedAPIKey) {
+				s.UserRepository.(*fakeAdminUserRepository).user.IsBanned = true
+			},
+			err: UserBannedError{Username: "test"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			user := models.User{ID: uuid.New(), Name: "test"}
+			s, repository := newTestAPIKeyService(user)
+			created, err := s.CreateAPIKey(user.ID, dtos.CreateAPIKey{Name: "script", Scope: test.scope})
+			assert.Nil(t, err)
+			if test.prepare != nil {
+				test.prepare(s, repository, &user, created)
+			}
+
+			token, err := s.AuthenticateAPIKey(created.Key)
+
+			assert.Equal(t, test.err, err)
+			if test.err == nil {
+				claims := token.Claims.(jwt.MapClaims)
+				assert.Equal(t, user.ID.String(), claims["sub"])
+				assert.Equal(t, test.scope, claims["scope"])
+			}
+		})
+	}
+}
+
+func TestAuthenticateAPIKeyWithUnknownKey(t *testing.T) {
+	s, _ := newTestAPIKeyService(models.User{ID: uuid.New()})
+
+	_, err := s.AuthenticateAPIKey(APIKeyPrefix + "unknown")
+
+	assert.Equal(t, InvalidAPIKeyError{}, err)
+}
+
+func TestAuthenticateAPIKeyUpdatesLastUseOncePerInterval(t *testing.T) {
+	user := models.User{ID: uuid.New(), Name: "test"}
+	s, repository := newTestAPIKeyService(user)
+	created, err := s.CreateAPIKey(user.ID, dtos.CreateAPIKey{Name: "script", Scope: models.ScopeRead})
+	assert.Nil(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err = s.AuthenticateAPIKey(created.Key)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 1, repository.countLastUpdates)
+
+	lastUsedAt := time.Now().UTC().Add(-APIKeyLastUsedInterval)
+	stored := repository.keys[created.ID]
+	stored.LastUsedAt = &lastUsedAt
+	repository.keys[created.ID] = stored
+	_, err = s.AuthenticateAPIKey(created.Key)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, repository.countLastUpdates)
+}
+
+func TestAPIKeyScope(t *testing.T) {
+	tests := []struct {
+		scope    string
+		required string
+		allowed  bool
+	}{
+		{scope: models.ScopeRead, required: models.ScopeRead, allowed: true},
+		{scope: models.ScopeRead, required: models.ScopeTournamentWrite, allowed: false},
+		{scope: models.ScopeTournamentWrite, required: models.ScopeRead, allowed: true},
+		{scope: models.ScopeTournamentWrite, required: models.ScopeTournamentWrite, allowed: true},
+		{scope: "unknown", required: models.ScopeRead, allowed: false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.allowed, models.HasScope(test.scope, test.required), "%s for %s", test.scope, test.required)
+	}
+}
@@ -1,11 +1,18 @@
 package services
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 	"tiktok-arena/configuration"
 	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/keys"
 	"tiktok-arena/internal/core/models"
 	"tiktok-arena/internal/core/validator"
 	"time"
@@ -16,14 +23,54 @@ type AuthServiceUserRepository interface {
 	UserExists(username string) (bool, error)
 	CreateUser(newUser *models.User) error
 	GetUserPhoto(id string) (string, error)
+	GetUserByID(id uuid.UUID) (user models.User, err error)
+	UpdateUserPassword(id uuid.UUID, password string) error
+	SetUserProviderLoginAt(id uuid.UUID, at time.Time) error
+	UpdateUserFailedLogins(id uuid.UUID, failedLogins int, lockedUntil *time.Time) error
+	IncrementUserFailedLogins(id uuid.UUID, now time.Time, maxAttempts int,
+		lockout time.Duration, maxLockout time.Duration) (*time.Time, error)
+	SetUserTOTP(id uuid.UUID, secret string, enabled bool) error
+	EnableUserTOTP(id uuid.UUID, secret string, counter int64, codes []models.RecoveryCode) (bool, error)
+	UseUserTOTPCounter(id uuid.UUID, counter int64) (bool, error)
+}
+
+type AuthServiceTokenRepository interface {
+	CreateRefreshToken(token *models.RefreshToken) error
+	GetRefreshTokenByHash(tokenHash string) (models.RefreshToken, error)
+	RotateRefreshToken(old models.RefreshToken, new *models.RefreshToken) (bool, error)
+	RevokeRefreshTokenFamily(familyId uuid.UUID) error
+	RevokeRefreshToken(userId uuid.UUID, tokenHash string) error
+	RevokeUserRefreshTokens(userId uuid.UUID) error
+	RevokeAccessToken(token models.RevokedToken) error
+	IsAccessTokenRevoked(jti string) (bool, error)
+	RevokeUserAccessTokens(userId uuid.UUID, revokedAt time.Time, expiresAt time.Time) error
+	IsUserAccessTokenRevoked(userId uuid.UUID, issuedAt time.Time) (bool, error)
+	CreatePasswordResetToken(token *models.PasswordResetToken) error
+	ResetPassword(tokenHash string, password string, revokedAt time.Time, expiresAt time.Time) (bool, error)
+	CreateEmailChangeToken(token *models.EmailChangeToken) error
+	ChangeEmail(tokenHash string) (bool, error)
+	ReplaceRecoveryCodes(userId uuid.UUID, codes []models.RecoveryCode) error
+	UseRecoveryCode(userId uuid.UUID, codeHash string) (bool, error)
+	DeleteRecoveryCodes(userId uuid.UUID) error
+	CreateTwoFactorChallenge(challenge *models.TwoFactorChallenge) error
+	UseTwoFactorChallengeAttempt(tokenHash string, now time.Time, maxAttempts int) (models.TwoFactorChallenge, bool, error)
+	DeleteTwoFactorChallenge(tokenHash string) (bool, error)
+}
+
+// Mailer delivers mails to users
+type Mailer interface {
+	Send(to string, subject string, body string) error
 }
 
 type AuthService struct {
-	UserRepository AuthServiceUserRepository
+	UserRepository  AuthServiceUserRepository
+	TokenRepository AuthServiceTokenRepository
+	Mailer          Mailer
 }
 
-func NewAuthService(userRepository AuthServiceUserRepository) *AuthService {
-	return &AuthService{UserRepository: userRepository}
+func NewAuthService(userRepository AuthServiceUserRepository, tokenRepository AuthServiceTokenRepository,
+	mailer Mailer) *AuthService {
+	return &AuthService{UserRepository: userRepository, TokenRepository: tokenRepository, Mailer: mailer}
 }
 
 func (s *AuthService) NewUser(auth dtos.AuthInput) (details dtos.RegisterDetails, err error) {
@@ -39,32 +86,40 @@ func (s *AuthService) NewUser(auth dtos.AuthInput) (details dtos.RegisterDetails
 	if err != nil {
 		return details, RepositoryError{err}
 	}
-	if exists {
+	if exists || models.IsReservedName(auth.Name) {
 		return details, UserAlreadyExistsError{auth.Name}
 	}
 
 	newUser := models.User{
 		Name:     auth.Name,
 		Password: string(hashedPassword),
+		Email:    auth.Email,
+		Role:     models.RoleUser,
 	}
 	err = s.UserRepository.CreateUser(&newUser)
 	if err != nil {
 		return details, RepositoryError{err}
 	}
 
-	token, err := UserJwtToken(newUser.ID, newUser.Name)
+	token, err := UserJwtToken(newUser.ID, newUser.Name, newUser.GetRole())
 	if err != nil {
 		return details, JWTGenerateError{err}
 	}
 
+	refreshToken, err := s.newRefreshToken(newUser.ID)
+	if err != nil {
+		return details, err
+	}
+
 	return dtos.RegisterDetails{
-		ID:    newUser.ID,
-		Name:  newUser.Name,
-		Token: token,
+		ID:           newUser.ID,
+		Name:         newUser.Name,
+		Token:        token,
+		RefreshToken: refreshToken,
 	}, err
 }
 
-func (s *AuthService) GetUserByNameAndPassword(input dtos.AuthInput) (details dtos.LoginDetails, err error) {
+func (s *AuthService) GetUserByNameAndPassword(input dtos.LoginInput) (details dtos.LoginDetails, err error) {
 	err = validator.ValidateStruct(input)
 	if err != nil {
 		return details, ValidateError{err}
@@ -75,26 +130,448 @@ func (s *AuthService) GetUserByNameAndPassword(input dtos.AuthInput) (details dt
 		return details, RepositoryError{err}
 	}
 
+	now := time.Now().UTC()
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		return details, LoginLockedError{Username: user.Name, RetryAfter: user.LockedUntil.Sub(now)}
+	}
+
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password))
 	if err != nil {
+		lockErr := s.recordFailedLogin(user, now)
+		if lockErr != nil {
+			return details, lockErr
+		}
 		return details, BcryptError{err}
 	}
+	// Failed logins of user with 2FA are reset when code is checked, so failed codes count too
+	if user.FailedLogins != 0 && !user.TOTPEnabled {
+		err = s.UserRepository.UpdateUserFailedLogins(user.ID, 0, nil)
+		if err != nil {
+			return details, RepositoryError{err}
+		}
+	}
+	if user.IsBanned {
+		return details, UserBannedError{Username: user.Name}
+	}
+
+	return s.loginOrChallenge(user)
+}
 
-	token, err := UserJwtToken(user.ID, user.Name)
+// loginDetails issues access token and refresh token of new token family to user who logged in
+func (s *AuthService) loginDetails(user models.User) (details dtos.LoginDetails, err error) {
+	token, err := UserJwtToken(user.ID, user.Name, user.GetRole())
 
 	if err != nil {
 		return details, JWTGenerateError{err}
 	}
 
+	refreshToken, err := s.newRefreshToken(user.ID)
+	if err != nil {
+		return details, err
+	}
+
 	return dtos.LoginDetails{
-		ID:       user.ID,
-		Name:     user.Name,
-		Token:    token,
-		PhotoURL: user.PhotoURL,
+		ID:           user.ID,
+		Name:         user.Name,
+		Token:        token,
+		RefreshToken: refreshToken,
+		PhotoURL:     user.PhotoURL,
 	}, err
 
 }
 
+// recordFailedLogin counts failed login of user and locks login after too many failed logins,
+// lockout is doubled for every next failed login up to max lockout, LoginLockedError is returned if login is locked.
+// Failed logins are counted in database, so concurrent attempts can't bypass lockout.
+func (s *AuthService) recordFailedLogin(user models.User, now time.Time) error {
+	lockedUntil, err := s.UserRepository.IncrementUserFailedLogins(user.ID, now,
+		configuration.EnvConfig.LoginMaxAttempts, configuration.EnvConfig.LoginLockout, configuration.EnvConfig.LoginMaxLockout)
+	if err != nil {
+		return RepositoryError{err}
+	}
+	if lockedUntil != nil && now.Before(*lockedUntil) {
+		return LoginLockedError{Username: user.Name, RetryAfter: lockedUntil.Sub(now)}
+	}
+	return nil
+}
+
+// RefreshToken rotates refresh token and issues new access token,
+// reuse of already rotated refresh token revokes all tokens rotated from the same login
+func (s *AuthService) RefreshToken(input dtos.RefreshInput) (details dtos.TokenDetails, err error) {
+	err = validator.ValidateStruct(input)
+	if err != nil {
+		return details, ValidateError{err}
+	}
+
+	old, err := s.TokenRepository.GetRefreshTokenByHash(hashToken(input.RefreshToken))
+	if err == gorm.ErrRecordNotFound {
+		return details, InvalidRefreshTokenError{}
+	}
+	if err != nil {
+		return details, RepositoryError{err}
+	}
+	if old.IsRevoked {
+		err = s.TokenRepository.RevokeRefreshTokenFamily(old.FamilyID)
+		if err != nil {
+			return details, RepositoryError{err}
+		}
+		return details, InvalidRefreshTokenError{}
+	}
+	if time.Now().UTC().After(old.ExpiresAt) {
+		return details, InvalidRefreshTokenError{}
+	}
+
+	user, err := s.UserRepository.GetUserByID(old.UserID)
+	if err != nil {
+		return details, RepositoryError{err}
+	}
+	if user.ID == uuid.Nil {
+		return details, InvalidRefreshTokenError{}
+	}
+	if user.IsBanned {
+		return details, UserBannedError{Username: user.Name}
+	}
+
+	refreshToken, newToken, err := newRefreshTokenModel(user.ID, old.FamilyID)
+	if err != nil {
+		return details, err
+	}
+	rotated, err := s.TokenRepository.RotateRefreshToken(old, &newToken)
+	if err != nil {
+		return details, RepositoryError{err}
+	}
+	if !rotated {
+		return details, InvalidRefreshTokenError{}
+	}
+
+	token, err := UserJwtToken(user.ID, user.Name, user.GetRole())
+	if err != nil {
+		return details, JWTGenerateError{err}
+	}
+
+	return dtos.TokenDetails{
+		Token:        token,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// Logout revokes access token and refresh token of input or all refresh tokens of user
+func (s *AuthService) Logout(token jwt.Token, input dtos.LogoutInput) error {
+	claims := token.Claims.(jwt.MapClaims)
+
+	userId, err := uuid.Parse(claims["sub"].(string))
+	if err != nil {
+		return UUIDError{err}
+	}
+
+	err = s.revokeAccessToken(claims)
+	if err != nil {
+		return err
+	}
+
+	if input.All {
+		err = s.TokenRepository.RevokeUserRefreshTokens(userId)
+	} else if input.RefreshToken != "" {
+		err = s.TokenRepository.RevokeRefreshToken(userId, hashToken(input.RefreshToken))
+	}
+	if err != nil {
+		return RepositoryError{err}
+	}
+	return nil
+}
+
+// ChangePassword sets new password of user of token after old password (or recent login through provider) is checked,
+// token and all access and refresh tokens of user are revoked, so every device has to login again,
+// new tokens are returned for current device
+func (s *AuthService) ChangePassword(token jwt.Token, input dtos.ChangePasswordInput) (details dtos.TokenDetails, err error) {
+	err = validator.ValidateStruct(input)
+	if err != nil {
+		return details, ValidateError{err}
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	userId, err := uuid.Parse(claims["sub"].(string))
+	if err != nil {
+		return details, UUIDError{err}
+	}
+	user, err := s.existingUser(userId)
+	if err != nil {
+		return details, err
+	}
+
+	err = reauthenticate(user, input.OldPassword)
+	if err != nil {
+		return details, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return details, BcryptError{err}
+	}
+	err = s.UserRepository.UpdateUserPassword(userId, string(hashedPassword))
+	if err != nil {
+		return details, RepositoryError{err}
+	}
+
+	err = s.TokenRepository.RevokeUserRefreshTokens(userId)
+	if err != nil {
+		return details, RepositoryError{err}
+	}
+	err = s.revokeAccessToken(claims)
+	if err != nil {
+		return details, err
+	}
+	err = s.revokeUserAccessTokensBeforeNewToken(userId)
+	if err != nil {
+		return details, err
+	}
+
+	accessToken, err := UserJwtToken(user.ID, user.Name, user.GetRole())
+	if err != nil {
+		return details, JWTGenerateError{err}
+	}
+	refreshToken, err := s.newRefreshToken(user.ID)
+	if err != nil {
+		return details, err
+	}
+	return dtos.TokenDetails{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// RequestEmailChange sends one-time confirmation token to new email after password (or recent login through provider)
+// and 2FA code of user are checked,
+// email of user is changed only when token is confirmed, so only verified emails receive password reset tokens
+func (s *AuthService) RequestEmailChange(userId uuid.UUID, input dtos.ChangeEmailInput) error {
+	err := validator.ValidateStruct(input)
+	if err != nil {
+		return ValidateError{err}
+	}
+
+	user, err := s.existingUser(userId)
+	if err != nil {
+		return err
+	}
+	err = reauthenticate(user, input.Password)
+	if err != nil {
+		return err
+	}
+	if user.TOTPEnabled {
+		if input.Code == "" {
+			return InvalidTwoFactorCodeError{}
+		}
+		valid, err := s.checkTwoFactorCode(user, input.Code)
+		if err != nil {
+			return err
+		}
+		if !valid {
+			return InvalidTwoFactorCodeError{}
+		}
+	}
+
+	changeToken, err := randomToken()
+	if err != nil {
+		return err
+	}
+	err = s.TokenRepository.CreateEmailChangeToken(&models.EmailChangeToken{
+		UserID:    user.ID,
+		Email:     input.Email,
+		TokenHash: hashToken(changeToken),
+		ExpiresAt: time.Now().UTC().Add(configuration.EnvConfig.EmailChangeExpiresIn),
+	})
+	if err != nil {
+		return RepositoryError{err}
+	}
+
+	body := fmt.Sprintf("Token to confirm email of %s: %s", user.Name, changeToken)
+	if configuration.EnvConfig.EmailChangeURL != "" {
+		body = fmt.Sprintf("Confirm email of %s: %s?token=%s",
+			user.Name, configuration.EnvConfig.EmailChangeURL, changeToken)
+	}
+	err = s.Mailer.Send(input.Email, "Email confirmation", body)
+	if err != nil {
+		return MailError{err}
+	}
+	return nil
+}
+
+// ConfirmEmailChange sets new email of user with one-time email change token
+func (s *AuthService) ConfirmEmailChange(input dtos.ConfirmEmailChange) error {
+	err := validator.ValidateStruct(input)
+	if err != nil {
+		return ValidateError{err}
+	}
+
+	changed, err := s.TokenRepository.ChangeEmail(hashToken(input.Token))
+	if err != nil {
+		return RepositoryError{err}
+	}
+	if !changed {
+		return InvalidEmailChangeTokenError{}
+	}
+	return nil
+}
+
+// RequestPasswordReset sends one-time password reset token to email of user,
+// nothing is sent if user doesn't exist or has no email, but no error is returned to not expose users
+func (s *AuthService) RequestPasswordReset(input dtos.PasswordResetRequest) error {
+	err := validator.ValidateStruct(input)
+	if err != nil {
+		return ValidateError{err}
+	}
+
+	user, err := s.UserRepository.GetUserByName(input.Name)
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return RepositoryError{err}
+	}
+	if user.Email == "" {
+		return nil
+	}
+
+	resetToken, err := randomToken()
+	if err != nil {
+		return err
+	}
+	err = s.TokenRepository.CreatePasswordResetToken(&models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(resetToken),
+		ExpiresAt: time.Now().UTC().Add(configuration.EnvConfig.PasswordResetExpiresIn),
+	})
+	if err != nil {
+		return RepositoryError{err}
+	}
+
+	body := fmt.Sprintf("Token to reset password of %s: %s", user.Name, resetToken)
+	if configuration.EnvConfig.PasswordResetURL != "" {
+		body = fmt.Sprintf("Reset password of %s: %s?token=%s",
+			user.Name, configuration.EnvConfig.PasswordResetURL, resetToken)
+	}
+	err = s.Mailer.Send(user.Email, "Password reset", body)
+	if err != nil {
+		return MailError{err}
+	}
+	return nil
+}
+
+// ResetPassword sets new password with one-time reset token, all refresh and access tokens of user are revoked
+func (s *AuthService) ResetPassword(input dtos.PasswordReset) error {
+	err := validator.ValidateStruct(input)
+	if err != nil {
+		return ValidateError{err}
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return BcryptError{err}
+	}
+	now := time.Now().UTC()
+	reset, err := s.TokenRepository.ResetPassword(hashToken(input.Token), string(hashedPassword),
+		now, now.Add(configuration.EnvConfig.JwtExpiresIn))
+	if err != nil {
+		return RepositoryError{err}
+	}
+	if !reset {
+		return InvalidPasswordResetTokenError{}
+	}
+	return nil
+}
+
+// revokeAccessToken adds access token with claims to revocation list until it expires
+func (s *AuthService) revokeAccessToken(claims jwt.MapClaims) error {
+	jti, _ := claims["jti"].(string)
+	exp, _ := claims["exp"].(float64)
+
+	err := s.TokenRepository.RevokeAccessToken(models.RevokedToken{
+		JTI:       jti,
+		ExpiresAt: time.Unix(int64(exp), 0).UTC(),
+	})
+	if err != nil {
+		return RepositoryError{err}
+	}
+	return nil
+}
+
+// revokeUserAccessTokensBeforeNewToken revokes all access tokens issued to user before current second,
+// issued at of JWT has precision of seconds, so new token issued right after revocation isn't revoked with them
+func (s *AuthService) revokeUserAccessTokensBeforeNewToken(userId uuid.UUID) error {
+	now := time.Now().UTC()
+	err := s.TokenRepository.RevokeUserAccessTokens(userId, now.Truncate(time.Second).Add(-time.Second),
+		now.Add(configuration.EnvConfig.JwtExpiresIn))
+	if err != nil {
+		return RepositoryError{err}
+	}
+	return nil
+}
+
+// IsAccessTokenRevoked checks access token with claims against revocation list,
+// token is revoked by its jti or with all access tokens of its user (on ban or role change)
+func (s *AuthService) IsAccessTokenRevoked(claims jwt.MapClaims) (bool, error) {
+	jti, _ := claims["jti"].(string)
+	revoked, err := s.TokenRepository.IsAccessTokenRevoked(jti)
+	if err != nil || revoked {
+		return true, err
+	}
+
+	sub, _ := claims["sub"].(string)
+	userId, err := uuid.Parse(sub)
+	if err != nil {
+		return true, nil
+	}
+	iat, _ := claims["iat"].(float64)
+	revoked, err = s.TokenRepository.IsUserAccessTokenRevoked(userId, time.Unix(int64(iat), 0).UTC())
+	if err != nil {
+		return true, RepositoryError{err}
+	}
+	return revoked, nil
+}
+
+// newRefreshToken creates refresh token of new token family for user
+func (s *AuthService) newRefreshToken(userId uuid.UUID) (string, error) {
+	refreshToken, token, err := newRefreshTokenModel(userId, uuid.New())
+	if err != nil {
+		return "", err
+	}
+	err = s.TokenRepository.CreateRefreshToken(&token)
+	if err != nil {
+		return "", RepositoryError{err}
+	}
+	return refreshToken, nil
+}
+
+// newRefreshTokenModel generates random refresh token and its model with hash of token
+func newRefreshTokenModel(userId uuid.UUID, familyId uuid.UUID) (string, models.RefreshToken, error) {
+	refreshToken, err := randomToken()
+	if err != nil {
+		return "", models.RefreshToken{}, err
+	}
+
+	return refreshToken, models.RefreshToken{
+		UserID:    userId,
+		FamilyID:  familyId,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().UTC().Add(configuration.EnvConfig.JwtRefreshExpiresIn),
+	}, nil
+}
+
+// randomToken generates opaque token, only its hash is stored
+func randomToken() (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", JWTGenerateError{err}
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
 func (s *AuthService) WhoAmI(token jwt.Token) (whoami dtos.WhoAmI, err error) {
 	claims := token.Claims.(jwt.MapClaims)
 
@@ -120,18 +597,32 @@ func (s *AuthService) WhoAmI(token jwt.Token) (whoami dtos.WhoAmI, err error) {
 	}, err
 }
 
-func UserJwtToken(id uuid.UUID, name string) (string, error) {
+func UserJwtToken(id uuid.UUID, name string, role string) (string, error) {
 
 	now := time.Now().UTC()
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	keySet, err := keys.Get()
+	if err != nil {
+		return "", err
+	}
+
+	return keySet.Sign(jwt.MapClaims{
 		"sub":  id.String(),
 		"name": name,
+		"role": role,
+		"jti":  uuid.NewString(),
 		"exp":  now.Add(configuration.EnvConfig.JwtExpiresIn).Unix(),
 		"iat":  now.Unix(),
 		"nbf":  now.Unix(),
 	})
 
-	return token.SignedString([]byte(configuration.EnvConfig.JwtSecret))
+}
 
+// JWKS returns public keys which verify JWT
+func (s *AuthService) JWKS() (jwks dtos.JWKS, err error) {
+	keySet, err := keys.Get()
+	if err != nil {
+		return jwks, JWTGenerateError{err}
+	}
+	return keySet.JWKS(), nil
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -16,9 +17,14 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"tiktok-arena/configuration"
 	"tiktok-arena/internal/api/controllers"
 	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+	"tiktok-arena/internal/core/totp"
+	"tiktok-arena/internal/data/mailer"
 	"tiktok-arena/internal/data/repository"
+	"time"
 )
 
 type AuthSuite struct {
@@ -53,7 +59,8 @@ func (as *AuthSuite) SetupTest() {
 	database, err := gorm.Open(dialector)
 	assert.Nil(as.T(), err)
 	userRepository := repository.NewUserRepository(database)
-	authService := NewAuthService(userRepository)
+	tokenRepository := repository.NewTokenRepository(database)
+	authService := NewAuthService(userRepository, tokenRepository, mailer.NewLogMailer(""))
 	as.controller = controllers.NewAuthController(authService)
 	app := fiber.New(fiber.Config{})
 	as.app = app
@@ -62,7 +69,7 @@ func (as *AuthSuite) SetupTest() {
 }
 
 func (as *AuthSuite) TestNewUser() {
-	newUser := dtos.AuthInput{Name: "test", Password: "test"}
+	newUser := dtos.AuthInput{Name: "test", Password: "testpassword"}
 	rows := sqlmock.NewRows([]string{"id"}).AddRow(nil)
 	as.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "users" WHERE name = $1 ORDER BY "users"."id" LIMIT 1`)).
 		WithArgs(newUser.Name).
@@ -70,10 +77,11 @@ func (as *AuthSuite) TestNewUser() {
 	as.mock.ExpectBegin()
 	id, _ := uuid.NewUUID()
 	rows = sqlmock.NewRows([]string{"id", "name", "password"}).AddRow(id, newUser.Name, newUser.Password)
-	as.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users" ("photo_url","name","password") VALUES ($1,$2,$3) RETURNING "id","name","password"`)).
-		WithArgs(sqlmock.AnyArg(), newUser.Name, sqlmock.AnyArg()).
+	as.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users" ("photo_url","email","role","is_banned","failed_logins","locked_until","totp_secret","totp_enabled","totp_last_counter","provider_login_at","name","password") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) RETURNING "id","name","password"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), models.RoleUser, false, 0, nil, "", false, 0, nil, newUser.Name, sqlmock.AnyArg()).
 		WillReturnRows(rows)
 	as.mock.ExpectCommit()
+	as.expectCreateRefreshToken(id)
 	as.app.Post("/register", as.controller.RegisterUser)
 
 	body, err := json.Marshal(newUser)
@@ -95,6 +103,44 @@ func (as *AuthSuite) TestNewUser() {
 	assert.Nil(as.T(), err)
 }
 
+func (as *AuthSuite) TestNewUserWithWeakPassword() {
+	as.app.Post("/register", as.controller.RegisterUser)
+
+	body, err := json.Marshal(dtos.AuthInput{Name: "test", Password: "test"})
+	if err != nil {
+		assert.Error(as.T(), err)
+	}
+	req := httptest.NewRequest("POST", "http://localhost:8000/register", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := as.app.Test(req)
+	assert.Nil(as.T(), err)
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	assert.NotEqual(as.T(), resp.StatusCode, fiber.StatusCreated)
+	assert.Contains(as.T(), string(bodyBytes), "at least 8 characters")
+	assert.Nil(as.T(), as.mock.ExpectationsWereMet())
+}
+
+func (as *AuthSuite) TestNewUserWithReservedName() {
+	newUser := dtos.AuthInput{Name: models.DeletedUserName, Password: "testpassword"}
+	as.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "users" WHERE name = $1 ORDER BY "users"."id" LIMIT 1`)).
+		WithArgs(newUser.Name).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	as.app.Post("/register", as.controller.RegisterUser)
+
+	body, err := json.Marshal(newUser)
+	assert.Nil(as.T(), err)
+	req := httptest.NewRequest("POST", "http://localhost:8000/register", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := as.app.Test(req)
+	assert.Nil(as.T(), err)
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	assert.NotEqual(as.T(), resp.StatusCode, fiber.StatusCreated)
+	assert.Contains(as.T(), string(bodyBytes), "already exists")
+	assert.Nil(as.T(), as.mock.ExpectationsWereMet())
+}
+
 func (as *AuthSuite) TestGetUsernameAndPassword() {
 	newUser := dtos.AuthInput{Name: "test", Password: "test"}
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newUser.Password), bcrypt.DefaultCost)
@@ -109,6 +155,7 @@ func (as *AuthSuite) TestGetUsernameAndPassword() {
 	as.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE name = $1 ORDER BY "users"."id" LIMIT 1`)).
 		WithArgs(newUser.Name).
 		WillReturnRows(rows)
+	as.expectCreateRefreshToken(id)
 
 	as.app.Post("/login", as.controller.LoginUser)
 
@@ -127,7 +174,414 @@ func (as *AuthSuite) TestGetUsernameAndPassword() {
 	bodyBytes, _ := io.ReadAll(resp.Body)
 	assert.Equal(as.T(), resp.StatusCode, fiber.StatusOK)
 	assert.Contains(as.T(), string(bodyBytes), newUser.Name)
+	assert.Contains(as.T(), string(bodyBytes), "refreshToken")
+	assert.NotContains(as.T(), string(bodyBytes), string(hashedPassword))
+	assert.Nil(as.T(), err)
+}
+
+func (as *AuthSuite) TestLoginBannedUser() {
+	newUser := dtos.LoginInput{Name: "test", Password: "test"}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newUser.Password), bcrypt.DefaultCost)
+	if err != nil {
+		assert.Error(as.T(), err)
+	}
+	rows := sqlmock.NewRows([]string{"id", "name", "password", "role", "is_banned"}).
+		AddRow(uuid.New(), newUser.Name, string(hashedPassword), models.RoleUser, true)
+	as.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE name = $1 ORDER BY "users"."id" LIMIT 1`)).
+		WithArgs(newUser.Name).
+		WillReturnRows(rows)
+	as.app.Post("/login", as.controller.LoginUser)
+
+	body, err := json.Marshal(newUser)
+	if err != nil {
+		assert.Error(as.T(), err)
+	}
+	req := httptest.NewRequest("POST", "http://localhost:8000/login", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := as.app.Test(req)
 	assert.Nil(as.T(), err)
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	assert.NotEqual(as.T(), resp.StatusCode, fiber.StatusOK)
+	assert.NotContains(as.T(), string(bodyBytes), "refreshToken")
+	assert.Nil(as.T(), as.mock.ExpectationsWereMet())
+}
+
+func (as *AuthSuite) TestLoginLockedUser() {
+	newUser := dtos.LoginInput{Name: "test", Password: "test"}
+	lockedUntil := time.Now().UTC().Add(time.Minute)
+	rows := sqlmock.NewRows([]string{"id", "name", "password", "failed_logins", "locked_until"}).
+		AddRow(uuid.New(), newUser.Name, "hash", 5, lockedUntil)
+	as.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE name = $1 ORDER BY "users"."id" LIMIT 1`)).
+		WithArgs(newUser.Name).
+		WillReturnRows(rows)
+	as.app.Post("/login", as.controller.LoginUser)
+
+	body, err := json.Marshal(newUser)
+	if err != nil {
+		assert.Error(as.T(), err)
+	}
+	req := httptest.NewRequest("POST", "http://localhost:8000/login", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := as.app.Test(req)
+	assert.Nil(as.T(), err)
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	assert.NotEqual(as.T(), resp.StatusCode, fiber.StatusOK)
+	assert.Contains(as.T(), string(bodyBytes), "is locked")
+	assert.Nil(as.T(), as.mock.ExpectationsWereMet())
+}
+
+func (as *AuthSuite) TestLoginWithWrongPasswordLocksUser() {
+	maxAttempts, lockout := configuration.EnvConfig.LoginMaxAttempts, configuration.EnvConfig.LoginLockout
+	configuration.EnvConfig.LoginMaxAttempts, configuration.EnvConfig.LoginLockout = 5, time.Minute
+	defer func() {
+		configuration.EnvConfig.LoginMaxAttempts, configuration.EnvConfig.LoginLockout = maxAttempts, lockout
+	}()
+	newUser := dtos.LoginInput{Name: "test", Password: "wrong"}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("testpassword"), bcrypt.DefaultCost)
+	if err != nil {
+		assert.Error(as.T(), err)
+	}
+	id := uuid.New()
+	rows := sqlmock.NewRows([]string{"id", "name", "password", "failed_logins"}).
+		AddRow(id, newUser.Name, string(hashedPassword), 4)
+	as.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE name = $1 ORDER BY "users"."id" LIMIT 1`)).
+		WithArgs(newUser.Name).
+		WillReturnRows(rows)
+	as.mock.ExpectBegin()
+	as.mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "users" SET "failed_logins"=failed_logins + 1,"locked_until"=CASE WHEN failed_logins + 1 >= $1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"locked_until"}).AddRow(time.Now().Add(time.Minute)))
+	as.mock.ExpectCommit()
+	as.app.Post("/login", as.controller.LoginUser)
+
+	body, err := json.Marshal(newUser)
+	if err != nil {
+		assert.Error(as.T(), err)
+	}
+	req := httptest.NewRequest("POST", "http://localhost:8000/login", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := as.app.Test(req)
+	assert.Nil(as.T(), err)
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	assert.NotEqual(as.T(), resp.StatusCode, fiber.StatusOK)
+	assert.Contains(as.T(), string(bodyBytes), "is locked")
+	assert.Nil(as.T(), as.mock.ExpectationsWereMet())
+}
+
+func (as *AuthSuite) TestLoginWithTwoFactorReturnsChallenge() {
+	newUser := dtos.LoginInput{Name: "test", Password: "test"}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newUser.Password), bcrypt.DefaultCost)
+	if err != nil {
+		assert.Error(as.T(), err)
+	}
+	id := uuid.New()
+	rows := sqlmock.NewRows([]string{"id", "name", "password", "totp_secret", "totp_enabled"}).
+		AddRow(id, newUser.Name, string(hashedPassword), "JBSWY3DPEHPK3PXP", true)
+	as.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE name = $1 ORDER BY "users"."id" LIMIT 1`)).
+		WithArgs(newUser.Name).
+		WillReturnRows(rows)
+	as.mock.ExpectBegin()
+	as.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "two_factor_challenges"`)).
+		WithArgs(sqlmock.AnyArg(), id, 0, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	as.mock.ExpectCommit()
+	as.mock.ExpectBegin()
+	as.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "two_factor_challenges" WHERE expires_at < $1`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	as.mock.ExpectCommit()
+	as.app.Post("/login", as.controller.LoginUser)
+
+	body, err := json.Marshal(newUser)
+	if err != nil {
+		assert.Error(as.T(), err)
+	}
+	req := httptest.NewRequest("POST", "http://localhost:8000/login", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := as.app.Test(req)
+	assert.Nil(as.T(), err)
+	var details dtos.LoginDetails
+	assert.Nil(as.T(), json.NewDecoder(resp.Body).Decode(&details))
+	assert.Equal(as.T(), resp.StatusCode, fiber.StatusOK)
+	assert.True(as.T(), details.TwoFactorRequired)
+	assert.NotEmpty(as.T(), details.ChallengeToken)
+	assert.Empty(as.T(), details.Token)
+	assert.Empty(as.T(), details.RefreshToken)
+	assert.Nil(as.T(), as.mock.ExpectationsWereMet())
+}
+
+func (as *AuthSuite) TestLoginTwoFactorWithWrongCodeLocksUser() {
+	maxAttempts, lockout := configuration.EnvConfig.LoginMaxAttempts, configuration.EnvConfig.LoginLockout
+	configuration.EnvConfig.LoginMaxAttempts, configuration.EnvConfig.LoginLockout = 5, time.Minute
+	defer func() {
+		configuration.EnvConfig.LoginMaxAttempts, configuration.EnvConfig.LoginLockout = maxAttempts, lockout
+	}()
+	input := dtos.TwoFactorLogin{ChallengeToken: "challenge", Code: "abcdef"}
+	id := uuid.New()
+	as.mock.ExpectBegin()
+	as.mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "two_factor_challenges" SET "attempts"=attempts + 1 WHERE token_hash = $1 AND attempts < $2 AND expires_at > $3 RETURNING *`)).
+		WithArgs(hashToken(input.ChallengeToken), MaxTwoFactorAttempts, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"token_hash", "user_id", "attempts", "expires_at"}).
+			AddRow(hashToken(input.ChallengeToken), id, 1, time.Now().Add(time.Minute)))
+	as.mock.ExpectCommit()
+	rows := sqlmock.NewRows([]string{"id", "name", "password", "failed_logins", "totp_secret", "totp_enabled"}).
+		AddRow(id, "test", "hash", 4, "JBSWY3DPEHPK3PXP", true)
+	as.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE id = $1`)).
+		WithArgs(id).
+		WillReturnRows(rows)
+	as.mock.ExpectBegin()
+	as.mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "users" SET "failed_logins"=failed_logins + 1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"locked_until"}).AddRow(time.Now().Add(time.Minute)))
+	as.mock.ExpectCommit()
+	as.app.Post("/2fa/login", as.controller.LoginTwoFactor)
+
+	body, err := json.Marshal(input)
+	assert.Nil(as.T(), err)
+	req := httptest.NewRequest("POST", "http://localhost:8000/2fa/login", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := as.app.Test(req)
+	assert.Nil(as.T(), err)
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	assert.NotEqual(as.T(), resp.StatusCode, fiber.StatusOK)
+	assert.Contains(as.T(), string(bodyBytes), "is locked")
+	assert.Nil(as.T(), as.mock.ExpectationsWereMet())
+}
+
+func (as *AuthSuite) TestLoginTwoFactorWithoutAttemptsLeft() {
+	input := dtos.TwoFactorLogin{ChallengeToken: "challenge", Code: "123456"}
+	as.mock.ExpectBegin()
+	as.mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "two_factor_challenges" SET "attempts"=attempts + 1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"token_hash", "user_id", "attempts", "expires_at"}))
+	as.mock.ExpectCommit()
+	as.mock.ExpectBegin()
+	as.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "two_factor_challenges" WHERE token_hash = $1`)).
+		WithArgs(hashToken(input.ChallengeToken)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	as.mock.ExpectCommit()
+	as.app.Post("/2fa/login", as.controller.LoginTwoFactor)
+
+	body, err := json.Marshal(input)
+	assert.Nil(as.T(), err)
+	req := httptest.NewRequest("POST", "http://localhost:8000/2fa/login", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := as.app.Test(req)
+	assert.Nil(as.T(), err)
+	assert.NotEqual(as.T(), resp.StatusCode, fiber.StatusOK)
+	assert.Nil(as.T(), as.mock.ExpectationsWereMet())
+}
+
+func (as *AuthSuite) TestVerifyTwoFactorEnablesWithRecoveryCodesInTransaction() {
+	id, secret := uuid.New(), "JBSWY3DPEHPK3PXP"
+	counter := totp.Counter(time.Now())
+	code, err := totp.Code(secret, counter)
+	assert.Nil(as.T(), err)
+	rows := sqlmock.NewRows([]string{"id", "name", "totp_secret", "totp_enabled"}).AddRow(id, "test", secret, false)
+	as.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE id = $1`)).
+		WithArgs(id).
+		WillReturnRows(rows)
+	as.mock.ExpectBegin()
+	as.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "totp_enabled"=$1,"totp_last_counter"=$2 WHERE id = $3 AND totp_secret = $4 AND totp_enabled = $5`)).
+		WithArgs(true, sqlmock.AnyArg(), id, secret, false).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	as.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "recovery_codes" WHERE user_id = $1`)).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	as.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "recovery_codes"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	as.mock.ExpectCommit()
+	token := &jwt.Token{Claims: jwt.MapClaims{"sub": id.String()}}
+	as.app.Post("/2fa/verify", func(c *fiber.Ctx) error {
+		c.Locals("user", token)
+		return c.Next()
+	}, as.controller.VerifyTwoFactor)
+
+	body, err := json.Marshal(dtos.TwoFactorCode{Code: code})
+	assert.Nil(as.T(), err)
+	req := httptest.NewRequest("POST", "http://localhost:8000/2fa/verify", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := as.app.Test(req)
+	assert.Nil(as.T(), err)
+	var codes dtos.RecoveryCodes
+	assert.Nil(as.T(), json.NewDecoder(resp.Body).Decode(&codes))
+	assert.Equal(as.T(), fiber.StatusOK, resp.StatusCode)
+	assert.Len(as.T(), codes.RecoveryCodes, CountRecoveryCodes)
+	assert.Nil(as.T(), as.mock.ExpectationsWereMet())
+}
+
+func (as *AuthSuite) TestRefreshTokenReuseRevokesFamily() {
+	refreshToken := "reused"
+	id, familyId := uuid.New(), uuid.New()
+	rows := sqlmock.NewRows([]string{"id", "user_id", "family_id", "token_hash", "is_revoked"}).
+		AddRow(id, uuid.New(), familyId, hashToken(refreshToken), true)
+	as.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "refresh_tokens" WHERE token_hash = $1`)).
+		WithArgs(hashToken(refreshToken)).
+		WillReturnRows(rows)
+	as.mock.ExpectBegin()
+	as.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "refresh_tokens" SET "is_revoked"=$1 WHERE family_id = $2`)).
+		WithArgs(true, familyId).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	as.mock.ExpectCommit()
+	as.app.Post("/refresh", as.controller.RefreshToken)
+
+	body, err := json.Marshal(dtos.RefreshInput{RefreshToken: refreshToken})
+	if err != nil {
+		assert.Error(as.T(), err)
+	}
+	req := httptest.NewRequest("POST", "http://localhost:8000/refresh", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := as.app.Test(req)
+	assert.Nil(as.T(), err)
+	assert.NotEqual(as.T(), resp.StatusCode, fiber.StatusOK)
+	assert.Nil(as.T(), as.mock.ExpectationsWereMet())
+}
+
+func (as *AuthSuite) TestChangePasswordRevokesAccessToken() {
+	id, jti := uuid.New(), uuid.NewString()
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("oldpassword"), bcrypt.DefaultCost)
+	assert.Nil(as.T(), err)
+	rows := sqlmock.NewRows([]string{"id", "name", "password"}).AddRow(id, "test", string(hashedPassword))
+	as.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE id = $1`)).
+		WithArgs(id).
+		WillReturnRows(rows)
+	as.mock.ExpectBegin()
+	as.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "password"=$1 WHERE id = $2`)).
+		WithArgs(sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	as.mock.ExpectCommit()
+	as.mock.ExpectBegin()
+	as.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "refresh_tokens" SET "is_revoked"=$1 WHERE user_id = $2`)).
+		WithArgs(true, id).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	as.mock.ExpectCommit()
+	as.mock.ExpectBegin()
+	as.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "revoked_tokens" ("jti","expires_at") VALUES ($1,$2) ON CONFLICT DO NOTHING`)).
+		WithArgs(jti, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	as.mock.ExpectCommit()
+	as.mock.ExpectBegin()
+	as.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "revoked_tokens" WHERE expires_at < $1`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	as.mock.ExpectCommit()
+	as.mock.ExpectBegin()
+	as.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "revoked_user_tokens" ("user_id","revoked_at","expires_at") VALUES ($1,$2,$3) ON CONFLICT ("user_id") DO UPDATE`)).
+		WithArgs(id, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	as.mock.ExpectCommit()
+	as.mock.ExpectBegin()
+	as.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "revoked_user_tokens" WHERE expires_at < $1`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	as.mock.ExpectCommit()
+	as.expectCreateRefreshToken(id)
+	token := &jwt.Token{Claims: jwt.MapClaims{
+		"sub": id.String(),
+		"jti": jti,
+		"exp": float64(time.Now().Add(time.Hour).Unix()),
+	}}
+	as.app.Put("/password", func(c *fiber.Ctx) error {
+		c.Locals("user", token)
+		return c.Next()
+	}, as.controller.ChangePassword)
+
+	body, err := json.Marshal(dtos.ChangePasswordInput{OldPassword: "oldpassword", NewPassword: "newpassword"})
+	assert.Nil(as.T(), err)
+	req := httptest.NewRequest("PUT", "http://localhost:8000/password", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := as.app.Test(req)
+	assert.Nil(as.T(), err)
+	var details dtos.TokenDetails
+	assert.Nil(as.T(), json.NewDecoder(resp.Body).Decode(&details))
+	assert.Equal(as.T(), fiber.StatusOK, resp.StatusCode)
+	assert.NotEmpty(as.T(), details.Token)
+	assert.NotEmpty(as.T(), details.RefreshToken)
+	assert.Nil(as.T(), as.mock.ExpectationsWereMet())
+}
+
+func (as *AuthSuite) TestRequestEmailChangeSendsTokenToNewEmail() {
+	id := uuid.New()
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	assert.Nil(as.T(), err)
+	rows := sqlmock.NewRows([]string{"id", "name", "password", "email"}).
+		AddRow(id, "test", string(hashedPassword), "old@example.com")
+	as.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE id = $1`)).
+		WithArgs(id).
+		WillReturnRows(rows)
+	as.mock.ExpectBegin()
+	as.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "email_change_tokens"`)).
+		WithArgs(id, false, sqlmock.AnyArg(), sqlmock.AnyArg(), "new@example.com", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+	as.mock.ExpectCommit()
+	as.app.Put("/email", func(c *fiber.Ctx) error {
+		c.Locals("user", &jwt.Token{Valid: true, Claims: jwt.MapClaims{"sub": id.String()}})
+		return c.Next()
+	}, as.controller.RequestEmailChange)
+
+	body, err := json.Marshal(dtos.ChangeEmailInput{Email: "new@example.com", Password: "password"})
+	assert.Nil(as.T(), err)
+	req := httptest.NewRequest("PUT", "http://localhost:8000/email", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := as.app.Test(req)
+	assert.Nil(as.T(), err)
+	assert.Equal(as.T(), fiber.StatusOK, resp.StatusCode)
+	assert.Nil(as.T(), as.mock.ExpectationsWereMet())
+}
+
+func (as *AuthSuite) TestConfirmEmailChangeWithInvalidToken() {
+	token := "invalid"
+	as.mock.ExpectBegin()
+	as.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "email_change_tokens" WHERE token_hash = $1 AND is_used = $2 AND expires_at > $3 FOR UPDATE`)).
+		WithArgs(hashToken(token), false, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	as.mock.ExpectCommit()
+	as.app.Post("/email/confirm", as.controller.ConfirmEmailChange)
+
+	body, err := json.Marshal(dtos.ConfirmEmailChange{Token: token})
+	assert.Nil(as.T(), err)
+	req := httptest.NewRequest("POST", "http://localhost:8000/email/confirm", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := as.app.Test(req)
+	assert.Nil(as.T(), err)
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	assert.NotEqual(as.T(), fiber.StatusOK, resp.StatusCode)
+	assert.Contains(as.T(), string(bodyBytes), "email change token")
+	assert.Nil(as.T(), as.mock.ExpectationsWereMet())
+}
+
+func (as *AuthSuite) TestAccessTokenRevokedWithAllTokensOfUser() {
+	id, jti := uuid.New(), uuid.NewString()
+	issuedAt := time.Now().UTC().Add(-time.Minute).Truncate(time.Second)
+	as.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "revoked_tokens" WHERE jti = $1`)).
+		WithArgs(jti).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	as.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "revoked_user_tokens" WHERE user_id = $1 AND revoked_at >= $2`)).
+		WithArgs(id, issuedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	revoked, err := as.controller.AuthService.(*AuthService).IsAccessTokenRevoked(jwt.MapClaims{
+		"sub": id.String(),
+		"jti": jti,
+		"iat": float64(issuedAt.Unix()),
+	})
+
+	assert.Nil(as.T(), err)
+	assert.True(as.T(), revoked)
+	assert.Nil(as.T(), as.mock.ExpectationsWereMet())
+}
+
+func (as *AuthSuite) expectCreateRefreshToken(userId uuid.UUID) {
+	as.mock.ExpectBegin()
+	as.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "refresh_tokens"`)).
+		WithArgs(userId, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "is_revoked"}).AddRow(uuid.New(), false))
+	as.mock.ExpectCommit()
 }
 
 // func (as *AuthSuite) TestWhoAmI() {
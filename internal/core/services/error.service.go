@@ -3,6 +3,8 @@ package services
 import (
 	"fmt"
 	"github.com/google/uuid"
+	"math"
+	"time"
 )
 
 type ValidateError struct {
@@ -18,7 +20,7 @@ type UserNotExistsError struct {
 }
 
 func (e UserNotExistsError) Error() string {
-	return fmt.Sprintf("User %s already exists", e.Username)
+	return fmt.Sprintf("User %s doesn't exist", e.Username)
 }
 
 type UserAlreadyExistsError struct {
@@ -67,7 +69,7 @@ type TournamentSizeAndTiktokCountMismatchError struct {
 }
 
 func (e TournamentSizeAndTiktokCountMismatchError) Error() string {
-	return fmt.Sprintf("Tournament size and count of tiktoks mismatch (%d != %d)",
+	return fmt.Sprintf("Tournament size is bigger than count of tiktoks (%d > %d)",
 		e.TournamentSize,
 		e.TiktokCount)
 }
@@ -85,7 +87,7 @@ type TournamentNotExistsError struct {
 }
 
 func (e TournamentNotExistsError) Error() string {
-	return fmt.Sprintf("Tournament with id: %s already exists", e.TournamentId)
+	return fmt.Sprintf("Tournament with id: %s doesn't exist", e.TournamentId)
 }
 
 type TournamentNameIsTakenError struct {
@@ -115,3 +117,256 @@ type NotAllowedContestTypeError struct {
 func (e NotAllowedContestTypeError) Error() string {
 	return fmt.Sprintf("Provided not allowed contests type: %s", e.ContestType)
 }
+
+type ContestResultsError struct {
+	error
+}
+
+func (e ContestResultsError) Error() string {
+	return fmt.Sprintf("Invalid contest results: %v", e.error)
+}
+
+type NotEnoughTiktoksError struct {
+	TiktokCount int
+}
+
+func (e NotEnoughTiktoksError) Error() string {
+	return fmt.Sprintf("Contest needs at least %d tiktoks, got %d", MinContestTiktoks, e.TiktokCount)
+}
+
+type PlaySessionNotExistsError struct {
+	SessionId uuid.UUID
+}
+
+func (e PlaySessionNotExistsError) Error() string {
+	return fmt.Sprintf("Play session with id: %s doesn't exist", e.SessionId)
+}
+
+type PlaySessionAccessDeniedError struct {
+	SessionId uuid.UUID
+}
+
+func (e PlaySessionAccessDeniedError) Error() string {
+	return fmt.Sprintf("Play session with id: %s belongs to other user", e.SessionId)
+}
+
+type PlaySessionCompletedError struct {
+	SessionId uuid.UUID
+}
+
+func (e PlaySessionCompletedError) Error() string {
+	return fmt.Sprintf("Play session with id: %s is already completed", e.SessionId)
+}
+
+type MatchDecisionError struct {
+	error
+}
+
+func (e MatchDecisionError) Error() string {
+	return fmt.Sprintf("Invalid match decision: %v", e.error)
+}
+
+type MatchAlreadyDecidedError struct {
+	SessionId uuid.UUID
+	MatchID   string
+}
+
+func (e MatchAlreadyDecidedError) Error() string {
+	return fmt.Sprintf("Match %s of play session with id: %s is already decided", e.MatchID, e.SessionId)
+}
+
+type ContestSerializationError struct {
+	error
+}
+
+func (e ContestSerializationError) Error() string {
+	return fmt.Sprintf("Contest serialization error: %v", e.error)
+}
+
+type TournamentAccessDeniedError struct {
+	TournamentId uuid.UUID
+}
+
+func (e TournamentAccessDeniedError) Error() string {
+	return fmt.Sprintf("No access to private tournament with id: %s", e.TournamentId)
+}
+
+type TiktokNotInTournamentError struct {
+	TiktokURL    string
+	TournamentId uuid.UUID
+}
+
+func (e TiktokNotInTournamentError) Error() string {
+	return fmt.Sprintf("Tiktok %s doesn't belong to tournament with id: %s", e.TiktokURL, e.TournamentId)
+}
+
+type PlaySessionNotCompletedError struct {
+	SessionId uuid.UUID
+}
+
+func (e PlaySessionNotCompletedError) Error() string {
+	return fmt.Sprintf("Play session with id: %s is not completed", e.SessionId)
+}
+
+type PlaySessionWinnerMismatchError struct {
+	SessionId uuid.UUID
+	TiktokURL string
+}
+
+func (e PlaySessionWinnerMismatchError) Error() string {
+	return fmt.Sprintf("Tiktok %s is not winner of play session with id: %s", e.TiktokURL, e.SessionId)
+}
+
+type TierListError struct {
+	error
+}
+
+func (e TierListError) Error() string {
+	return fmt.Sprintf("Tier list error: %v", e.error)
+}
+
+type InvalidRefreshTokenError struct {
+}
+
+func (e InvalidRefreshTokenError) Error() string {
+	return "Invalid or expired refresh token"
+}
+
+type InvalidPasswordResetTokenError struct {
+}
+
+func (e InvalidPasswordResetTokenError) Error() string {
+	return "Invalid, expired or already used password reset token"
+}
+
+type InvalidEmailChangeTokenError struct {
+}
+
+func (e InvalidEmailChangeTokenError) Error() string {
+	return "Invalid, expired or already used email change token"
+}
+
+type MailError struct {
+	error
+}
+
+func (e MailError) Error() string {
+	return fmt.Sprintf("Mail error: %v", e.error)
+}
+
+type UserBannedError struct {
+	Username string
+}
+
+func (e UserBannedError) Error() string {
+	return fmt.Sprintf("User %s is banned", e.Username)
+}
+
+type InsufficientRoleError struct {
+	Role string
+}
+
+func (e InsufficientRoleError) Error() string {
+	return fmt.Sprintf("Action is not allowed for %s", e.Role)
+}
+
+type LoginLockedError struct {
+	Username   string
+	RetryAfter time.Duration
+}
+
+func (e LoginLockedError) Error() string {
+	return fmt.Sprintf("Login of %s is locked after too many failed attempts, retry after %d seconds",
+		e.Username, int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+type OIDCProviderNotFoundError struct {
+	Provider string
+}
+
+func (e OIDCProviderNotFoundError) Error() string {
+	return fmt.Sprintf("Login provider %s doesn't exist", e.Provider)
+}
+
+type OIDCError struct {
+	error
+}
+
+func (e OIDCError) Error() string {
+	return fmt.Sprintf("Login with provider failed: %v", e.error)
+}
+
+type IdentityAlreadyLinkedError struct {
+	Provider string
+}
+
+func (e IdentityAlreadyLinkedError) Error() string {
+	return fmt.Sprintf("Account of %s is already linked to another user", e.Provider)
+}
+
+type InvalidAPIKeyError struct {
+}
+
+func (e InvalidAPIKeyError) Error() string {
+	return "Invalid or expired API key"
+}
+
+type APIKeyNotExistsError struct {
+	ID string
+}
+
+func (e APIKeyNotExistsError) Error() string {
+	return fmt.Sprintf("API key with id %s doesn't exist", e.ID)
+}
+
+type APIKeyLimitError struct {
+	Limit int
+}
+
+func (e APIKeyLimitError) Error() string {
+	return fmt.Sprintf("User can't have more than %d API keys", e.Limit)
+}
+
+type TwoFactorAlreadyEnabledError struct {
+}
+
+func (e TwoFactorAlreadyEnabledError) Error() string {
+	return "Two-factor authentication is already enabled"
+}
+
+type TwoFactorNotEnabledError struct {
+}
+
+func (e TwoFactorNotEnabledError) Error() string {
+	return "Two-factor authentication is not enabled"
+}
+
+type InvalidTwoFactorCodeError struct {
+}
+
+func (e InvalidTwoFactorCodeError) Error() string {
+	return "Invalid or already used two-factor code"
+}
+
+type InvalidTwoFactorChallengeError struct {
+}
+
+func (e InvalidTwoFactorChallengeError) Error() string {
+	return "Invalid or expired two-factor challenge, login again"
+}
+
+type ReauthenticationRequiredError struct {
+	MaxAge time.Duration
+}
+
+func (e ReauthenticationRequiredError) Error() string {
+	return fmt.Sprintf("Login with provider again, login must be not older than %s", e.MaxAge)
+}
+
+type ExportError struct {
+	error
+}
+
+func (e ExportError) Error() string {
+	return fmt.Sprintf("Export error: %v", e.error)
+}
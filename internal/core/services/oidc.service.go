@@ -0,0 +1,245 @@
+package services
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"sort"
+	"strings"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+	"tiktok-arena/internal/core/validator"
+	"time"
+)
+
+// OIDCLoginStateExpiresIn time user has to login at provider
+const OIDCLoginStateExpiresIn = 10 * time.Minute
+
+// IdentityProvider external OpenID Connect provider which authenticates users with authorization code and PKCE
+type IdentityProvider interface {
+	AuthorizationURL(state string, nonce string, codeVerifier string) (string, error)
+	Exchange(code string, codeVerifier string, nonce string) (dtos.ExternalIdentity, error)
+}
+
+type OIDCServiceIdentityRepository interface {
+	CreateLoginState(state *models.OIDCLoginState) error
+	TakeLoginState(stateHash string) (models.OIDCLoginState, bool, error)
+	GetIdentity(provider string, subject string) (models.UserIdentity, error)
+	CreateIdentity(identity *models.UserIdentity) error
+	CreateUserWithIdentity(user *models.User, identity *models.UserIdentity) error
+}
+
+type OIDCService struct {
+	Providers          map[string]IdentityProvider
+	IdentityRepository OIDCServiceIdentityRepository
+	AuthService        *AuthService
+}
+
+func NewOIDCService(providers map[string]IdentityProvider, identityRepository OIDCServiceIdentityRepository,
+	authService *AuthService) *OIDCService {
+	return &OIDCService{Providers: providers, IdentityRepository: identityRepository, AuthService: authService}
+}
+
+// GetProviders returns names of configured providers
+func (s *OIDCService) GetProviders() dtos.OIDCProviders {
+	providers := make([]string, 0, len(s.Providers))
+	for name := range s.Providers {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+	return dtos.OIDCProviders{Providers: providers}
+}
+
+// StartLogin saves state of login and returns URL of provider where user logs in,
+// if userId is set identity of provider is linked to this user after login
+func (s *OIDCService) StartLogin(providerName string, userId *uuid.UUID) (login dtos.OIDCLogin, err error) {
+	provider, ok := s.Providers[providerName]
+	if !ok {
+		return login, OIDCProviderNotFoundError{Provider: providerName}
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return login, err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return login, err
+	}
+	codeVerifier, err := randomToken()
+	if err != nil {
+		return login, err
+	}
+
+	url, err := provider.AuthorizationURL(state, nonce, codeVerifier)
+	if err != nil {
+		return login, OIDCError{err}
+	}
+
+	err = s.IdentityRepository.CreateLoginState(&models.OIDCLoginState{
+		StateHash:    hashToken(state),
+		Provider:     providerName,
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		UserID:       userId,
+		ExpiresAt:    time.Now().UTC().Add(OIDCLoginStateExpiresIn),
+	})
+	if err != nil {
+		return login, RepositoryError{err}
+	}
+
+	return dtos.OIDCLogin{AuthorizationURL: url, State: state}, nil
+}
+
+// CompleteLogin exchanges authorization code returned by provider and logs in user of provider identity,
+// user is registered on first login, identity is linked to user who started login if login was started by user.
+// State must be returned to browser which started login, so login can't be completed in browser of another user.
+func (s *OIDCService) CompleteLogin(providerName string, callback dtos.OIDCCallback) (details dtos.LoginDetails, err error) {
+	provider, ok := s.Providers[providerName]
+	if !ok {
+		return details, OIDCProviderNotFoundError{Provider: providerName}
+	}
+	if callback.Error != "" {
+		return details, OIDCError{fmt.Errorf("%s %s", callback.Error, callback.ErrorDescription)}
+	}
+	err = validator.ValidateStruct(callback)
+	if err != nil {
+		return details, ValidateError{err}
+	}
+
+	if subtle.ConstantTimeCompare([]byte(callback.State), []byte(callback.BrowserState)) != 1 {
+		return details, OIDCError{errors.New("state doesn't belong to browser")}
+	}
+
+	state, taken, err := s.IdentityRepository.TakeLoginState(hashToken(callback.State))
+	if err != nil {
+		return details, RepositoryError{err}
+	}
+	if !taken || state.Provider != providerName {
+		return details, OIDCError{errors.New("invalid or expired state")}
+	}
+
+	external, err := provider.Exchange(callback.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		return details, OIDCError{err}
+	}
+
+	user, err := s.identityUser(providerName, external, state.UserID)
+	if err != nil {
+		return details, err
+	}
+	if user.IsBanned {
+		return details, UserBannedError{Username: user.Name}
+	}
+	err = s.AuthService.UserRepository.SetUserProviderLoginAt(user.ID, time.Now().UTC())
+	if err != nil {
+		return details, RepositoryError{err}
+	}
+
+	return s.AuthService.loginOrChallenge(user)
+}
+
+// identityUser finds user of identity, links identity to user with linkUserId or registers new user
+func (s *OIDCService) identityUser(providerName string, external dtos.ExternalIdentity,
+	linkUserId *uuid.UUID) (user models.User, err error) {
+	identity, err := s.IdentityRepository.GetIdentity(providerName, external.Subject)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return user, RepositoryError{err}
+	}
+	exists := err == nil
+
+	if linkUserId != nil {
+		if exists && identity.UserID != *linkUserId {
+			return user, IdentityAlreadyLinkedError{Provider: providerName}
+		}
+		user, err = s.AuthService.UserRepository.GetUserByID(*linkUserId)
+		if err != nil {
+			return user, RepositoryError{err}
+		}
+		if user.ID == uuid.Nil {
+			return user, UserNotExistsError{Username: linkUserId.String()}
+		}
+		if !exists {
+			err = s.IdentityRepository.CreateIdentity(&models.UserIdentity{
+				UserID:   user.ID,
+				Provider: providerName,
+				Subject:  external.Subject,
+				Email:    external.Email,
+			})
+			if err != nil {
+				return user, RepositoryError{err}
+			}
+		}
+		return user, nil
+	}
+
+	if exists {
+		user, err = s.AuthService.UserRepository.GetUserByID(identity.UserID)
+		if err != nil {
+			return user, RepositoryError{err}
+		}
+		if user.ID == uuid.Nil {
+			return user, UserNotExistsError{Username: identity.UserID.String()}
+		}
+		return user, nil
+	}
+
+	return s.registerIdentityUser(providerName, external)
+}
+
+// registerIdentityUser registers user of provider identity with unique name,
+// user has no password, so user can login only through provider until password is reset
+func (s *OIDCService) registerIdentityUser(providerName string, external dtos.ExternalIdentity) (user models.User, err error) {
+	name, err := s.uniqueUsername(providerName, external)
+	if err != nil {
+		return user, err
+	}
+
+	user = models.User{
+		Name:     name,
+		Password: models.NoPassword,
+		Role:     models.RoleUser,
+	}
+	if external.EmailVerified {
+		user.Email = external.Email
+	}
+	err = s.IdentityRepository.CreateUserWithIdentity(&user, &models.UserIdentity{
+		Provider: providerName,
+		Subject:  external.Subject,
+		Email:    external.Email,
+	})
+	if err != nil {
+		return user, RepositoryError{err}
+	}
+	return user, nil
+}
+
+// uniqueUsername takes name of identity, local part of email or provider name as username,
+// random suffix is added if username is already taken
+func (s *OIDCService) uniqueUsername(providerName string, external dtos.ExternalIdentity) (string, error) {
+	base := strings.TrimSpace(external.Name)
+	if base == "" {
+		base, _, _ = strings.Cut(external.Email, "@")
+	}
+	if base == "" {
+		base = providerName
+	}
+	if runes := []rune(base); len(runes) > 32 {
+		base = string(runes[:32])
+	}
+
+	name := base
+	for i := 0; i < 5; i++ {
+		exists, err := s.AuthService.UserRepository.UserExists(name)
+		if err != nil {
+			return "", RepositoryError{err}
+		}
+		if !exists && !models.IsReservedName(name) {
+			return name, nil
+		}
+		name = fmt.Sprintf("%s-%s", base, uuid.NewString()[:8])
+	}
+	return "", UserAlreadyExistsError{base}
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+)
+
+// fakeIdentityRepository keeps states of started logins in memory
+type fakeIdentityRepository struct {
+	OIDCServiceIdentityRepository
+	states map[string]models.OIDCLoginState
+}
+
+func (r *fakeIdentityRepository) TakeLoginState(stateHash string) (models.OIDCLoginState, bool, error) {
+	state, ok := r.states[stateHash]
+	delete(r.states, stateHash)
+	return state, ok, nil
+}
+
+func TestCompleteLoginRejectsStateOfAnotherBrowser(t *testing.T) {
+	tests := []struct {
+		name         string
+		browserState string
+	}{
+		{name: "without state cookie", browserState: ""},
+		{name: "state cookie of another login", browserState: "another-state"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := &fakeIdentityRepository{states: map[string]models.OIDCLoginState{
+				hashToken("test-state"): {Provider: "mock"},
+			}}
+			s := NewOIDCService(map[string]IdentityProvider{"mock": nil}, repository, nil)
+
+			_, err := s.CompleteLogin("mock", dtos.OIDCCallback{
+				Code:         "test-code",
+				State:        "test-state",
+				BrowserState: test.browserState,
+			})
+
+			assert.IsType(t, OIDCError{}, err)
+			assert.Len(t, repository.states, 1, "state of login is taken")
+		})
+	}
+}
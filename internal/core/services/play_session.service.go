@@ -0,0 +1,333 @@
+package services
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"tiktok-arena/internal/core/contests"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+	"tiktok-arena/internal/core/ratings"
+	"tiktok-arena/internal/core/validator"
+)
+
+type PlaySessionServicePlaySessionRepository interface {
+	CreatePlaySession(session *models.PlaySession) error
+	GetPlaySessionById(id uuid.UUID) (models.PlaySession, error)
+	UpdatePlaySessionContest(id uuid.UUID, old string, contest string) (bool, error)
+	GetMatchDecisions(sessionId uuid.UUID) ([]models.MatchDecision, error)
+	GetMatchVotes(sessionId uuid.UUID) ([]models.MatchVote, error)
+	RecordMatchVote(tournamentId uuid.UUID, vote models.MatchVote, decision *models.MatchDecision,
+		rate func(winner *models.Tiktok, loser *models.Tiktok)) (bool, error)
+	CompletePlaySession(session models.PlaySession, tiktokURLs []string, ranking []models.SessionRanking) error
+}
+
+type PlaySessionServiceTiktokRepository interface {
+	GetTournamentTiktoksById(tournamentId uuid.UUID) ([]models.Tiktok, error)
+}
+
+type PlaySessionServiceTournamentRepository interface {
+	GetTournamentById(tournamentId uuid.UUID) (models.Tournament, error)
+}
+
+type PlaySessionService struct {
+	PlaySessionRepository PlaySessionServicePlaySessionRepository
+	TiktokRepository      PlaySessionServiceTiktokRepository
+	TournamentRepository  PlaySessionServiceTournamentRepository
+}
+
+func NewPlaySessionService(playSessionRepository PlaySessionServicePlaySessionRepository,
+	tiktokRepository PlaySessionServiceTiktokRepository,
+	tournamentRepository PlaySessionServiceTournamentRepository) *PlaySessionService {
+	return &PlaySessionService{PlaySessionRepository: playSessionRepository, TiktokRepository: tiktokRepository,
+		TournamentRepository: tournamentRepository}
+}
+
+func (s *PlaySessionService) StartPlaySession(tournamentIdString string, payload dtos.ContestPayload, userId *uuid.UUID) (details dtos.PlaySessionDetails, err error) {
+	if tournamentIdString == "" {
+		return details, EmptyTournamentIdError{}
+	}
+
+	err = validator.ValidateStruct(payload)
+	if err != nil {
+		return details, ValidateError{err}
+	}
+
+	if !dtos.CheckIfAllowedContestType(payload.Type) {
+		return details, NotAllowedContestTypeError{payload.Type}
+	}
+	tournamentId, err := uuid.Parse(tournamentIdString)
+	if err != nil {
+		return details, UUIDError{err}
+	}
+
+	tournament, err := s.TournamentRepository.GetTournamentById(tournamentId)
+	if err == gorm.ErrRecordNotFound {
+		return details, TournamentNotExistsError{tournamentId}
+	}
+	if err != nil {
+		return details, RepositoryError{err}
+	}
+	if tournament.IsPrivate && (userId == nil || tournament.UserID != *userId) {
+		return details, TournamentAccessDeniedError{tournamentId}
+	}
+
+	tiktoks, err := s.TiktokRepository.GetTournamentTiktoksById(tournamentId)
+	if err != nil {
+		return details, RepositoryError{err}
+	}
+	if len(tiktoks) == 0 {
+		return details, TournamentNotExistsError{tournamentId}
+	}
+	if len(tiktoks) < MinContestTiktoks {
+		return details, NotEnoughTiktoksError{TiktokCount: len(tiktoks)}
+	}
+
+	contest := newContest(tournament, tiktoks, payload)
+	serializedContest, err := json.Marshal(contest)
+	if err != nil {
+		return details, ContestSerializationError{err}
+	}
+
+	session := models.PlaySession{
+		TournamentID: tournamentId,
+		UserID:       userId,
+		ContestType:  payload.Type,
+		Contest:      string(serializedContest),
+	}
+	err = s.PlaySessionRepository.CreatePlaySession(&session)
+	if err != nil {
+		return details, RepositoryError{err}
+	}
+
+	return s.playSessionDetails(session, contests.NewProgress(contest), []models.MatchDecision{}, []models.MatchVote{}), nil
+}
+
+func (s *PlaySessionService) GetPlaySession(sessionIdString string) (details dtos.PlaySessionDetails, err error) {
+	session, progress, decisions, votes, err := s.loadPlaySession(sessionIdString)
+	if err != nil {
+		return details, err
+	}
+	return s.playSessionDetails(session, progress, decisions, votes), nil
+}
+
+// DecideMatch records vote of user in match of play session,
+// session of logged in user can be played only by this user, anonymous session by anyone who knows its ID
+func (s *PlaySessionService) DecideMatch(sessionIdString string, decision dtos.MatchDecision,
+	userId *uuid.UUID) (details dtos.PlaySessionDetails, err error) {
+	err = validator.ValidateStruct(decision)
+	if err != nil {
+		return details, ValidateError{err}
+	}
+
+	session, progress, _, _, err := s.loadPlaySession(sessionIdString)
+	if err != nil {
+		return details, err
+	}
+	if session.UserID != nil && (userId == nil || *userId != *session.UserID) {
+		return details, PlaySessionAccessDeniedError{session.ID}
+	}
+	if session.IsCompleted {
+		return details, PlaySessionCompletedError{session.ID}
+	}
+
+	first, second, err := progress.Participators(decision.MatchID)
+	if err != nil {
+		return details, MatchDecisionError{err}
+	}
+	decided, err := progress.Vote(decision.MatchID, decision.WinnerURL)
+	if err != nil {
+		return details, MatchDecisionError{err}
+	}
+
+	// Every vote is recorded, best-of-N match is decided only by vote giving required count of wins,
+	// decision is recorded with matchup and ratings of its tiktoks in one transaction
+	vote := models.MatchVote{
+		SessionID: session.ID,
+		MatchID:   decision.MatchID,
+		TiktokURL: decision.WinnerURL,
+	}
+	var newDecision *models.MatchDecision
+	var rate func(winner *models.Tiktok, loser *models.Tiktok)
+	if decided {
+		loserURL := first
+		if decision.WinnerURL == first {
+			loserURL = second
+		}
+		newDecision = &models.MatchDecision{
+			SessionID: session.ID,
+			MatchID:   decision.MatchID,
+			WinnerURL: decision.WinnerURL,
+			LoserURL:  loserURL,
+		}
+		rate, err = s.ratingUpdate(session.TournamentID)
+		if err != nil {
+			return details, err
+		}
+	}
+	recorded, err := s.PlaySessionRepository.RecordMatchVote(session.TournamentID, vote, newDecision, rate)
+	if err != nil {
+		return details, RepositoryError{err}
+	}
+	if !recorded {
+		// Match was decided by concurrent vote
+		return details, MatchAlreadyDecidedError{SessionId: session.ID, MatchID: decision.MatchID}
+	}
+
+	// Session is loaded again with vote, so next round is paired and session is completed
+	// also when other matches of round were decided concurrently
+	return s.GetPlaySession(sessionIdString)
+}
+
+// ratingUpdate returns function updating ratings of match participators with rating system of tournament
+func (s *PlaySessionService) ratingUpdate(tournamentId uuid.UUID) (func(winner *models.Tiktok, loser *models.Tiktok), error) {
+	tournament, err := s.TournamentRepository.GetTournamentById(tournamentId)
+	if err != nil {
+		return nil, RepositoryError{err}
+	}
+	system := ratings.GetSystem(tournament.RatingSystem)
+
+	return func(winner *models.Tiktok, loser *models.Tiktok) {
+		winnerRating, loserRating := system.Update(
+			ratings.Rating{Value: winner.Rating, Deviation: winner.RatingDeviation, Volatility: winner.RatingVolatility},
+			ratings.Rating{Value: loser.Rating, Deviation: loser.RatingDeviation, Volatility: loser.RatingVolatility},
+		)
+		winner.Rating, winner.RatingDeviation, winner.RatingVolatility =
+			winnerRating.Value, winnerRating.Deviation, winnerRating.Volatility
+		loser.Rating, loser.RatingDeviation, loser.RatingVolatility =
+			loserRating.Value, loserRating.Deviation, loserRating.Volatility
+	}, nil
+}
+
+// loadPlaySession gets session with its contest, replays decided matches and votes of undecided matches,
+// then pairs next round and completes session if it is still pending (see advancePlaySession)
+func (s *PlaySessionService) loadPlaySession(sessionIdString string) (session models.PlaySession, progress *contests.Progress,
+	decisions []models.MatchDecision, votes []models.MatchVote, err error) {
+	sessionId, err := uuid.Parse(sessionIdString)
+	if err != nil {
+		return session, progress, decisions, votes, UUIDError{err}
+	}
+
+	session, err = s.PlaySessionRepository.GetPlaySessionById(sessionId)
+	if err == gorm.ErrRecordNotFound {
+		return session, progress, decisions, votes, PlaySessionNotExistsError{sessionId}
+	}
+	if err != nil {
+		return session, progress, decisions, votes, RepositoryError{err}
+	}
+
+	var contest dtos.Contest
+	err = json.Unmarshal([]byte(session.Contest), &contest)
+	if err != nil {
+		return session, progress, decisions, votes, ContestSerializationError{err}
+	}
+
+	decisions, err = s.PlaySessionRepository.GetMatchDecisions(sessionId)
+	if err != nil {
+		return session, progress, decisions, votes, RepositoryError{err}
+	}
+	votes, err = s.PlaySessionRepository.GetMatchVotes(sessionId)
+	if err != nil {
+		return session, progress, decisions, votes, RepositoryError{err}
+	}
+
+	progress = contests.NewProgress(contest)
+	for _, decision := range decisions {
+		err = progress.Decide(decision.MatchID, decision.WinnerURL)
+		if err != nil {
+			return session, progress, decisions, votes, MatchDecisionError{err}
+		}
+	}
+	for _, vote := range votes {
+		if progress.IsDecided(vote.MatchID) {
+			continue
+		}
+		_, err = progress.Vote(vote.MatchID, vote.TiktokURL)
+		if err != nil {
+			return session, progress, decisions, votes, MatchDecisionError{err}
+		}
+	}
+	err = s.advancePlaySession(&session, progress)
+	return
+}
+
+// advancePlaySession pairs next round when all rounds are decided and completes session when contest is finished.
+// Votes are recorded before, so pairing or completion left pending by concurrent votes or by failed request
+// is done by next load of session. It is safe to run concurrently: the same decisions are paired the same way,
+// contest is replaced only if it wasn't replaced already and session is completed only once.
+func (s *PlaySessionService) advancePlaySession(session *models.PlaySession, progress *contests.Progress) error {
+	if session.IsCompleted {
+		return nil
+	}
+
+	paired, err := progress.PairNextRound()
+	if err != nil {
+		return MatchDecisionError{err}
+	}
+	if paired {
+		serializedContest, err := json.Marshal(progress.Contest())
+		if err != nil {
+			return ContestSerializationError{err}
+		}
+		_, err = s.PlaySessionRepository.UpdatePlaySessionContest(session.ID, session.Contest, string(serializedContest))
+		if err != nil {
+			return RepositoryError{err}
+		}
+		session.Contest = string(serializedContest)
+	}
+
+	if podium, finished := progress.Podium(); finished {
+		session.WinnerURL, session.SecondURL, session.ThirdURL = podium.First, podium.Second, podium.Third
+		var ranking []models.SessionRanking
+		if session.ContestType == dtos.FullRanking {
+			for i, standing := range progress.Standings() {
+				ranking = append(ranking, models.SessionRanking{
+					SessionID:    session.ID,
+					TournamentID: session.TournamentID,
+					TiktokURL:    standing.TiktokURL,
+					Rank:         i + 1,
+				})
+			}
+		}
+		err = s.PlaySessionRepository.CompletePlaySession(*session, progress.TiktokURLs(), ranking)
+		if err != nil {
+			return RepositoryError{err}
+		}
+		session.IsCompleted = true
+	}
+	return nil
+}
+
+func (s *PlaySessionService) playSessionDetails(session models.PlaySession, progress *contests.Progress,
+	decisions []models.MatchDecision, votes []models.MatchVote) dtos.PlaySessionDetails {
+	contest := progress.Contest()
+	contest.Standings = progress.Standings()
+
+	matchDecisions := make([]dtos.MatchDecision, 0, len(decisions))
+	for _, decision := range decisions {
+		matchDecisions = append(matchDecisions, dtos.MatchDecision{
+			MatchID:   decision.MatchID,
+			WinnerURL: decision.WinnerURL,
+		})
+	}
+
+	matchVotes := make([]dtos.MatchVote, 0, len(votes))
+	for _, vote := range votes {
+		matchVotes = append(matchVotes, dtos.MatchVote{
+			MatchID:   vote.MatchID,
+			TiktokURL: vote.TiktokURL,
+		})
+	}
+
+	return dtos.PlaySessionDetails{
+		ID:           session.ID,
+		TournamentID: session.TournamentID,
+		Contest:      contest,
+		Decisions:    matchDecisions,
+		Votes:        matchVotes,
+		IsCompleted:  session.IsCompleted,
+		WinnerURL:    session.WinnerURL,
+		SecondURL:    session.SecondURL,
+		ThirdURL:     session.ThirdURL,
+	}
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tiktok-arena/internal/core/contests"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+)
+
+// fakePlaySessionRepository keeps play sessions in memory
+type fakePlaySessionRepository struct {
+	sessions      map[uuid.UUID]models.PlaySession
+	decisions     []models.MatchDecision
+	votes         []models.MatchVote
+	completedURLs []string // tiktoks which appearances are updated on completion of session
+	beforeRecord  func()   // called before vote is recorded, e.g. to record concurrent vote
+}
+
+func (r *fakePlaySessionRepository) CreatePlaySession(session *models.PlaySession) error {
+	session.ID = uuid.New()
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *fakePlaySessionRepository) GetPlaySessionById(id uuid.UUID) (models.PlaySession, error) {
+	return r.sessions[id], nil
+}
+
+func (r *fakePlaySessionRepository) UpdatePlaySessionContest(id uuid.UUID, old string, contest string) (bool, error) {
+	session := r.sessions[id]
+	if session.Contest != old {
+		return false, nil
+	}
+	session.Contest = contest
+	r.sessions[id] = session
+	return true, nil
+}
+
+func (r *fakePlaySessionRepository) GetMatchDecisions(uuid.UUID) ([]models.MatchDecision, error) {
+	return r.decisions, nil
+}
+
+func (r *fakePlaySessionRepository) GetMatchVotes(uuid.UUID) ([]models.MatchVote, error) {
+	return r.votes, nil
+}
+
+func (r *fakePlaySessionRepository) RecordMatchVote(_ uuid.UUID, vote models.MatchVote, decision *models.MatchDecision,
+	_ func(winner *models.Tiktok, loser *models.Tiktok)) (bool, error) {
+	if r.beforeRecord != nil {
+		r.beforeRecord()
+		r.beforeRecord = nil
+	}
+	r.votes = append(r.votes, vote)
+	if decision != nil {
+		r.decisions = append(r.decisions, *decision)
+	}
+	return true, nil
+}
+
+func (r *fakePlaySessionRepository) CompletePlaySession(session models.PlaySession, tiktokURLs []string,
+	_ []models.SessionRanking) error {
+	session.IsCompleted = true
+	r.sessions[session.ID] = session
+	r.completedURLs = tiktokURLs
+	return nil
+}
+
+// fakeTiktokRepository keeps tiktoks of tournament in memory, not faked methods of tournament service panic
+type fakeTiktokRepository struct {
+	TournamentServiceTiktokRepository
+	tiktoks []models.Tiktok
+}
+
+func (r *fakeTiktokRepository) GetTournamentTiktoksById(uuid.UUID) ([]models.Tiktok, error) {
+	return append([]models.Tiktok{}, r.tiktoks...), nil
+}
+
+func (r *fakeTiktokRepository) CheckIfTiktokExists(_ uuid.UUID, tiktokURL string) (bool, error) {
+	for _, tiktok := range r.tiktoks {
+		if tiktok.URL == tiktokURL {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// fakeTournamentRepository returns tournament with any ID, public one unless owner of private one is set,
+// not faked methods of tournament service panic
+type fakeTournamentRepository struct {
+	TournamentServiceTournamentRepository
+	privateOwner *uuid.UUID
+}
+
+func (r *fakeTournamentRepository) GetTournamentById(tournamentId uuid.UUID) (models.Tournament, error) {
+	if r.privateOwner != nil {
+		return models.Tournament{ID: tournamentId, IsPrivate: true, UserID: *r.privateOwner}, nil
+	}
+	return models.Tournament{ID: tournamentId}, nil
+}
+
+func TestDecideMatchCompletesSessionWithAppearancesOfAllTiktoks(t *testing.T) {
+	var tiktoks []models.Tiktok
+	var urls []string
+	for i := 0; i < 5; i++ {
+		tiktoks = append(tiktoks, models.Tiktok{URL: fmt.Sprint("testurl", i)})
+		urls = append(urls, fmt.Sprint("testurl", i))
+	}
+
+	for _, contestType := range []string{
+		dtos.SingleElimination, dtos.KingOfTheHill, dtos.DoubleElimination, dtos.RoundRobin, dtos.Swiss,
+	} {
+		t.Run(contestType, func(t *testing.T) {
+			repository := &fakePlaySessionRepository{sessions: map[uuid.UUID]models.PlaySession{}}
+			service := NewPlaySessionService(repository, &fakeTiktokRepository{tiktoks: tiktoks}, &fakeTournamentRepository{})
+			seed := int64(1)
+
+			details, err := service.StartPlaySession(uuid.New().String(), dtos.ContestPayload{Type: contestType, Seed: &seed}, nil)
+			assert.Nil(t, err)
+			for i := 0; !details.IsCompleted && i < 100; i++ {
+				matchID, winnerURL := nextMatch(t, details)
+				details, err = service.DecideMatch(details.ID.String(), dtos.MatchDecision{MatchID: matchID, WinnerURL: winnerURL}, nil)
+				assert.Nil(t, err)
+			}
+
+			assert.True(t, details.IsCompleted)
+			assert.ElementsMatch(t, urls, repository.completedURLs)
+		})
+	}
+}
+
+func TestDecideMatchPairsNextRoundAfterConcurrentDecisions(t *testing.T) {
+	var tiktoks []models.Tiktok
+	for i := 0; i < 4; i++ {
+		tiktoks = append(tiktoks, models.Tiktok{URL: fmt.Sprint("testurl", i)})
+	}
+	repository := &fakePlaySessionRepository{sessions: map[uuid.UUID]models.PlaySession{}}
+	service := NewPlaySessionService(repository, &fakeTiktokRepository{tiktoks: tiktoks}, &fakeTournamentRepository{})
+	seed := int64(1)
+	details, err := service.StartPlaySession(uuid.New().String(), dtos.ContestPayload{Type: dtos.Swiss, Seed: &seed}, nil)
+	assert.Nil(t, err)
+	first, second := details.Contest.Rounds[0].Matches[0], details.Contest.Rounds[0].Matches[1]
+	firstWinner := first.FirstOption.(dtos.TiktokOption).TiktokURL
+	secondWinner := second.FirstOption.(dtos.TiktokOption).TiktokURL
+
+	// Other request decides first match of round after this request loaded session without it
+	repository.beforeRecord = func() {
+		repository.votes = append(repository.votes, models.MatchVote{MatchID: first.MatchID, TiktokURL: firstWinner})
+		repository.decisions = append(repository.decisions, models.MatchDecision{MatchID: first.MatchID, WinnerURL: firstWinner})
+	}
+	details, err = service.DecideMatch(details.ID.String(), dtos.MatchDecision{MatchID: second.MatchID, WinnerURL: secondWinner}, nil)
+
+	assert.Nil(t, err)
+	assert.Len(t, details.Contest.Rounds, 2)
+	var stored dtos.Contest
+	assert.Nil(t, json.Unmarshal([]byte(repository.sessions[details.ID].Contest), &stored))
+	assert.Len(t, stored.Rounds, 2)
+}
+
+func TestDecideMatchOfUserSession(t *testing.T) {
+	tiktoks := []models.Tiktok{{URL: "first"}, {URL: "second"}}
+	owner, other := uuid.New(), uuid.New()
+	tests := []struct {
+		name   string
+		userId *uuid.UUID
+		denied bool
+	}{
+		{name: "owner", userId: &owner},
+		{name: "other user", userId: &other, denied: true},
+		{name: "anonymous", denied: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := &fakePlaySessionRepository{sessions: map[uuid.UUID]models.PlaySession{}}
+			service := NewPlaySessionService(repository, &fakeTiktokRepository{tiktoks: tiktoks}, &fakeTournamentRepository{})
+			details, err := service.StartPlaySession(uuid.New().String(), dtos.ContestPayload{Type: dtos.SingleElimination}, &owner)
+			assert.Nil(t, err)
+			matchID, winnerURL := nextMatch(t, details)
+
+			_, err = service.DecideMatch(details.ID.String(), dtos.MatchDecision{MatchID: matchID, WinnerURL: winnerURL}, test.userId)
+
+			if test.denied {
+				assert.Equal(t, PlaySessionAccessDeniedError{SessionId: details.ID}, err)
+				assert.Empty(t, repository.votes)
+			} else {
+				assert.Nil(t, err)
+				assert.Len(t, repository.decisions, 1)
+			}
+		})
+	}
+}
+
+// nextMatch returns first playable match of play session with its first option as winner
+func nextMatch(t *testing.T, details dtos.PlaySessionDetails) (matchID string, winnerURL string) {
+	progress := contests.NewProgress(details.Contest)
+	for _, decision := range details.Decisions {
+		assert.Nil(t, progress.Decide(decision.MatchID, decision.WinnerURL))
+	}
+	for _, round := range details.Contest.Rounds {
+		for _, match := range round.Matches {
+			if progress.IsDecided(match.MatchID) {
+				continue
+			}
+			if first, _, err := progress.Participators(match.MatchID); err == nil {
+				return match.MatchID, first
+			}
+		}
+	}
+	t.Fatal("play session has no playable match")
+	return "", ""
+}
+
+func TestStartPlaySessionOfPrivateTournament(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+	tiktoks := []models.Tiktok{{URL: "first"}, {URL: "second"}}
+	repository := &fakePlaySessionRepository{sessions: map[uuid.UUID]models.PlaySession{}}
+	service := NewPlaySessionService(repository, &fakeTiktokRepository{tiktoks: tiktoks},
+		&fakeTournamentRepository{privateOwner: &owner})
+	tournamentId := uuid.New()
+
+	tests := []struct {
+		name   string
+		userId *uuid.UUID
+		err    error
+	}{
+		{name: "owner", userId: &owner},
+		{name: "other user", userId: &other, err: TournamentAccessDeniedError{tournamentId}},
+		{name: "anonymous", err: TournamentAccessDeniedError{tournamentId}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := service.StartPlaySession(tournamentId.String(), dtos.ContestPayload{Type: dtos.SingleElimination}, test.userId)
+
+			assert.Equal(t, test.err, err)
+		})
+	}
+}
@@ -1,17 +1,27 @@
 package services
 
 import (
+	"fmt"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"math/rand"
+	"sort"
 	"tiktok-arena/internal/core/contests"
 	"tiktok-arena/internal/core/dtos"
 	"tiktok-arena/internal/core/models"
+	"tiktok-arena/internal/core/ratings"
+	"tiktok-arena/internal/core/tiers"
 	"tiktok-arena/internal/core/validator"
+	"time"
 )
 
+// MinContestTiktoks count of tiktoks contest can't be played without
+const MinContestTiktoks = 2
+
 type TournamentServiceTournamentRepository interface {
+	GetTournamentById(tournamentId uuid.UUID) (models.Tournament, error)
 	GetTournamentWithUserById(tournamentId uuid.UUID) (models.Tournament, error)
-	GetAllTournamentsWithUsers(totalTournaments int64, queries dtos.PaginationQueries) (dtos.TournamentsResponse, error)
+	GetAllTournamentsWithUsers(queries dtos.PaginationQueries, isPrivate bool) ([]models.Tournament, error)
 	CheckIfTournamentExistsByName(name string) (bool, error)
 	CheckIfNameIsTakenByOtherTournament(name string, id uuid.UUID) (bool, error)
 	CheckIfTournamentExistsById(id uuid.UUID) (bool, error)
@@ -29,25 +39,48 @@ type TournamentServiceTiktokRepository interface {
 	CreateNewTiktoks(t []models.Tiktok) error
 	EditTiktok(t models.Tiktok) error
 	DeleteTiktoks(t []models.Tiktok) error
-	DeleteTiktoksByIds(ids []string) error
 	GetTournamentTiktoksById(tournamentId uuid.UUID) ([]models.Tiktok, error)
+	CheckIfTiktokExists(tournamentId uuid.UUID, tiktokURL string) (bool, error)
 	UpdateTiktokWins(tournamentId uuid.UUID, tiktokURL string) error
+	UpdateTiktoksAppearances(tournamentId uuid.UUID, tiktokURLs []string) error
 }
 
 type TournamentServiceUserRepository interface {
 	GetUserByID(id uuid.UUID) (user models.User, err error)
 }
 
+type TournamentServicePlaySessionRepository interface {
+	GetPlaySessionById(id uuid.UUID) (models.PlaySession, error)
+	GetTournamentRankings(tournamentId uuid.UUID) ([]models.SessionRanking, error)
+}
+
+type TournamentServiceMatchupRepository interface {
+	GetTournamentMatchups(tournamentId uuid.UUID) ([]models.Matchup, error)
+}
+
+type TournamentServiceTierListRepository interface {
+	CreateTierList(tierList *models.TierList) error
+	GetTournamentTierPlacements(tournamentId uuid.UUID) ([]models.TierPlacement, error)
+}
+
 type TournamentService struct {
-	TournamentRepository TournamentServiceTournamentRepository
-	TiktokRepository     TournamentServiceTiktokRepository
-	UserRepository       TournamentServiceUserRepository
+	TournamentRepository  TournamentServiceTournamentRepository
+	TiktokRepository      TournamentServiceTiktokRepository
+	UserRepository        TournamentServiceUserRepository
+	PlaySessionRepository TournamentServicePlaySessionRepository
+	MatchupRepository     TournamentServiceMatchupRepository
+	TierListRepository    TournamentServiceTierListRepository
 }
 
 func NewTournamentService(tournamentRepository TournamentServiceTournamentRepository,
 	tiktokRepository TournamentServiceTiktokRepository,
-	userRepository TournamentServiceUserRepository) *TournamentService {
-	return &TournamentService{TournamentRepository: tournamentRepository, TiktokRepository: tiktokRepository, UserRepository: userRepository}
+	userRepository TournamentServiceUserRepository,
+	playSessionRepository TournamentServicePlaySessionRepository,
+	matchupRepository TournamentServiceMatchupRepository,
+	tierListRepository TournamentServiceTierListRepository) *TournamentService {
+	return &TournamentService{TournamentRepository: tournamentRepository, TiktokRepository: tiktokRepository,
+		UserRepository: userRepository, PlaySessionRepository: playSessionRepository, MatchupRepository: matchupRepository,
+		TierListRepository: tierListRepository}
 }
 
 func (s *TournamentService) GetTournaments(queries dtos.PaginationQueries) (response dtos.TournamentsResponse, err error) {
@@ -55,26 +88,26 @@ func (s *TournamentService) GetTournaments(queries dtos.PaginationQueries) (resp
 	if err != nil {
 		return response, RepositoryError{err}
 	}
-	response, err = s.TournamentRepository.GetAllTournamentsWithUsers(countTournaments, queries)
+	tournaments, err := s.TournamentRepository.GetAllTournamentsWithUsers(queries, false)
 	if err != nil {
 		return response, RepositoryError{err}
 	}
-	return
+	return dtos.TournamentsResponse{TournamentCount: countTournaments, Tournaments: dtos.NewTournamentViews(tournaments)}, nil
 }
 
-func (s *TournamentService) GetTournament(tournamentIdString string) (tournament models.Tournament, err error) {
+func (s *TournamentService) GetTournament(tournamentIdString string) (view dtos.TournamentView, err error) {
 	if tournamentIdString == "" {
-		return tournament, EmptyTournamentIdError{}
+		return view, EmptyTournamentIdError{}
 	}
 	tournamentIdUUID, err := uuid.Parse(tournamentIdString)
 	if err != nil {
-		return tournament, UUIDError{err}
+		return view, UUIDError{err}
 	}
-	tournament, err = s.TournamentRepository.GetTournamentWithUserById(tournamentIdUUID)
+	tournament, err := s.TournamentRepository.GetTournamentWithUserById(tournamentIdUUID)
 	if err != nil {
-		return tournament, RepositoryError{err}
+		return view, RepositoryError{err}
 	}
-	return
+	return dtos.NewTournamentView(tournament), nil
 }
 
 func (s *TournamentService) CreateTournament(create dtos.CreateTournament, userId uuid.UUID) error {
@@ -83,7 +116,11 @@ func (s *TournamentService) CreateTournament(create dtos.CreateTournament, userI
 		return ValidateError{err}
 	}
 
-	if create.Size != len(create.Tiktoks) {
+	// Tournament is pool of tiktoks, every play draws Size tiktoks from it
+	if create.Size == 0 {
+		create.Size = len(create.Tiktoks)
+	}
+	if create.Size > len(create.Tiktoks) {
 		return TournamentSizeAndTiktokCountMismatchError{create.Size, len(create.Tiktoks)}
 	}
 
@@ -101,12 +138,15 @@ func (s *TournamentService) CreateTournament(create dtos.CreateTournament, userI
 	}
 
 	newTournament := models.Tournament{
-		ID:        newTournamentId,
-		Name:      create.Name,
-		UserID:    userId,
-		Size:      create.Size,
-		PhotoURL:  create.PhotoURL,
-		IsPrivate: create.IsPrivate,
+		ID:           newTournamentId,
+		Name:         create.Name,
+		UserID:       userId,
+		Size:         create.Size,
+		PhotoURL:     create.PhotoURL,
+		IsPrivate:    create.IsPrivate,
+		RatingSystem: create.RatingSystem,
+		BestOf:       create.BestOf,
+		Tiers:        create.Tiers,
 	}
 	err = s.TournamentRepository.CreateNewTournament(newTournament)
 	if err != nil {
@@ -135,7 +175,11 @@ func (s *TournamentService) EditTournament(edit dtos.EditTournament, userId uuid
 		return ValidateError{err}
 	}
 
-	if edit.Size != len(edit.Tiktoks) {
+	// Tournament is pool of tiktoks, every play draws Size tiktoks from it
+	if edit.Size == 0 {
+		edit.Size = len(edit.Tiktoks)
+	}
+	if edit.Size > len(edit.Tiktoks) {
 		return TournamentSizeAndTiktokCountMismatchError{edit.Size, len(edit.Tiktoks)}
 	}
 
@@ -172,12 +216,15 @@ func (s *TournamentService) EditTournament(edit dtos.EditTournament, userId uuid
 	}
 
 	editedTournament := models.Tournament{
-		ID:        tournamentIdUUID,
-		Name:      edit.Name,
-		UserID:    userId,
-		Size:      edit.Size,
-		PhotoURL:  edit.PhotoURL,
-		IsPrivate: edit.IsPrivate,
+		ID:           tournamentIdUUID,
+		Name:         edit.Name,
+		UserID:       userId,
+		Size:         edit.Size,
+		PhotoURL:     edit.PhotoURL,
+		IsPrivate:    edit.IsPrivate,
+		RatingSystem: edit.RatingSystem,
+		BestOf:       edit.BestOf,
+		Tiers:        edit.Tiers,
 	}
 
 	err = s.TournamentRepository.EditTournament(editedTournament)
@@ -240,17 +287,7 @@ func (s *TournamentService) DeleteTournament(userId uuid.UUID, tournamentIdStrin
 		return TournamentNotExistsError{}
 	}
 
-	// Get tiktoks to delete
-	tiktoksToDelete, err := s.TiktokRepository.GetTournamentTiktoksById(tournamentIdUUID)
-	if err != nil {
-		return RepositoryError{err}
-	}
-
-	err = s.TiktokRepository.DeleteTiktoks(tiktoksToDelete)
-	if err != nil {
-		return RepositoryError{err}
-	}
-
+	// Tiktoks, play sessions and statistics are deleted with tournament
 	err = s.TournamentRepository.DeleteTournamentById(tournamentIdUUID, userId)
 	if err != nil {
 		return RepositoryError{err}
@@ -272,10 +309,6 @@ func (s *TournamentService) DeleteTournaments(userId uuid.UUID, tournamentIds dt
 	if !exists {
 		return TournamentNotExistsError{}
 	}
-	err = s.TiktokRepository.DeleteTiktoksByIds(ids)
-	if err != nil {
-		return RepositoryError{err}
-	}
 	err = s.TournamentRepository.DeleteTournamentsByIds(ids, userId)
 	if err != nil {
 		return RepositoryError{err}
@@ -283,38 +316,270 @@ func (s *TournamentService) DeleteTournaments(userId uuid.UUID, tournamentIds dt
 	return nil
 }
 
-func (s *TournamentService) GetTournamentStats(tournamentIdString string) (tournamentStats dtos.TournamentStats, err error) {
+func (s *TournamentService) GetTournamentStats(tournamentIdString string, queries dtos.StatsQueries) (tournamentStats dtos.TournamentStats, err error) {
 	if tournamentIdString == "" {
 		return tournamentStats, EmptyTournamentIdError{}
 	}
+	err = validator.ValidateStruct(queries)
+	if err != nil {
+		return tournamentStats, ValidateError{err}
+	}
 	tournamentIdUUID, err := uuid.Parse(tournamentIdString)
 	if err != nil {
 		return tournamentStats, UUIDError{err}
 	}
+	tournament, err := s.TournamentRepository.GetTournamentById(tournamentIdUUID)
+	if err == gorm.ErrRecordNotFound {
+		return tournamentStats, TournamentNotExistsError{tournamentIdUUID}
+	}
+	if err != nil {
+		return tournamentStats, RepositoryError{err}
+	}
 	tiktoks, err := s.TiktokRepository.GetTournamentTiktoksById(tournamentIdUUID)
 	if err != nil {
 		return tournamentStats, RepositoryError{err}
 	}
+	matchups, err := s.MatchupRepository.GetTournamentMatchups(tournamentIdUUID)
+	if err != nil {
+		return tournamentStats, RepositoryError{err}
+	}
+	matches := make(map[string]int)
+	matchWins := make(map[string]int)
+	for _, matchup := range matchups {
+		matches[matchup.FirstURL] += matchup.FirstWins + matchup.SecondWins
+		matches[matchup.SecondURL] += matchup.FirstWins + matchup.SecondWins
+		matchWins[matchup.FirstURL] += matchup.FirstWins
+		matchWins[matchup.SecondURL] += matchup.SecondWins
+	}
+	rankings, err := s.PlaySessionRepository.GetTournamentRankings(tournamentIdUUID)
+	if err != nil {
+		return tournamentStats, RepositoryError{err}
+	}
+	rankSums := make(map[string]int)
+	rankCounts := make(map[string]int)
+	for _, ranking := range rankings {
+		rankSums[ranking.TiktokURL] += ranking.Rank
+		rankCounts[ranking.TiktokURL]++
+	}
+	averageRanks := make(map[string]float64, len(rankCounts))
+	for url, count := range rankCounts {
+		averageRanks[url] = float64(rankSums[url]) / float64(count)
+	}
+
 	tournamentStats.TournamentId = tournamentIdUUID
+	tournamentStats.RatingSystem = ratings.EloSystem
+	if tournament.RatingSystem != "" {
+		tournamentStats.RatingSystem = tournament.RatingSystem
+	}
 	for _, tiktok := range tiktoks {
 		tournamentStats.TiktoksStats = append(tournamentStats.TiktoksStats, dtos.TiktokStats{
-			Name: tiktok.Name,
-			URL:  tiktok.URL,
-			Wins: tiktok.Wins,
+			Name:            tiktok.Name,
+			URL:             tiktok.URL,
+			Wins:            tiktok.Wins,
+			Appearances:     tiktok.Appearances,
+			SecondPlaces:    tiktok.SecondPlaces,
+			ThirdPlaces:     tiktok.ThirdPlaces,
+			AverageRank:     averageRanks[tiktok.URL],
+			Rankings:        rankCounts[tiktok.URL],
+			Matches:         matches[tiktok.URL],
+			MatchWins:       matchWins[tiktok.URL],
+			Rating:          tiktok.Rating,
+			RatingDeviation: tiktok.RatingDeviation,
+		})
+	}
+
+	tournamentStats.TierList, err = s.tierListStats(tournament, tiktoks)
+	if err != nil {
+		return tournamentStats, err
+	}
+
+	stats := tournamentStats.TiktoksStats
+	switch queries.Sort {
+	case dtos.SortByWins:
+		sort.SliceStable(stats, func(i, j int) bool { return stats[i].Wins > stats[j].Wins })
+	case dtos.SortByRating:
+		sort.SliceStable(stats, func(i, j int) bool { return stats[i].Rating > stats[j].Rating })
+	case dtos.SortByRank:
+		// Tiktoks without rankings are placed last
+		sort.SliceStable(stats, func(i, j int) bool {
+			if stats[i].Rankings == 0 || stats[j].Rankings == 0 {
+				return stats[j].Rankings == 0 && stats[i].Rankings != 0
+			}
+			return stats[i].AverageRank < stats[j].AverageRank
+		})
+	}
+
+	return
+}
+
+// tierListStats aggregates tier lists of tournament into tier distribution of every tiktok
+func (s *TournamentService) tierListStats(tournament models.Tournament, tiktoks []models.Tiktok) (stats dtos.TierListStats, err error) {
+	placements, err := s.TierListRepository.GetTournamentTierPlacements(tournament.ID)
+	if err != nil {
+		return stats, RepositoryError{err}
+	}
+	tierLists := make(map[uuid.UUID]bool)
+	tierPlacements := make([]tiers.Placement, 0, len(placements))
+	for _, placement := range placements {
+		tierLists[placement.TierListID] = true
+		tierPlacements = append(tierPlacements, tiers.Placement{TiktokURL: placement.TiktokURL, Tier: placement.Tier})
+	}
+	names := make(map[string]string, len(tiktoks))
+	tiktokURLs := make([]string, 0, len(tiktoks))
+	for _, tiktok := range tiktoks {
+		names[tiktok.URL] = tiktok.Name
+		tiktokURLs = append(tiktokURLs, tiktok.URL)
+	}
+
+	stats.Tiers = tournament.GetTiers()
+	stats.CountTierList = len(tierLists)
+	stats.TiktoksTiers = make([]dtos.TiktokTiers, 0, len(tiktoks))
+	for _, distribution := range tiers.Aggregate(stats.Tiers, tiktokURLs, tierPlacements) {
+		stats.TiktoksTiers = append(stats.TiktoksTiers, dtos.TiktokTiers{
+			Name:          names[distribution.TiktokURL],
+			URL:           distribution.TiktokURL,
+			Distribution:  distribution.Counts,
+			ConsensusTier: distribution.Consensus,
+		})
+	}
+	return
+}
+
+// SubmitTierList
+// Stores placements of all tournament tiktoks into tiers of tournament
+func (s *TournamentService) SubmitTierList(tournamentIdString string, submission dtos.TierListSubmission, userId uuid.UUID) error {
+	if tournamentIdString == "" {
+		return EmptyTournamentIdError{}
+	}
+
+	tournamentId, err := uuid.Parse(tournamentIdString)
+	if err != nil {
+		return UUIDError{err}
+	}
+
+	err = validator.ValidateStruct(submission)
+	if err != nil {
+		return ValidateError{err}
+	}
+
+	tournament, err := s.TournamentRepository.GetTournamentById(tournamentId)
+	if err == gorm.ErrRecordNotFound {
+		return TournamentNotExistsError{tournamentId}
+	}
+	if err != nil {
+		return RepositoryError{err}
+	}
+	if tournament.IsPrivate && tournament.UserID != userId {
+		return TournamentAccessDeniedError{tournamentId}
+	}
+
+	tiktoks, err := s.TiktokRepository.GetTournamentTiktoksById(tournamentId)
+	if err != nil {
+		return RepositoryError{err}
+	}
+	tiktokURLs := make([]string, 0, len(tiktoks))
+	for _, tiktok := range tiktoks {
+		tiktokURLs = append(tiktokURLs, tiktok.URL)
+	}
+	tierPlacements := make([]tiers.Placement, 0, len(submission.Placements))
+	for _, placement := range submission.Placements {
+		tierPlacements = append(tierPlacements, tiers.Placement{TiktokURL: placement.TiktokURL, Tier: placement.Tier})
+	}
+	err = tiers.ValidatePlacements(tournament.GetTiers(), tiktokURLs, tierPlacements)
+	if err != nil {
+		return TierListError{err}
+	}
+
+	tierList := models.TierList{
+		ID:           uuid.New(),
+		TournamentID: tournamentId,
+	}
+	if userId != uuid.Nil {
+		tierList.UserID = &userId
+	}
+	for _, placement := range submission.Placements {
+		tierList.Placements = append(tierList.Placements, models.TierPlacement{
+			TierListID:   tierList.ID,
+			TournamentID: tournamentId,
+			TiktokURL:    placement.TiktokURL,
+			Tier:         placement.Tier,
+		})
+	}
+	err = s.TierListRepository.CreateTierList(&tierList)
+	if err != nil {
+		return RepositoryError{err}
+	}
+	return nil
+}
+
+// GetTournamentMatrix
+// Returns head-to-head results for every pair of tiktoks which met in play sessions
+func (s *TournamentService) GetTournamentMatrix(tournamentIdString string) (matrix dtos.TournamentMatrix, err error) {
+	if tournamentIdString == "" {
+		return matrix, EmptyTournamentIdError{}
+	}
+	tournamentIdUUID, err := uuid.Parse(tournamentIdString)
+	if err != nil {
+		return matrix, UUIDError{err}
+	}
+	tiktoks, err := s.TiktokRepository.GetTournamentTiktoksById(tournamentIdUUID)
+	if err != nil {
+		return matrix, RepositoryError{err}
+	}
+	matchups, err := s.MatchupRepository.GetTournamentMatchups(tournamentIdUUID)
+	if err != nil {
+		return matrix, RepositoryError{err}
+	}
+
+	matrix.TournamentId = tournamentIdUUID
+	matrix.TiktokURLs = make([]string, 0, len(tiktoks))
+	matrix.WinRates = make([][]float64, 0, len(tiktoks))
+	positions := make(map[string]int, len(tiktoks))
+	for i, tiktok := range tiktoks {
+		positions[tiktok.URL] = i
+		matrix.TiktokURLs = append(matrix.TiktokURLs, tiktok.URL)
+		winRates := make([]float64, len(tiktoks))
+		for j := range winRates {
+			winRates[j] = -1
+		}
+		matrix.WinRates = append(matrix.WinRates, winRates)
+	}
+
+	matrix.Matchups = make([]dtos.Matchup, 0, len(matchups))
+	for _, matchup := range matchups {
+		matrix.Matchups = append(matrix.Matchups, dtos.Matchup{
+			FirstTiktokURL:  matchup.FirstURL,
+			SecondTiktokURL: matchup.SecondURL,
+			FirstWins:       matchup.FirstWins,
+			SecondWins:      matchup.SecondWins,
 		})
+		first, firstOk := positions[matchup.FirstURL]
+		second, secondOk := positions[matchup.SecondURL]
+		total := matchup.FirstWins + matchup.SecondWins
+		if !firstOk || !secondOk || total == 0 { // Tiktok could be deleted from tournament
+			continue
+		}
+		matrix.WinRates[first][second] = float64(matchup.FirstWins) / float64(total)
+		matrix.WinRates[second][first] = float64(matchup.SecondWins) / float64(total)
 	}
 
 	return
 }
 
-func (s *TournamentService) TournamentWinner(tournamentIdString string, winner dtos.TournamentWinner) error {
+// TournamentWinner
+// Registers winner of played tournament. If play session is provided, its winner is only verified,
+// because statistics of play session are updated when it is completed.
+func (s *TournamentService) TournamentWinner(tournamentIdString string, winner dtos.TournamentWinner, userId uuid.UUID) error {
 	if tournamentIdString == "" {
 		return EmptyTournamentIdError{}
 	}
 
-	tournamentId, _ := uuid.Parse(tournamentIdString) // Ignore error, because error will be handled later in validate
+	tournamentId, err := uuid.Parse(tournamentIdString)
+	if err != nil {
+		return UUIDError{err}
+	}
 
-	err := validator.ValidateStruct(winner)
+	err = validator.ValidateStruct(winner)
 	if err != nil {
 		return ValidateError{err}
 	}
@@ -323,6 +588,43 @@ func (s *TournamentService) TournamentWinner(tournamentIdString string, winner d
 		return EmptyTiktokURLError{}
 	}
 
+	tournament, err := s.TournamentRepository.GetTournamentById(tournamentId)
+	if err == gorm.ErrRecordNotFound {
+		return TournamentNotExistsError{tournamentId}
+	}
+	if err != nil {
+		return RepositoryError{err}
+	}
+	if tournament.IsPrivate && tournament.UserID != userId {
+		return TournamentAccessDeniedError{tournamentId}
+	}
+
+	exists, err := s.TiktokRepository.CheckIfTiktokExists(tournamentId, winner.TiktokURL)
+	if err != nil {
+		return RepositoryError{err}
+	}
+	if !exists {
+		return TiktokNotInTournamentError{TiktokURL: winner.TiktokURL, TournamentId: tournamentId}
+	}
+
+	if winner.SessionID != "" {
+		return s.verifyPlaySessionWinner(tournamentId, winner)
+	}
+
+	if len(winner.Participators) != 0 {
+		if !containsURL(winner.Participators, winner.TiktokURL) {
+			return ContestResultsError{fmt.Errorf("winner %s wasn't drawn for play", winner.TiktokURL)}
+		}
+		tiktoks, err := s.TiktokRepository.GetTournamentTiktoksById(tournamentId)
+		if err != nil {
+			return RepositoryError{err}
+		}
+		_, err = selectTiktoks(tiktoks, winner.Participators)
+		if err != nil {
+			return ContestResultsError{err}
+		}
+	}
+
 	err = s.TournamentRepository.UpdateTournamentTimesPlayed(tournamentId)
 	if err != nil {
 		return RepositoryError{err}
@@ -332,32 +634,216 @@ func (s *TournamentService) TournamentWinner(tournamentIdString string, winner d
 	if err != nil {
 		return RepositoryError{err}
 	}
+
+	err = s.TiktokRepository.UpdateTiktoksAppearances(tournamentId, winner.Participators)
+	if err != nil {
+		return RepositoryError{err}
+	}
+	return nil
+}
+
+func (s *TournamentService) verifyPlaySessionWinner(tournamentId uuid.UUID, winner dtos.TournamentWinner) error {
+	sessionId, err := uuid.Parse(winner.SessionID)
+	if err != nil {
+		return UUIDError{err}
+	}
+	session, err := s.PlaySessionRepository.GetPlaySessionById(sessionId)
+	if err == gorm.ErrRecordNotFound || (err == nil && session.TournamentID != tournamentId) {
+		return PlaySessionNotExistsError{sessionId}
+	}
+	if err != nil {
+		return RepositoryError{err}
+	}
+	if !session.IsCompleted {
+		return PlaySessionNotCompletedError{sessionId}
+	}
+	if session.WinnerURL != winner.TiktokURL {
+		return PlaySessionWinnerMismatchError{SessionId: sessionId, TiktokURL: winner.TiktokURL}
+	}
 	return nil
 }
 
-func (s *TournamentService) GetTournamentContest(tournamentIdString string, bracketType string) (bracket dtos.Contest, err error) {
+func (s *TournamentService) GetTournamentContest(tournamentIdString string, payload dtos.ContestPayload, userId uuid.UUID) (bracket dtos.Contest, err error) {
+	if tournamentIdString == "" {
+		return bracket, EmptyTournamentIdError{}
+	}
+
+	err = validator.ValidateStruct(payload)
+	if err != nil {
+		return bracket, ValidateError{err}
+	}
+
+	if !dtos.CheckIfAllowedContestType(payload.Type) {
+		return bracket, NotAllowedContestTypeError{payload.Type}
+	}
+	tournamentId, err := uuid.Parse(tournamentIdString)
+	if err != nil {
+		return bracket, UUIDError{err}
+	}
+
+	tournament, err := s.TournamentRepository.GetTournamentById(tournamentId)
+	if err == gorm.ErrRecordNotFound {
+		return bracket, TournamentNotExistsError{tournamentId}
+	}
+	if err != nil {
+		return bracket, RepositoryError{err}
+	}
+	if tournament.IsPrivate && tournament.UserID != userId {
+		return bracket, TournamentAccessDeniedError{tournamentId}
+	}
+
+	tiktoks, err := s.TiktokRepository.GetTournamentTiktoksById(tournamentId)
+	if err != nil {
+		return bracket, RepositoryError{err}
+	}
+	if len(tiktoks) < MinContestTiktoks {
+		return bracket, NotEnoughTiktoksError{TiktokCount: len(tiktoks)}
+	}
+	return newContest(tournament, tiktoks, payload), err
+}
+
+func (s *TournamentService) GetNextContestRound(tournamentIdString string, results dtos.ContestResults, userId uuid.UUID) (bracket dtos.Contest, err error) {
 	if tournamentIdString == "" {
 		return bracket, EmptyTournamentIdError{}
 	}
 
-	if !dtos.CheckIfAllowedContestType(bracketType) {
-		return bracket, NotAllowedContestTypeError{bracketType}
+	err = validator.ValidateStruct(results)
+	if err != nil {
+		return bracket, ValidateError{err}
+	}
+
+	if !dtos.CheckIfServerPairedContestType(results.Type) {
+		return bracket, NotAllowedContestTypeError{results.Type}
 	}
 	tournamentId, err := uuid.Parse(tournamentIdString)
 	if err != nil {
 		return bracket, UUIDError{err}
 	}
 
+	tournament, err := s.TournamentRepository.GetTournamentById(tournamentId)
+	if err == gorm.ErrRecordNotFound {
+		return bracket, TournamentNotExistsError{tournamentId}
+	}
+	if err != nil {
+		return bracket, RepositoryError{err}
+	}
+	if tournament.IsPrivate && tournament.UserID != userId {
+		return bracket, TournamentAccessDeniedError{tournamentId}
+	}
+
 	tiktoks, err := s.TiktokRepository.GetTournamentTiktoksById(tournamentId)
 	if err != nil {
 		return bracket, RepositoryError{err}
 	}
-	models.ShuffleTiktok(tiktoks)
-	if bracketType == dtos.SingleElimination {
-		return contests.SingleElimination(tiktoks), err
+	if len(results.Participators) != 0 {
+		tiktoks, err = selectTiktoks(tiktoks, results.Participators)
+		if err != nil {
+			return bracket, ContestResultsError{err}
+		}
 	}
-	if bracketType == dtos.KingOfTheHill {
-		return contests.KingOfTheHill(tiktoks), err
+	if len(tiktoks) < MinContestTiktoks {
+		return bracket, NotEnoughTiktoksError{TiktokCount: len(tiktoks)}
+	}
+	options := contests.Options{Seed: results.Seed}
+	if results.Type == dtos.FullRanking {
+		bracket, err = contests.FullRankingNextRound(tiktoks, results.Results, options)
+	} else {
+		bracket, err = contests.SwissNextRound(tiktoks, results.Results, options)
+	}
+	if err != nil {
+		return bracket, ContestResultsError{err}
 	}
 	return
 }
+
+// newContest generates contest of allowed type from tiktoks drawn from pool of tournament and ordered by seeding of payload,
+// draw, shuffle and match IDs are derived from seed of payload or from random seed if it is not set
+func newContest(tournament models.Tournament, tiktoks []models.Tiktok, payload dtos.ContestPayload) dtos.Contest {
+	seed := time.Now().UnixNano() & dtos.MaxSeed
+	if payload.Seed != nil {
+		seed = *payload.Seed
+	}
+	r := rand.New(rand.NewSource(seed))
+
+	size := payload.Size
+	if size == 0 {
+		size = tournament.Size
+	}
+	if size > 0 && size < len(tiktoks) {
+		models.ShuffleTiktok(tiktoks, r)
+		tiktoks = tiktoks[:size]
+	}
+
+	seedTiktoks(tiktoks, payload.Seeding, r)
+	options := contests.Options{
+		Seed:         seed,
+		BracketReset: payload.BracketReset,
+		ThirdPlace:   payload.ThirdPlace,
+		Advance:      payload.Advance,
+		BestOf:       tournament.BestOf,
+	}
+	switch payload.Type {
+	case dtos.SingleElimination:
+		return contests.SingleElimination(tiktoks, options)
+	case dtos.KingOfTheHill:
+		return contests.KingOfTheHill(tiktoks, options)
+	case dtos.DoubleElimination:
+		return contests.DoubleElimination(tiktoks, options)
+	case dtos.RoundRobin:
+		return contests.RoundRobin(tiktoks, options)
+	case dtos.Swiss:
+		return contests.Swiss(tiktoks, options)
+	case dtos.GroupsKnockout:
+		return contests.GroupsKnockout(tiktoks, options)
+	case dtos.FullRanking:
+		return contests.FullRanking(tiktoks, options)
+	}
+	return dtos.Contest{}
+}
+
+func containsURL(urls []string, url string) bool {
+	for _, u := range urls {
+		if u == url {
+			return true
+		}
+	}
+	return false
+}
+
+// selectTiktoks returns tiktoks with URLs in order of URLs
+func selectTiktoks(tiktoks []models.Tiktok, urls []string) ([]models.Tiktok, error) {
+	byURL := make(map[string]models.Tiktok, len(tiktoks))
+	for _, tiktok := range tiktoks {
+		byURL[tiktok.URL] = tiktok
+	}
+	selected := make([]models.Tiktok, 0, len(urls))
+	for _, url := range urls {
+		tiktok, ok := byURL[url]
+		if !ok {
+			return nil, fmt.Errorf("tiktok %s doesn't belong to tournament", url)
+		}
+		selected = append(selected, tiktok)
+	}
+	return selected, nil
+}
+
+// seedTiktoks orders tiktoks from top seed to bottom seed, ties are ordered randomly
+func seedTiktoks(tiktoks []models.Tiktok, seeding string, r *rand.Rand) {
+	models.ShuffleTiktok(tiktoks, r)
+	switch seeding {
+	case dtos.WinsSeeding:
+		sort.SliceStable(tiktoks, func(i, j int) bool {
+			return tiktoks[i].Wins > tiktoks[j].Wins
+		})
+	case dtos.WinrateSeeding:
+		winrate := func(t models.Tiktok) float64 {
+			if t.Appearances == 0 {
+				return 0
+			}
+			return float64(t.Wins) / float64(t.Appearances)
+		}
+		sort.SliceStable(tiktoks, func(i, j int) bool {
+			return winrate(tiktoks[i]) > winrate(tiktoks[j])
+		})
+	}
+}
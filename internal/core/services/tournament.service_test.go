@@ -0,0 +1,103 @@
+package services
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+)
+
+func TestNewContestSeed(t *testing.T) {
+	zero, large := int64(0), int64(dtos.MaxSeed)
+	tests := []struct {
+		name string
+		seed *int64
+	}{
+		{name: "explicit zero seed is used", seed: &zero},
+		{name: "largest seed is used", seed: &large},
+		{name: "random seed fits in 53 bits"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var tiktoks []models.Tiktok
+			for i := 0; i < 4; i++ {
+				tiktoks = append(tiktoks, models.Tiktok{URL: fmt.Sprint("testurl", i)})
+			}
+			payload := dtos.ContestPayload{Type: dtos.SingleElimination, Seed: test.seed}
+
+			contest := newContest(models.Tournament{}, tiktoks, payload)
+
+			if test.seed != nil {
+				assert.Equal(t, *test.seed, contest.Seed)
+			} else {
+				assert.GreaterOrEqual(t, contest.Seed, int64(0))
+				assert.LessOrEqual(t, contest.Seed, int64(dtos.MaxSeed))
+			}
+		})
+	}
+}
+
+func TestTournamentWinnerRejectsInvalidParticipators(t *testing.T) {
+	tiktoks := []models.Tiktok{{URL: "first"}, {URL: "second"}, {URL: "third"}}
+	service := NewTournamentService(&fakeTournamentRepository{}, &fakeTiktokRepository{tiktoks: tiktoks},
+		nil, nil, nil, nil)
+
+	tests := []struct {
+		name          string
+		participators []string
+		err           error
+	}{
+		{name: "single participator", participators: []string{"first"}, err: ValidateError{}},
+		{name: "winner wasn't drawn", participators: []string{"second", "third"}, err: ContestResultsError{}},
+		{name: "participator isn't in tournament", participators: []string{"first", "unknown"}, err: ContestResultsError{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			winner := dtos.TournamentWinner{TiktokURL: "first", Participators: test.participators}
+
+			err := service.TournamentWinner(uuid.New().String(), winner, uuid.New())
+
+			assert.IsType(t, test.err, err)
+		})
+	}
+}
+
+func TestGetNextContestRoundWithoutEnoughTiktoks(t *testing.T) {
+	for _, tiktoks := range [][]models.Tiktok{nil, {{URL: "first"}}} {
+		service := NewTournamentService(&fakeTournamentRepository{}, &fakeTiktokRepository{tiktoks: tiktoks},
+			nil, nil, nil, nil)
+
+		_, err := service.GetNextContestRound(uuid.New().String(), dtos.ContestResults{Type: dtos.Swiss}, uuid.Nil)
+
+		assert.Equal(t, NotEnoughTiktoksError{TiktokCount: len(tiktoks)}, err)
+	}
+}
+
+func TestGetTournamentContestOfPrivateTournament(t *testing.T) {
+	owner := uuid.New()
+	tiktoks := []models.Tiktok{{URL: "first"}, {URL: "second"}}
+	service := NewTournamentService(&fakeTournamentRepository{privateOwner: &owner}, &fakeTiktokRepository{tiktoks: tiktoks},
+		nil, nil, nil, nil)
+	tournamentId := uuid.New()
+
+	tests := []struct {
+		name   string
+		userId uuid.UUID
+		err    error
+	}{
+		{name: "owner", userId: owner},
+		{name: "other user", userId: uuid.New(), err: TournamentAccessDeniedError{tournamentId}},
+		{name: "anonymous", userId: uuid.Nil, err: TournamentAccessDeniedError{tournamentId}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := service.GetTournamentContest(tournamentId.String(), dtos.ContestPayload{Type: dtos.SingleElimination}, test.userId)
+			assert.Equal(t, test.err, err)
+
+			_, err = service.GetNextContestRound(tournamentId.String(), dtos.ContestResults{Type: dtos.Swiss}, test.userId)
+			assert.Equal(t, test.err, err)
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"tiktok-arena/configuration"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+	"tiktok-arena/internal/core/totp"
+	"tiktok-arena/internal/core/validator"
+	"time"
+)
+
+const (
+	// CountRecoveryCodes generated for user when 2FA is enabled
+	CountRecoveryCodes = 10
+	// MaxTwoFactorAttempts codes which can be entered for challenge, then user has to login again
+	MaxTwoFactorAttempts = 5
+)
+
+// loginOrChallenge issues tokens to user who logged in,
+// challenge token is issued instead if user has 2FA, so TOTP or recovery code is checked before tokens are issued
+func (s *AuthService) loginOrChallenge(user models.User) (details dtos.LoginDetails, err error) {
+	if !user.TOTPEnabled {
+		return s.loginDetails(user)
+	}
+
+	challengeToken, err := randomToken()
+	if err != nil {
+		return details, err
+	}
+	err = s.TokenRepository.CreateTwoFactorChallenge(&models.TwoFactorChallenge{
+		TokenHash: hashToken(challengeToken),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().UTC().Add(configuration.EnvConfig.TwoFactorChallengeExpiresIn),
+	})
+	if err != nil {
+		return details, RepositoryError{err}
+	}
+
+	return dtos.LoginDetails{
+		TwoFactorRequired: true,
+		ChallengeToken:    challengeToken,
+	}, nil
+}
+
+// LoginTwoFactor completes login of user with 2FA, challenge token is exchanged for tokens with TOTP or recovery code.
+// Wrong codes are counted as failed logins of user, so login is locked like after wrong passwords.
+func (s *AuthService) LoginTwoFactor(input dtos.TwoFactorLogin) (details dtos.LoginDetails, err error) {
+	err = validator.ValidateStruct(input)
+	if err != nil {
+		return details, ValidateError{err}
+	}
+
+	tokenHash := hashToken(input.ChallengeToken)
+	now := time.Now().UTC()
+	challenge, ok, err := s.TokenRepository.UseTwoFactorChallengeAttempt(tokenHash, now, MaxTwoFactorAttempts)
+	if err != nil {
+		return details, RepositoryError{err}
+	}
+	if !ok {
+		_, err = s.TokenRepository.DeleteTwoFactorChallenge(tokenHash)
+		if err != nil {
+			return details, RepositoryError{err}
+		}
+		return details, InvalidTwoFactorChallengeError{}
+	}
+
+	user, err := s.UserRepository.GetUserByID(challenge.UserID)
+	if err != nil {
+		return details, RepositoryError{err}
+	}
+	if user.ID == uuid.Nil || !user.TOTPEnabled {
+		return details, InvalidTwoFactorChallengeError{}
+	}
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		return details, LoginLockedError{Username: user.Name, RetryAfter: user.LockedUntil.Sub(now)}
+	}
+	if user.IsBanned {
+		return details, UserBannedError{Username: user.Name}
+	}
+
+	valid, err := s.checkTwoFactorCode(user, input.Code)
+	if err != nil {
+		return details, err
+	}
+	if !valid {
+		lockErr := s.recordFailedLogin(user, now)
+		if lockErr != nil {
+			return details, lockErr
+		}
+		return details, InvalidTwoFactorCodeError{}
+	}
+
+	deleted, err := s.TokenRepository.DeleteTwoFactorChallenge(tokenHash)
+	if err != nil {
+		return details, RepositoryError{err}
+	}
+	if !deleted {
+		return details, InvalidTwoFactorChallengeError{}
+	}
+	if user.FailedLogins != 0 {
+		err = s.UserRepository.UpdateUserFailedLogins(user.ID, 0, nil)
+		if err != nil {
+			return details, RepositoryError{err}
+		}
+	}
+
+	return s.loginDetails(user)
+}
+
+// EnrollTwoFactor generates new TOTP secret of user after password or recent login through provider is checked,
+// 2FA is enabled only after first code of secret is verified
+func (s *AuthService) EnrollTwoFactor(userId uuid.UUID, input dtos.TwoFactorEnrollInput) (enrollment dtos.TwoFactorEnrollment, err error) {
+	err = validator.ValidateStruct(input)
+	if err != nil {
+		return enrollment, ValidateError{err}
+	}
+
+	user, err := s.existingUser(userId)
+	if err != nil {
+		return enrollment, err
+	}
+	if user.TOTPEnabled {
+		return enrollment, TwoFactorAlreadyEnabledError{}
+	}
+	err = reauthenticate(user, input.Password)
+	if err != nil {
+		return enrollment, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return enrollment, err
+	}
+	err = s.UserRepository.SetUserTOTP(user.ID, secret, false)
+	if err != nil {
+		return enrollment, RepositoryError{err}
+	}
+
+	return dtos.TwoFactorEnrollment{
+		Secret:     secret,
+		OtpauthURI: totp.URI(configuration.EnvConfig.TwoFactorIssuer, user.Name, secret),
+	}, nil
+}
+
+// VerifyTwoFactor enables 2FA of user with code of enrolled secret and generates recovery codes
+func (s *AuthService) VerifyTwoFactor(userId uuid.UUID, input dtos.TwoFactorCode) (codes dtos.RecoveryCodes, err error) {
+	err = validator.ValidateStruct(input)
+	if err != nil {
+		return codes, ValidateError{err}
+	}
+
+	user, err := s.existingUser(userId)
+	if err != nil {
+		return codes, err
+	}
+	if user.TOTPEnabled {
+		return codes, TwoFactorAlreadyEnabledError{}
+	}
+	if user.TOTPSecret == "" {
+		return codes, TwoFactorNotEnabledError{}
+	}
+
+	counter, valid := totp.Validate(user.TOTPSecret, normalizeCode(input.Code), time.Now())
+	if !valid {
+		return codes, InvalidTwoFactorCodeError{}
+	}
+
+	codes, recoveryCodes, err := generateRecoveryCodes(user.ID)
+	if err != nil {
+		return codes, err
+	}
+	enabled, err := s.UserRepository.EnableUserTOTP(user.ID, user.TOTPSecret, counter, recoveryCodes)
+	if err != nil {
+		return codes, RepositoryError{err}
+	}
+	if !enabled {
+		return dtos.RecoveryCodes{}, InvalidTwoFactorCodeError{}
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor removes TOTP secret and recovery codes of user after password (or recent login through provider)
+// and code are checked
+func (s *AuthService) DisableTwoFactor(userId uuid.UUID, input dtos.TwoFactorDisable) error {
+	err := validator.ValidateStruct(input)
+	if err != nil {
+		return ValidateError{err}
+	}
+
+	user, err := s.existingUser(userId)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return TwoFactorNotEnabledError{}
+	}
+	err = reauthenticate(user, input.Password)
+	if err != nil {
+		return err
+	}
+	valid, err := s.checkTwoFactorCode(user, input.Code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return InvalidTwoFactorCodeError{}
+	}
+
+	err = s.UserRepository.SetUserTOTP(user.ID, "", false)
+	if err != nil {
+		return RepositoryError{err}
+	}
+	err = s.TokenRepository.DeleteRecoveryCodes(user.ID)
+	if err != nil {
+		return RepositoryError{err}
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces recovery codes of user after TOTP or recovery code is checked
+func (s *AuthService) RegenerateRecoveryCodes(userId uuid.UUID, input dtos.TwoFactorCode) (codes dtos.RecoveryCodes, err error) {
+	err = validator.ValidateStruct(input)
+	if err != nil {
+		return codes, ValidateError{err}
+	}
+
+	user, err := s.existingUser(userId)
+	if err != nil {
+		return codes, err
+	}
+	if !user.TOTPEnabled {
+		return codes, TwoFactorNotEnabledError{}
+	}
+	valid, err := s.checkTwoFactorCode(user, input.Code)
+	if err != nil {
+		return codes, err
+	}
+	if !valid {
+		return codes, InvalidTwoFactorCodeError{}
+	}
+
+	return s.newRecoveryCodes(user.ID)
+}
+
+// checkTwoFactorCode checks TOTP code or recovery code of user, every code can be used only once
+func (s *AuthService) checkTwoFactorCode(user models.User, code string) (bool, error) {
+	code = normalizeCode(code)
+
+	if len(code) == totp.Digits {
+		counter, valid := totp.Validate(user.TOTPSecret, code, time.Now())
+		if !valid {
+			return false, nil
+		}
+		used, err := s.UserRepository.UseUserTOTPCounter(user.ID, counter)
+		if err != nil {
+			return false, RepositoryError{err}
+		}
+		return used, nil
+	}
+
+	used, err := s.TokenRepository.UseRecoveryCode(user.ID, hashToken(code))
+	if err != nil {
+		return false, RepositoryError{err}
+	}
+	return used, nil
+}
+
+// newRecoveryCodes replaces recovery codes of user with new random codes
+func (s *AuthService) newRecoveryCodes(userId uuid.UUID) (codes dtos.RecoveryCodes, err error) {
+	codes, recoveryCodes, err := generateRecoveryCodes(userId)
+	if err != nil {
+		return codes, err
+	}
+	err = s.TokenRepository.ReplaceRecoveryCodes(userId, recoveryCodes)
+	if err != nil {
+		return dtos.RecoveryCodes{}, RepositoryError{err}
+	}
+	return codes, nil
+}
+
+// generateRecoveryCodes returns random codes shown to user and their hashes which are stored
+func generateRecoveryCodes(userId uuid.UUID) (codes dtos.RecoveryCodes, recoveryCodes []models.RecoveryCode, err error) {
+	recoveryCodes = make([]models.RecoveryCode, 0, CountRecoveryCodes)
+	for i := 0; i < CountRecoveryCodes; i++ {
+		bytes := make([]byte, 7)
+		_, err = rand.Read(bytes)
+		if err != nil {
+			return dtos.RecoveryCodes{}, nil, err
+		}
+		code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(bytes))[:10]
+		codes.RecoveryCodes = append(codes.RecoveryCodes, code[:5]+"-"+code[5:])
+		recoveryCodes = append(recoveryCodes, models.RecoveryCode{UserID: userId, CodeHash: hashToken(code)})
+	}
+	return codes, recoveryCodes, nil
+}
+
+// normalizeCode removes separators and spaces which users type with codes
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+func (s *AuthService) existingUser(userId uuid.UUID) (user models.User, err error) {
+	user, err = s.UserRepository.GetUserByID(userId)
+	if err != nil {
+		return user, RepositoryError{err}
+	}
+	if user.ID == uuid.Nil {
+		return user, UserNotExistsError{Username: userId.String()}
+	}
+	return user, nil
+}
+
+// reauthenticate checks password of user, user without password has to login through provider recently instead
+func reauthenticate(user models.User, password string) error {
+	if user.HasPassword() {
+		err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+		if err != nil {
+			return BcryptError{err}
+		}
+		return nil
+	}
+	if user.ProviderLoginAt == nil || time.Since(*user.ProviderLoginAt) > ReauthenticationMaxAge {
+		return ReauthenticationRequiredError{MaxAge: ReauthenticationMaxAge}
+	}
+	return nil
+}
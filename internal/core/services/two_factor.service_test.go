@@ -0,0 +1,64 @@
+package services
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"testing"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+	"time"
+)
+
+func TestEnrollTwoFactor(t *testing.T) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	assert.Nil(t, err)
+	recently, longAgo := time.Now().UTC().Add(-time.Minute), time.Now().UTC().Add(-time.Hour)
+
+	tests := []struct {
+		name  string
+		user  models.User
+		input dtos.TwoFactorEnrollInput
+		err   error
+	}{
+		{
+			name:  "password",
+			user:  models.User{Password: string(hashedPassword)},
+			input: dtos.TwoFactorEnrollInput{Password: "password"},
+		},
+		{
+			name:  "wrong password",
+			user:  models.User{Password: string(hashedPassword)},
+			input: dtos.TwoFactorEnrollInput{Password: "wrong"},
+			err:   BcryptError{bcrypt.ErrMismatchedHashAndPassword},
+		},
+		{
+			name: "without password after recent login with provider",
+			user: models.User{Password: models.NoPassword, ProviderLoginAt: &recently},
+		},
+		{
+			name: "without password after old login with provider",
+			user: models.User{Password: models.NoPassword, ProviderLoginAt: &longAgo},
+			err:  ReauthenticationRequiredError{MaxAge: ReauthenticationMaxAge},
+		},
+		{
+			name:  "without password, password of another account is ignored",
+			user:  models.User{Password: models.NoPassword},
+			input: dtos.TwoFactorEnrollInput{Password: "password"},
+			err:   ReauthenticationRequiredError{MaxAge: ReauthenticationMaxAge},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.user.ID = uuid.New()
+			userRepository := &fakeAccountUserRepository{user: test.user}
+			s := NewAuthService(userRepository, &fakeAccountTokenRepository{}, nil)
+
+			enrollment, err := s.EnrollTwoFactor(test.user.ID, test.input)
+
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, enrollment.Secret, userRepository.user.TOTPSecret)
+			assert.False(t, userRepository.user.TOTPEnabled)
+		})
+	}
+}
@@ -16,7 +16,7 @@ type UserServiceUserRepository interface {
 	ChangeUserPhoto(url string, id uuid.UUID) error
 	GetUserByID(id uuid.UUID) (user models.User, err error)
 	TotalUsers() (int64, error)
-	GetAllUsers(totalUsers int64, queries dtos.PaginationQueries) (dtos.UsersResponse, error)
+	GetAllUsers(queries dtos.PaginationQueries) ([]models.User, error)
 }
 
 type UserService struct {
@@ -33,11 +33,11 @@ func (s *UserService) GetUsers(queries dtos.PaginationQueries) (response dtos.Us
 	if err != nil {
 		return response, RepositoryError{err}
 	}
-	response, err = s.UserRepository.GetAllUsers(countUsers, queries)
+	users, err := s.UserRepository.GetAllUsers(queries)
 	if err != nil {
 		return response, RepositoryError{err}
 	}
-	return
+	return dtos.UsersResponse{UserCount: countUsers, Users: dtos.NewPublicUsers(users)}, nil
 }
 
 func (s *UserService) TournamentsOfUser(id uuid.UUID, queries dtos.PaginationQueries, hasAccessToPrivate bool) (response dtos.TournamentsResponseWithUser, err error) {
@@ -49,10 +49,11 @@ func (s *UserService) TournamentsOfUser(id uuid.UUID, queries dtos.PaginationQue
 	if err != nil {
 		return response, RepositoryError{err}
 	}
-	response.User, err = s.UserRepository.GetUserByID(id)
+	user, err := s.UserRepository.GetUserByID(id)
 	if err != nil {
 		return response, RepositoryError{err}
 	}
+	response.User = dtos.NewPublicUser(user)
 	return
 }
 
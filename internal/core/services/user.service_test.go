@@ -0,0 +1,376 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"tiktok-arena/internal/api/controllers"
+	"tiktok-arena/internal/core/dtos"
+	"tiktok-arena/internal/core/models"
+	"tiktok-arena/internal/core/totp"
+	"tiktok-arena/internal/data/mailer"
+	"tiktok-arena/internal/data/repository"
+	"time"
+)
+
+const (
+	testPasswordHash = "$2a$10$Qm0ypn8vF7b0Tq4yYbyv9eJbWzvcdm6Hn7y6YjGz0kR1xLh3tXrQ6"
+	testEmail        = "leak@example.com"
+	testTOTPSecret   = "JBSWY3DPEHPK3PXP"
+	testContest      = `{"leak":"contest"}`
+)
+
+var (
+	// userSecrets must never be in responses
+	userSecrets = []string{testPasswordHash, "password", testTOTPSecret, "totp", "isBanned", "failedLogins"}
+	// userPersonalData is only in export of user's own data
+	userPersonalData = []string{testEmail, "email", models.RoleAdmin, "role"}
+)
+
+// fakeIdentityProvider returns the same identity for every authorization code
+type fakeIdentityProvider struct {
+	identity dtos.ExternalIdentity
+}
+
+func (p fakeIdentityProvider) AuthorizationURL(string, string, string) (string, error) {
+	return "", nil
+}
+
+func (p fakeIdentityProvider) Exchange(string, string, string) (dtos.ExternalIdentity, error) {
+	return p.identity, nil
+}
+
+// UserViewSuite checks that endpoints returning users expose only public view of user
+// and endpoints returning data of current user don't expose its secrets
+type UserViewSuite struct {
+	suite.Suite
+	app    *fiber.App
+	mock   sqlmock.Sqlmock
+	db     *sql.DB
+	userId uuid.UUID
+}
+
+func TestUserViewSuite(t *testing.T) {
+	suite.Run(t, new(UserViewSuite))
+}
+
+func (us *UserViewSuite) TearDownTest() {
+	us.db.Close()
+}
+
+func (us *UserViewSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	assert.Nil(us.T(), err)
+	us.db = db
+	us.mock = mock
+	us.userId = uuid.New()
+
+	dialector := postgres.New(postgres.Config{
+		DSN:                  "sqlmock_db_0",
+		DriverName:           "postgres",
+		Conn:                 us.db,
+		PreferSimpleProtocol: true,
+	})
+	database, err := gorm.Open(dialector)
+	assert.Nil(us.T(), err)
+
+	userRepository := repository.NewUserRepository(database)
+	tournamentRepository := repository.NewTournamentRepository(database)
+	tokenRepository := repository.NewTokenRepository(database)
+	userController := controllers.NewUserController(NewUserService(userRepository, tournamentRepository))
+	tournamentController := controllers.NewTournamentController(NewTournamentService(tournamentRepository,
+		repository.NewTiktokRepository(database), userRepository, repository.NewPlaySessionRepository(database),
+		repository.NewMatchupRepository(database), repository.NewTierListRepository(database)))
+	adminController := controllers.NewAdminController(NewAdminService(tournamentRepository, userRepository, tokenRepository))
+	authService := NewAuthService(userRepository, tokenRepository, mailer.NewLogMailer(""))
+	authController := controllers.NewAuthController(authService)
+	oidcController := controllers.NewOIDCController(NewOIDCService(map[string]IdentityProvider{
+		"mock": fakeIdentityProvider{identity: dtos.ExternalIdentity{Subject: "subject", Email: testEmail}},
+	}, repository.NewIdentityRepository(database), authService))
+	accountController := controllers.NewAccountController(NewAccountService(repository.NewAccountRepository(database),
+		authService))
+
+	us.app = fiber.New(fiber.Config{})
+	us.app.Get("/api/user/users", userController.GetAllUsers)
+	us.app.Get("/api/user/profile/:userId", userController.UserInformation)
+	us.app.Get("/api/tournament/tournaments", tournamentController.GetAllTournaments)
+	us.app.Get("/api/tournament/details/:tournamentId", tournamentController.GetTournamentDetails)
+	us.app.Get("/api/admin/tournaments", adminController.GetAllTournaments)
+	us.app.Post("/api/auth/register", authController.RegisterUser)
+	us.app.Get("/api/auth/whoami", us.setUser, authController.WhoAmI)
+	us.app.Post("/api/auth/refresh", authController.RefreshToken)
+	us.app.Get("/api/auth/oidc/:provider/callback", oidcController.CompleteLogin)
+	us.app.Post("/api/auth/2fa/verify", us.setUser, authController.VerifyTwoFactor)
+	us.app.Get("/api/user/me/export", us.setUser, accountController.ExportAccount)
+}
+
+// setUser sets token of user like middleware.Protected does
+func (us *UserViewSuite) setUser(c *fiber.Ctx) error {
+	c.Locals("user", &jwt.Token{Raw: "test-token", Claims: jwt.MapClaims{"sub": us.userId.String(), "name": "test"}})
+	return c.Next()
+}
+
+func (us *UserViewSuite) TestGetAllUsers() {
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+		WillReturnRows(us.userRows())
+
+	body := us.get("/api/user/users")
+	us.assertPublicUser(body)
+}
+
+func (us *UserViewSuite) TestUserInformation() {
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tournaments"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	us.mock.ExpectQuery(regexp.QuoteMeta(`FROM "tournaments" WHERE user_id = $1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE id = $1`)).
+		WithArgs(us.userId).
+		WillReturnRows(us.userRows())
+
+	body := us.get("/api/user/profile/" + us.userId.String())
+	us.assertPublicUser(body)
+}
+
+func (us *UserViewSuite) TestGetAllTournaments() {
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tournaments"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	us.expectTournamentWithUser()
+
+	body := us.get("/api/tournament/tournaments")
+	us.assertPublicUser(body)
+}
+
+func (us *UserViewSuite) TestGetTournamentDetails() {
+	us.expectTournamentWithUser()
+
+	body := us.get("/api/tournament/details/" + uuid.NewString())
+	us.assertPublicUser(body)
+}
+
+func (us *UserViewSuite) TestAdminGetAllTournaments() {
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tournaments"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	us.expectTournamentWithUser()
+
+	body := us.get("/api/admin/tournaments")
+	us.assertPublicUser(body)
+}
+
+func (us *UserViewSuite) TestRegisterUser() {
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "users" WHERE name = $1`)).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	us.mock.ExpectBegin()
+	us.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users"`)).
+		WillReturnRows(us.userRows())
+	us.mock.ExpectCommit()
+	us.expectCreateRefreshToken()
+
+	body := us.send("POST", "/api/auth/register", dtos.AuthInput{Name: "test", Password: "testpassword"},
+		fiber.StatusCreated)
+	assert.Contains(us.T(), body, us.userId.String())
+	us.assertNotContains(body, userSecrets...)
+	us.assertNotContains(body, userPersonalData...)
+}
+
+func (us *UserViewSuite) TestWhoAmI() {
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "users" WHERE name = $1`)).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(us.userId))
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "photo_url" FROM "users" WHERE id = $1`)).
+		WithArgs(us.userId.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"photo_url"}).AddRow("https://example.com/photo.png"))
+
+	body := us.get("/api/auth/whoami")
+	us.assertPublicUser(body)
+}
+
+func (us *UserViewSuite) TestRefreshToken() {
+	refreshToken := "refresh"
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "refresh_tokens" WHERE token_hash = $1`)).
+		WithArgs(hashToken(refreshToken)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "family_id", "token_hash", "is_revoked", "expires_at"}).
+			AddRow(uuid.New(), us.userId, uuid.New(), hashToken(refreshToken), false, time.Now().Add(time.Hour)))
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE id = $1`)).
+		WithArgs(us.userId).
+		WillReturnRows(us.userRows())
+	us.mock.ExpectBegin()
+	us.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "refresh_tokens" SET "is_revoked"=$1 WHERE id = $2 AND is_revoked = $3`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	us.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "refresh_tokens"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "is_revoked"}).AddRow(uuid.New(), false))
+	us.mock.ExpectCommit()
+
+	body := us.send("POST", "/api/auth/refresh", dtos.RefreshInput{RefreshToken: refreshToken}, fiber.StatusOK)
+	us.assertNotContains(body, userSecrets...)
+	us.assertNotContains(body, userPersonalData...)
+}
+
+func (us *UserViewSuite) TestCompleteOIDCLogin() {
+	state := "test-state"
+	us.mock.ExpectBegin()
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "o_id_c_login_states" WHERE state_hash = $1`)).
+		WithArgs(hashToken(state)).
+		WillReturnRows(sqlmock.NewRows([]string{"state_hash", "provider", "code_verifier", "nonce", "expires_at"}).
+			AddRow(hashToken(state), "mock", "verifier", "nonce", time.Now().Add(time.Minute)))
+	us.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "o_id_c_login_states" WHERE state_hash = $1`)).
+		WithArgs(hashToken(state)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	us.mock.ExpectCommit()
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "user_identities" WHERE provider = $1 AND subject = $2`)).
+		WithArgs("mock", "subject").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "provider", "subject", "email"}).
+			AddRow(uuid.New(), us.userId, "mock", "subject", testEmail))
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE id = $1`)).
+		WithArgs(us.userId).
+		WillReturnRows(us.userRowsWithTwoFactor(false))
+	us.mock.ExpectBegin()
+	us.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "provider_login_at"=$1 WHERE id = $2`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	us.mock.ExpectCommit()
+	us.expectCreateRefreshToken()
+
+	req := httptest.NewRequest("GET", "http://localhost:8000/api/auth/oidc/mock/callback?code=code&state="+state, nil)
+	req.AddCookie(&http.Cookie{Name: controllers.OIDCStateCookie, Value: state})
+	body := us.test(req, fiber.StatusOK)
+	us.assertPublicUser(body)
+}
+
+func (us *UserViewSuite) TestVerifyTwoFactor() {
+	code, err := totp.Code(testTOTPSecret, totp.Counter(time.Now()))
+	assert.Nil(us.T(), err)
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE id = $1`)).
+		WithArgs(us.userId).
+		WillReturnRows(us.userRowsWithTwoFactor(false))
+	us.mock.ExpectBegin()
+	us.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "totp_enabled"=$1`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	us.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "recovery_codes"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	us.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "recovery_codes"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	us.mock.ExpectCommit()
+
+	body := us.send("POST", "/api/auth/2fa/verify", dtos.TwoFactorCode{Code: code}, fiber.StatusOK)
+	assert.Contains(us.T(), body, "recoveryCodes")
+	us.assertNotContains(body, userSecrets...)
+	us.assertNotContains(body, userPersonalData...)
+}
+
+func (us *UserViewSuite) TestExportAccount() {
+	tournamentId, sessionId, tierListId := uuid.New(), uuid.New(), uuid.New()
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE id = $1`)).
+		WithArgs(us.userId).
+		WillReturnRows(us.userRows())
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tournaments" WHERE user_id = $1`)).
+		WithArgs(us.userId).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "user_id"}).AddRow(tournamentId, "tournament", us.userId))
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tiktoks" WHERE tournament_id IN ($1)`)).
+		WithArgs(tournamentId).
+		WillReturnRows(sqlmock.NewRows([]string{"tournament_id", "url"}).AddRow(tournamentId, "https://example.com/a"))
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "play_sessions" WHERE user_id = $1`)).
+		WithArgs(us.userId).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tournament_id", "user_id", "contest_type", "contest"}).
+			AddRow(sessionId, tournamentId, us.userId, dtos.SingleElimination, testContest))
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "match_decisions" WHERE session_id IN ($1)`)).
+		WithArgs(sessionId).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "session_id", "match_id", "winner_url", "loser_url"}).
+			AddRow(uuid.New(), sessionId, "1", "https://example.com/a", "https://example.com/b"))
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tier_lists" WHERE user_id = $1`)).
+		WithArgs(us.userId).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tournament_id", "user_id"}).AddRow(tierListId, tournamentId, us.userId))
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tier_placements" WHERE "tier_placements"."tier_list_id" = $1`)).
+		WithArgs(tierListId).
+		WillReturnRows(sqlmock.NewRows([]string{"tier_list_id", "tournament_id", "tiktok_url", "tier"}).
+			AddRow(tierListId, tournamentId, "https://example.com/a", "S"))
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "user_identities" WHERE user_id = $1`)).
+		WithArgs(us.userId).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "provider", "subject"}).
+			AddRow(uuid.New(), us.userId, "mock", "subject"))
+
+	body := us.get("/api/user/me/export")
+	for _, exported := range []string{us.userId.String(), testEmail, sessionId.String(), tierListId.String(),
+		`"tier":"S"`, `"subject":"subject"`} {
+		assert.Contains(us.T(), body, exported)
+	}
+	us.assertNotContains(body, userSecrets...)
+	us.assertNotContains(body, testContest, "sessionID", "tierListID")
+}
+
+func (us *UserViewSuite) userRows() *sqlmock.Rows {
+	return us.userRowsWithTwoFactor(true)
+}
+
+func (us *UserViewSuite) userRowsWithTwoFactor(enabled bool) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "name", "password", "photo_url", "email", "role", "is_banned",
+		"totp_secret", "totp_enabled"}).
+		AddRow(us.userId, "test", testPasswordHash, "https://example.com/photo.png", testEmail, models.RoleAdmin,
+			false, testTOTPSecret, enabled)
+}
+
+func (us *UserViewSuite) expectCreateRefreshToken() {
+	us.mock.ExpectBegin()
+	us.mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "refresh_tokens"`)).
+		WithArgs(us.userId, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "is_revoked"}).AddRow(uuid.New(), false))
+	us.mock.ExpectCommit()
+}
+
+func (us *UserViewSuite) expectTournamentWithUser() {
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tournaments"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "size", "times_played", "user_id"}).
+			AddRow(uuid.New(), "tournament", 8, 3, us.userId))
+	us.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE "users"."id" = $1`)).
+		WithArgs(us.userId).
+		WillReturnRows(us.userRows())
+}
+
+func (us *UserViewSuite) get(url string) string {
+	return us.test(httptest.NewRequest("GET", "http://localhost:8000"+url, nil), fiber.StatusOK)
+}
+
+func (us *UserViewSuite) send(method string, url string, payload interface{}, status int) string {
+	body, err := json.Marshal(payload)
+	assert.Nil(us.T(), err)
+	req := httptest.NewRequest(method, "http://localhost:8000"+url, strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	return us.test(req, status)
+}
+
+func (us *UserViewSuite) test(req *http.Request, status int) string {
+	resp, err := us.app.Test(req)
+	assert.Nil(us.T(), err)
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	assert.Equal(us.T(), status, resp.StatusCode, string(bodyBytes))
+	assert.Nil(us.T(), us.mock.ExpectationsWereMet())
+	return string(bodyBytes)
+}
+
+// assertPublicUser checks that user is in response without any of its internal fields
+func (us *UserViewSuite) assertPublicUser(body string) {
+	assert.Contains(us.T(), body, us.userId.String())
+	assert.Contains(us.T(), body, `"photoURL":"https://example.com/photo.png"`)
+	us.assertNotContains(body, userSecrets...)
+	us.assertNotContains(body, userPersonalData...)
+}
+
+func (us *UserViewSuite) assertNotContains(body string, internals ...string) {
+	for _, internal := range internals {
+		assert.NotContains(us.T(), body, internal)
+	}
+}
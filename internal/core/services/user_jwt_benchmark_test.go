@@ -4,6 +4,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"tiktok-arena/internal/core/models"
 )
 
 func BenchmarkUserJwtToken(b *testing.B) {
@@ -11,7 +12,7 @@ func BenchmarkUserJwtToken(b *testing.B) {
 	name := "John Doe"
 
 	for n := 0; n < b.N; n++ {
-		_, err := UserJwtToken(id, name)
+		_, err := UserJwtToken(id, name, models.RoleUser)
 		require.NoError(b, err)
 	}
 }
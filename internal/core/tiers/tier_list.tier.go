@@ -0,0 +1,84 @@
+package tiers
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Placement of tiktok into tier of tier list
+type Placement struct {
+	TiktokURL string
+	Tier      string
+}
+
+// Distribution of placements of tiktok into tiers
+type Distribution struct {
+	TiktokURL string
+	Counts    map[string]int // tier -> count of placements
+	Consensus string         // median tier of placements, empty if tiktok wasn't placed
+}
+
+// ValidatePlacements checks that tier list places every tiktok exactly once into one of tiers
+func ValidatePlacements(tiers []string, tiktokURLs []string, placements []Placement) error {
+	allowedTiers := make(map[string]bool, len(tiers))
+	for _, tier := range tiers {
+		allowedTiers[tier] = true
+	}
+	placed := make(map[string]bool, len(tiktokURLs))
+	for _, url := range tiktokURLs {
+		placed[url] = false
+	}
+
+	for _, placement := range placements {
+		alreadyPlaced, exists := placed[placement.TiktokURL]
+		if !exists {
+			return fmt.Errorf("tiktok %s doesn't belong to tournament", placement.TiktokURL)
+		}
+		if alreadyPlaced {
+			return fmt.Errorf("tiktok %s is placed more than once", placement.TiktokURL)
+		}
+		if !allowedTiers[placement.Tier] {
+			return fmt.Errorf("tier %s doesn't exist in tournament", placement.Tier)
+		}
+		placed[placement.TiktokURL] = true
+	}
+	if len(placements) != len(tiktokURLs) {
+		return fmt.Errorf("%d of %d tiktoks are not placed", len(tiktokURLs)-len(placements), len(tiktokURLs))
+	}
+	return nil
+}
+
+// Aggregate counts placements of every tiktok into tiers and finds consensus tier,
+// tiers are ordered from best to worst, placements into tiers which don't exist anymore are skipped.
+// Consensus tier is median of placements, for even count of placements better of two middle tiers is taken.
+func Aggregate(tiers []string, tiktokURLs []string, placements []Placement) []Distribution {
+	order := make(map[string]int, len(tiers))
+	for i, tier := range tiers {
+		order[tier] = i
+	}
+
+	tiktokPlacements := make(map[string][]int, len(tiktokURLs))
+	for _, placement := range placements {
+		if i, ok := order[placement.Tier]; ok {
+			tiktokPlacements[placement.TiktokURL] = append(tiktokPlacements[placement.TiktokURL], i)
+		}
+	}
+
+	distributions := make([]Distribution, 0, len(tiktokURLs))
+	for _, url := range tiktokURLs {
+		distribution := Distribution{TiktokURL: url, Counts: make(map[string]int, len(tiers))}
+		for _, tier := range tiers {
+			distribution.Counts[tier] = 0
+		}
+		placed := tiktokPlacements[url]
+		for _, i := range placed {
+			distribution.Counts[tiers[i]]++
+		}
+		if len(placed) != 0 {
+			sort.Ints(placed)
+			distribution.Consensus = tiers[placed[(len(placed)-1)/2]]
+		}
+		distributions = append(distributions, distribution)
+	}
+	return distributions
+}
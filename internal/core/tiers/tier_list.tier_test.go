@@ -0,0 +1,62 @@
+package tiers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAggregate(t *testing.T) {
+	tiers := []string{"S", "A", "B", "C", "D"}
+	placements := []Placement{
+		{TiktokURL: "first", Tier: "S"},
+		{TiktokURL: "first", Tier: "A"},
+		{TiktokURL: "first", Tier: "C"},
+		{TiktokURL: "second", Tier: "B"},
+		{TiktokURL: "second", Tier: "D"},
+		{TiktokURL: "second", Tier: "removed"},
+	}
+	distributions := Aggregate(tiers, []string{"first", "second", "third"}, placements)
+
+	expected := []struct {
+		counts    map[string]int
+		consensus string
+	}{
+		{map[string]int{"S": 1, "A": 1, "C": 1}, "A"},
+		{map[string]int{"B": 1, "D": 1}, "B"},
+		{map[string]int{}, ""},
+	}
+	assert.Len(t, distributions, len(expected))
+	for i, distribution := range distributions {
+		assert.Equal(t, expected[i].consensus, distribution.Consensus, distribution.TiktokURL)
+		for _, tier := range tiers {
+			assert.Equal(t, expected[i].counts[tier], distribution.Counts[tier],
+				"%s: placements in %s", distribution.TiktokURL, tier)
+		}
+	}
+}
+
+func TestValidatePlacements(t *testing.T) {
+	tiers := []string{"S", "A"}
+	tiktokURLs := []string{"first", "second"}
+	tests := []struct {
+		name       string
+		placements []Placement
+		valid      bool
+	}{
+		{"all placed", []Placement{{"first", "S"}, {"second", "S"}}, true},
+		{"not placed", []Placement{{"first", "S"}}, false},
+		{"placed twice", []Placement{{"first", "S"}, {"first", "A"}}, false},
+		{"unknown tier", []Placement{{"first", "S"}, {"second", "B"}}, false},
+		{"unknown tiktok", []Placement{{"first", "S"}, {"third", "A"}}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidatePlacements(tiers, tiktokURLs, test.placements)
+			if test.valid {
+				assert.Nil(t, err)
+			} else {
+				assert.NotNil(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package totp
+
+import (
+	"encoding/base32"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// rfcSecret is SHA1 secret of test vectors https://www.rfc-editor.org/rfc/rfc6238#appendix-B
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeMatchesRFCTestVectors(t *testing.T) {
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, expected := range vectors {
+		code, err := Code(rfcSecret, Counter(time.Unix(unix, 0)))
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+func TestValidateAcceptsSkewOnly(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	for periods, accepted := range map[int64]bool{-2: false, -1: true, 0: true, 1: true, 2: false} {
+		counter := Counter(now) + periods
+		code, err := Code(rfcSecret, counter)
+		assert.Nil(t, err)
+
+		matched, ok := Validate(rfcSecret, code, now)
+
+		assert.Equal(t, accepted, ok, "code %d periods from now", periods)
+		if ok {
+			assert.Equal(t, counter, matched, "code %d periods from now", periods)
+		}
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("TikTok arena", "test", "SECRET")
+
+	assert.Regexp(t, `^otpauth://totp/TikTok%20arena:test\?`, uri)
+	assert.Contains(t, uri, "secret=SECRET")
+}
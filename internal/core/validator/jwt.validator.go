@@ -15,3 +15,13 @@ func GetUserIdAndCheckJWT(user interface{}) (uuid.UUID, error) {
 
 	return uuid.Parse(claims["sub"].(string))
 }
+
+// GetUserRole returns role of user from JWT claims, empty if JWT or role is missing
+func GetUserRole(user interface{}) string {
+	userJWT, ok := user.(*jwt.Token)
+	if !ok {
+		return ""
+	}
+	role, _ := userJWT.Claims.(jwt.MapClaims)["role"].(string)
+	return role
+}
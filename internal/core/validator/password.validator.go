@@ -0,0 +1,64 @@
+package validator
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"unicode"
+)
+
+// MaxPasswordLength bcrypt ignores bytes of password after 72nd
+const MaxPasswordLength = 72
+
+// PasswordPolicy
+// Strength rules of passwords checked by "password" tag.
+type PasswordPolicy struct {
+	MinLength        int
+	RequireDigit     bool
+	RequireUppercase bool
+	RequireSymbol    bool
+}
+
+var passwordPolicy = PasswordPolicy{MinLength: 8}
+
+// SetPasswordPolicy sets policy checked by "password" tag, MinLength is 8 by default
+func SetPasswordPolicy(policy PasswordPolicy) {
+	if policy.MinLength <= 0 {
+		policy.MinLength = 8
+	}
+	passwordPolicy = policy
+}
+
+// CheckPassword checks password against password policy
+func CheckPassword(password string) error {
+	if len([]rune(password)) < passwordPolicy.MinLength {
+		return fmt.Errorf("password must be at least %d characters long", passwordPolicy.MinLength)
+	}
+	if len(password) > MaxPasswordLength {
+		return fmt.Errorf("password must be at most %d bytes long", MaxPasswordLength)
+	}
+	var hasDigit, hasUppercase, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsUpper(r):
+			hasUppercase = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+	if passwordPolicy.RequireDigit && !hasDigit {
+		return fmt.Errorf("password must contain digit")
+	}
+	if passwordPolicy.RequireUppercase && !hasUppercase {
+		return fmt.Errorf("password must contain uppercase letter")
+	}
+	if passwordPolicy.RequireSymbol && !hasSymbol {
+		return fmt.Errorf("password must contain symbol")
+	}
+	return nil
+}
+
+func validatePassword(fl validator.FieldLevel) bool {
+	return CheckPassword(fl.Field().String()) == nil
+}
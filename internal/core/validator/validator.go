@@ -1,10 +1,27 @@
 package validator
 
-import "github.com/go-playground/validator/v10"
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
 
-var validate = validator.New()
+var validate = newValidate()
+
+func newValidate() *validator.Validate {
+	v := validator.New()
+	_ = v.RegisterValidation("password", validatePassword)
+	return v
+}
 
 func ValidateStruct[T any](payload T) error {
 	err := validate.Struct(payload)
+	if errs, ok := err.(validator.ValidationErrors); ok {
+		// Explain which rule of password policy is broken
+		for _, e := range errs {
+			if e.Tag() == "password" {
+				return fmt.Errorf("%s: %v", e.Field(), CheckPassword(fmt.Sprint(e.Value())))
+			}
+		}
+	}
 	return err
 }
@@ -29,8 +29,25 @@ func ConnectDB(config *configuration.EnvConfigModel) *gorm.DB {
 
 	err = db.AutoMigrate(
 		&models.User{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.RevokedUserTokens{},
+		&models.PasswordResetToken{},
+		&models.EmailChangeToken{},
+		&models.RecoveryCode{},
+		&models.TwoFactorChallenge{},
+		&models.UserIdentity{},
+		&models.OIDCLoginState{},
+		&models.APIKey{},
 		&models.Tournament{},
 		&models.Tiktok{},
+		&models.PlaySession{},
+		&models.MatchDecision{},
+		&models.MatchVote{},
+		&models.SessionRanking{},
+		&models.TierList{},
+		&models.TierPlacement{},
+		&models.Matchup{},
 	)
 	if err != nil {
 		log.Fatal("Migration Failed:\n", err.Error())
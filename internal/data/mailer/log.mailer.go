@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+)
+
+// LogMailer
+// Mailer for local use, mails are written to log or appended to file instead of being sent.
+type LogMailer struct {
+	filePath string
+	mu       sync.Mutex
+}
+
+// NewLogMailer creates mailer which appends mails to file, mails are written to log if filePath is empty
+func NewLogMailer(filePath string) *LogMailer {
+	return &LogMailer{filePath: filePath}
+}
+
+func (m *LogMailer) Send(to string, subject string, body string) error {
+	mail := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n\n", to, subject, body)
+	if m.filePath == "" {
+		log.Printf("Mail:\n%s", mail)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	file, err := os.OpenFile(m.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = file.WriteString(mail)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
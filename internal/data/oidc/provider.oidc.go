@@ -0,0 +1,352 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"tiktok-arena/configuration"
+	"tiktok-arena/internal/core/dtos"
+	"time"
+)
+
+var defaultScopes = []string{"openid", "profile", "email"}
+
+// Provider
+// https://openid.net/specs/openid-connect-core-1_0.html#CodeFlowAuth
+// Client of OpenID Connect provider for authorization code flow with PKCE (S256).
+// Endpoints are discovered from issuer on first use, keys of provider are cached
+// and fetched again when ID token is signed with unknown key.
+type Provider struct {
+	Config configuration.OIDCProviderConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *metadata
+	keys     map[string]interface{} // kid -> public key
+}
+
+// metadata of provider from its discovery document
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func NewProvider(config configuration.OIDCProviderConfig) *Provider {
+	return &Provider{Config: config, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// NewProviders creates providers of config by their names
+func NewProviders(configs []configuration.OIDCProviderConfig) (map[string]*Provider, error) {
+	providers := make(map[string]*Provider, len(configs))
+	for _, config := range configs {
+		if config.Name == "" || config.Issuer == "" || config.ClientID == "" || config.RedirectURL == "" {
+			return nil, fmt.Errorf("provider %q needs name, issuer, clientId and redirectURL", config.Name)
+		}
+		if _, ok := providers[config.Name]; ok {
+			return nil, fmt.Errorf("provider %s is configured more than once", config.Name)
+		}
+		providers[config.Name] = NewProvider(config)
+	}
+	return providers, nil
+}
+
+// AuthorizationURL returns URL of provider where user logs in, code challenge is derived from code verifier
+func (p *Provider) AuthorizationURL(state string, nonce string, codeVerifier string) (string, error) {
+	m, err := p.getMetadata()
+	if err != nil {
+		return "", err
+	}
+
+	scopes := p.Config.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultScopes
+	}
+	challenge := sha256.Sum256([]byte(codeVerifier))
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.Config.ClientID},
+		"redirect_uri":          {p.Config.RedirectURL},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(m.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return m.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems authorization code at token endpoint and verifies returned ID token,
+// nonce of ID token must match nonce sent with authorization URL
+func (p *Provider) Exchange(code string, codeVerifier string, nonce string) (identity dtos.ExternalIdentity, err error) {
+	m, err := p.getMetadata()
+	if err != nil {
+		return identity, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.Config.RedirectURL},
+		"client_id":     {p.Config.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	request, err := http.NewRequest(http.MethodPost, m.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return identity, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	if p.Config.ClientSecret != "" {
+		request.SetBasicAuth(url.QueryEscape(p.Config.ClientID), url.QueryEscape(p.Config.ClientSecret))
+	}
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return identity, err
+	}
+	defer response.Body.Close()
+
+	var tokens tokenResponse
+	err = json.NewDecoder(response.Body).Decode(&tokens)
+	if err != nil {
+		return identity, fmt.Errorf("invalid token response: %v", err)
+	}
+	if response.StatusCode != http.StatusOK || tokens.Error != "" {
+		return identity, fmt.Errorf("token request failed: %s %s", tokens.Error, tokens.ErrorDescription)
+	}
+	if tokens.IDToken == "" {
+		return identity, errors.New("token response has no ID token")
+	}
+
+	return p.verifyIDToken(tokens.IDToken, m, nonce)
+}
+
+// verifyIDToken checks signature, issuer, audience, expiration and nonce of ID token
+func (p *Provider) verifyIDToken(idToken string, m *metadata, nonce string) (identity dtos.ExternalIdentity, err error) {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{
+		"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA",
+	}))
+	claims := jwt.MapClaims{}
+	_, err = parser.ParseWithClaims(idToken, claims, p.keyFunc)
+	if err != nil {
+		return identity, fmt.Errorf("invalid ID token: %v", err)
+	}
+
+	now := time.Now().Unix()
+	if !claims.VerifyIssuer(m.Issuer, true) {
+		return identity, errors.New("ID token is issued by another issuer")
+	}
+	if !claims.VerifyAudience(p.Config.ClientID, true) {
+		return identity, errors.New("ID token is issued for another client")
+	}
+	if !claims.VerifyExpiresAt(now, true) {
+		return identity, errors.New("ID token is expired")
+	}
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return identity, errors.New("ID token nonce doesn't match")
+	}
+
+	identity.Subject, _ = claims["sub"].(string)
+	if identity.Subject == "" {
+		return identity, errors.New("ID token has no subject")
+	}
+	identity.Email, _ = claims["email"].(string)
+	identity.EmailVerified, _ = claims["email_verified"].(bool)
+	identity.Name, _ = claims["preferred_username"].(string)
+	if identity.Name == "" {
+		identity.Name, _ = claims["name"].(string)
+	}
+	return identity, nil
+}
+
+// keyFunc finds key which signed ID token, keys are fetched again if key is unknown
+func (p *Provider) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	p.mu.Lock()
+	keys := p.keys
+	p.mu.Unlock()
+
+	key, ok := findKey(keys, kid)
+	if ok {
+		return key, nil
+	}
+
+	keys, err := p.fetchKeys()
+	if err != nil {
+		return nil, err
+	}
+	key, ok = findKey(keys, kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown key %s", kid)
+	}
+	return key, nil
+}
+
+// findKey finds key by kid, tokens without kid can be verified only if provider has one key
+func findKey(keys map[string]interface{}, kid string) (interface{}, bool) {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+	key, ok := keys[kid]
+	return key, ok
+}
+
+func (p *Provider) getMetadata() (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var m metadata
+	err := p.getJSON(strings.TrimSuffix(p.Config.Issuer, "/")+"/.well-known/openid-configuration", &m)
+	if err != nil {
+		return nil, err
+	}
+	if m.Issuer != p.Config.Issuer {
+		return nil, fmt.Errorf("discovery document has issuer %s instead of %s", m.Issuer, p.Config.Issuer)
+	}
+	if m.AuthorizationEndpoint == "" || m.TokenEndpoint == "" || m.JwksURI == "" {
+		return nil, errors.New("discovery document misses endpoints")
+	}
+	p.metadata = &m
+	return p.metadata, nil
+}
+
+func (p *Provider) fetchKeys() (map[string]interface{}, error) {
+	m, err := p.getMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	var jwks dtos.JWKS
+	err = p.getJSON(m.JwksURI, &jwks)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]interface{}, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use == "enc" {
+			continue
+		}
+		key, err := publicKey(jwk)
+		if err != nil {
+			// keys of unsupported types are skipped, they can't verify ID tokens anyway
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+	return keys, nil
+}
+
+func (p *Provider) getJSON(url string, v interface{}) error {
+	response, err := p.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", url, response.Status)
+	}
+	return json.NewDecoder(response.Body).Decode(v)
+}
+
+// publicKey decodes RSA, EC or Ed25519 public key of JWK
+func publicKey(jwk dtos.JWK) (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC key is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %s", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", jwk.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes) == 0 {
+		return nil, errors.New("empty key parameter")
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
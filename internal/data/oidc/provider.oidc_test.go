@@ -0,0 +1,136 @@
+package oidc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"tiktok-arena/configuration"
+	"tiktok-arena/internal/core/dtos"
+	"time"
+)
+
+// mockProvider is local OpenID Connect provider which issues ID token for code "test-code"
+type mockProvider struct {
+	server    *httptest.Server
+	key       ed25519.PrivateKey
+	challenge string // code challenge of last authorization URL
+	nonce     string // nonce put into ID token
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.Nil(t, err)
+	m := &mockProvider{key: privateKey}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"kid": "mock",
+			"x":   base64.RawURLEncoding.EncodeToString(publicKey),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if r.PostFormValue("code") != "test-code" ||
+			base64.RawURLEncoding.EncodeToString(verifier[:]) != m.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+			"iss":                m.server.URL,
+			"aud":                "arena",
+			"sub":                "mock-subject",
+			"nonce":              m.nonce,
+			"preferred_username": "mockuser",
+			"email":              "mock@example.com",
+			"email_verified":     true,
+			"exp":                time.Now().Add(time.Minute).Unix(),
+		})
+		token.Header["kid"] = "mock"
+		idToken, err := token.SignedString(m.key)
+		assert.Nil(t, err)
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "token_type": "Bearer"})
+	})
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+func (m *mockProvider) provider() *Provider {
+	return NewProvider(configuration.OIDCProviderConfig{
+		Name:        "mock",
+		Issuer:      m.server.URL,
+		ClientID:    "arena",
+		RedirectURL: "http://localhost:3000/oidc/callback",
+	})
+}
+
+func (m *mockProvider) authorize(t *testing.T, provider *Provider, nonce string, codeVerifier string) {
+	authorizationURL, err := provider.AuthorizationURL("test-state", nonce, codeVerifier)
+	require.Nil(t, err)
+	require.True(t, strings.HasPrefix(authorizationURL, m.server.URL+"/authorize?"), authorizationURL)
+	parsed, err := url.Parse(authorizationURL)
+	require.Nil(t, err)
+	query := parsed.Query()
+	require.Equal(t, "test-state", query.Get("state"))
+	require.Equal(t, "S256", query.Get("code_challenge_method"))
+	m.challenge = query.Get("code_challenge")
+	m.nonce = query.Get("nonce")
+}
+
+func TestExchangeReturnsIdentityOfVerifiedIDToken(t *testing.T) {
+	m := newMockProvider(t)
+	provider := m.provider()
+	m.authorize(t, provider, "test-nonce", "test-verifier")
+
+	identity, err := provider.Exchange("test-code", "test-verifier", "test-nonce")
+
+	assert.Nil(t, err)
+	assert.Equal(t, dtos.ExternalIdentity{
+		Subject:       "mock-subject",
+		Email:         "mock@example.com",
+		EmailVerified: true,
+		Name:          "mockuser",
+	}, identity)
+}
+
+func TestExchangeRejectsWrongCodeVerifier(t *testing.T) {
+	m := newMockProvider(t)
+	provider := m.provider()
+	m.authorize(t, provider, "test-nonce", "test-verifier")
+
+	_, err := provider.Exchange("test-code", "another-verifier", "test-nonce")
+
+	assert.ErrorContains(t, err, "invalid_grant")
+}
+
+func TestExchangeRejectsWrongNonce(t *testing.T) {
+	m := newMockProvider(t)
+	provider := m.provider()
+	m.authorize(t, provider, "test-nonce", "test-verifier")
+
+	_, err := provider.Exchange("test-code", "test-verifier", "another-nonce")
+
+	assert.ErrorContains(t, err, "nonce")
+}
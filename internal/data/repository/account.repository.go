@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"tiktok-arena/internal/core/models"
+)
+
+// AccountRepository
+// Deletion and export of all data of user.
+type AccountRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+// DeleteUser deletes user with its credentials, tournaments of user are deleted with their tiktoks and statistics
+// or anonymised by moving them to deleted user, play sessions and tier lists of user are kept as anonymous
+func (r *AccountRepository) DeleteUser(id uuid.UUID, anonymiseTournaments bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if anonymiseTournaments {
+			record := tx.
+				Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "id"}}, DoNothing: true}).
+				Create(&models.User{
+					ID:       models.DeletedUserID,
+					Name:     models.DeletedUserName,
+					Password: models.NoPassword,
+					Role:     models.RoleUser,
+					IsBanned: true,
+				})
+			if record.Error != nil {
+				return record.Error
+			}
+			record = tx.
+				Model(&models.Tournament{}).
+				Where("user_id = ?", id).
+				Update("user_id", models.DeletedUserID)
+			if record.Error != nil {
+				return record.Error
+			}
+		} else {
+			var tournamentIds []uuid.UUID
+			record := tx.
+				Model(&models.Tournament{}).
+				Where("user_id = ?", id).
+				Pluck("id", &tournamentIds)
+			if record.Error != nil {
+				return record.Error
+			}
+			for _, tournamentId := range tournamentIds {
+				err := deleteTournament(tx, tournamentId)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, model := range []interface{}{&models.PlaySession{}, &models.TierList{}, &models.OIDCLoginState{}} {
+			record := tx.
+				Model(model).
+				Where("user_id = ?", id).
+				Update("user_id", nil)
+			if record.Error != nil {
+				return record.Error
+			}
+		}
+		for _, model := range []interface{}{
+			&models.RefreshToken{},
+			&models.PasswordResetToken{},
+			&models.EmailChangeToken{},
+			&models.RecoveryCode{},
+			&models.TwoFactorChallenge{},
+			&models.APIKey{},
+			&models.UserIdentity{},
+		} {
+			record := tx.
+				Where("user_id = ?", id).
+				Delete(model)
+			if record.Error != nil {
+				return record.Error
+			}
+		}
+
+		record := tx.
+			Where("id = ?", id).
+			Delete(&models.User{})
+		return record.Error
+	})
+}
+
+func (r *AccountRepository) GetUserTournaments(userId uuid.UUID) ([]models.Tournament, error) {
+	tournaments := make([]models.Tournament, 0)
+	record := r.db.
+		Where("user_id = ?", userId).
+		Order("name").
+		Find(&tournaments)
+	return tournaments, record.Error
+}
+
+func (r *AccountRepository) GetTournamentsTiktoks(tournamentIds []uuid.UUID) ([]models.Tiktok, error) {
+	tiktoks := make([]models.Tiktok, 0)
+	if len(tournamentIds) == 0 {
+		return tiktoks, nil
+	}
+	record := r.db.
+		Where("tournament_id IN ?", tournamentIds).
+		Order("tournament_id, url").
+		Find(&tiktoks)
+	return tiktoks, record.Error
+}
+
+func (r *AccountRepository) GetUserPlaySessions(userId uuid.UUID) ([]models.PlaySession, error) {
+	sessions := make([]models.PlaySession, 0)
+	record := r.db.
+		Where("user_id = ?", userId).
+		Order("created_at").
+		Find(&sessions)
+	return sessions, record.Error
+}
+
+func (r *AccountRepository) GetSessionsDecisions(sessionIds []uuid.UUID) ([]models.MatchDecision, error) {
+	decisions := make([]models.MatchDecision, 0)
+	if len(sessionIds) == 0 {
+		return decisions, nil
+	}
+	record := r.db.
+		Where("session_id IN ?", sessionIds).
+		Order("created_at").
+		Find(&decisions)
+	return decisions, record.Error
+}
+
+func (r *AccountRepository) GetUserTierLists(userId uuid.UUID) ([]models.TierList, error) {
+	tierLists := make([]models.TierList, 0)
+	record := r.db.
+		Preload("Placements").
+		Where("user_id = ?", userId).
+		Order("created_at").
+		Find(&tierLists)
+	return tierLists, record.Error
+}
+
+func (r *AccountRepository) GetUserIdentities(userId uuid.UUID) ([]models.UserIdentity, error) {
+	identities := make([]models.UserIdentity, 0)
+	record := r.db.
+		Where("user_id = ?", userId).
+		Find(&identities)
+	return identities, record.Error
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"tiktok-arena/internal/core/models"
+	"time"
+)
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) CreateAPIKey(key *models.APIKey) error {
+	record := r.db.
+		Create(key)
+	return record.Error
+}
+
+func (r *APIKeyRepository) CountUserAPIKeys(userId uuid.UUID) (int64, error) {
+	var count int64
+	record := r.db.
+		Model(&models.APIKey{}).
+		Where("user_id = ?", userId).
+		Count(&count)
+	return count, record.Error
+}
+
+func (r *APIKeyRepository) GetUserAPIKeys(userId uuid.UUID) ([]models.APIKey, error) {
+	keys := make([]models.APIKey, 0)
+	record := r.db.
+		Where("user_id = ?", userId).
+		Order("created_at").
+		Find(&keys)
+	return keys, record.Error
+}
+
+func (r *APIKeyRepository) GetAPIKeyByHash(keyHash string) (models.APIKey, error) {
+	var key models.APIKey
+	record := r.db.
+		Where("key_hash = ?", keyHash).
+		First(&key)
+	return key, record.Error
+}
+
+func (r *APIKeyRepository) UpdateAPIKeyLastUsed(id uuid.UUID, lastUsedAt time.Time) error {
+	record := r.db.
+		Model(&models.APIKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", lastUsedAt)
+	return record.Error
+}
+
+// DeleteUserAPIKey deletes key of user, returns false if user has no key with id
+func (r *APIKeyRepository) DeleteUserAPIKey(userId uuid.UUID, id uuid.UUID) (bool, error) {
+	record := r.db.
+		Where("id = ? AND user_id = ?", id, userId).
+		Delete(&models.APIKey{})
+	return record.RowsAffected != 0, record.Error
+}
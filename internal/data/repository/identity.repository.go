@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"tiktok-arena/internal/core/models"
+	"time"
+)
+
+type IdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+// CreateLoginState saves state of started login and removes expired states of logins which weren't completed
+func (r *IdentityRepository) CreateLoginState(state *models.OIDCLoginState) error {
+	record := r.db.
+		Create(state)
+	if record.Error != nil {
+		return record.Error
+	}
+	record = r.db.
+		Where("expires_at < ?", time.Now().UTC()).
+		Delete(&models.OIDCLoginState{})
+	return record.Error
+}
+
+// TakeLoginState deletes state of login and returns it,
+// returns false if state doesn't exist, is expired or was already taken
+func (r *IdentityRepository) TakeLoginState(stateHash string) (models.OIDCLoginState, bool, error) {
+	var state models.OIDCLoginState
+	taken := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		record := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("state_hash = ?", stateHash).
+			Find(&state)
+		if record.Error != nil || record.RowsAffected == 0 {
+			return record.Error
+		}
+		record = tx.
+			Where("state_hash = ?", stateHash).
+			Delete(&models.OIDCLoginState{})
+		if record.Error != nil {
+			return record.Error
+		}
+		taken = time.Now().UTC().Before(state.ExpiresAt)
+		return nil
+	})
+	return state, taken, err
+}
+
+func (r *IdentityRepository) GetIdentity(provider string, subject string) (models.UserIdentity, error) {
+	var identity models.UserIdentity
+	record := r.db.
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity)
+	return identity, record.Error
+}
+
+func (r *IdentityRepository) CreateIdentity(identity *models.UserIdentity) error {
+	record := r.db.
+		Create(identity)
+	return record.Error
+}
+
+// CreateUserWithIdentity creates user registered through external provider together with its identity
+func (r *IdentityRepository) CreateUserWithIdentity(user *models.User, identity *models.UserIdentity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		record := tx.
+			Create(user)
+		if record.Error != nil {
+			return record.Error
+		}
+		identity.UserID = user.ID
+		record = tx.
+			Create(identity)
+		return record.Error
+	})
+}
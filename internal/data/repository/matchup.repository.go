@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"tiktok-arena/internal/core/models"
+)
+
+type MatchupRepository struct {
+	db *gorm.DB
+}
+
+func NewMatchupRepository(db *gorm.DB) *MatchupRepository {
+	return &MatchupRepository{db: db}
+}
+
+func (r *MatchupRepository) GetTournamentMatchups(tournamentId uuid.UUID) ([]models.Matchup, error) {
+	var matchups []models.Matchup
+	record := r.db.
+		Where("tournament_id = ?", tournamentId).
+		Find(&matchups)
+	return matchups, record.Error
+}
+
+// recordMatchup creates matchup or adds its wins to existing one
+func recordMatchup(tx *gorm.DB, m models.Matchup) error {
+	record := tx.
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "tournament_id"}, {Name: "first_url"}, {Name: "second_url"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"first_wins":  gorm.Expr("matchups.first_wins + ?", m.FirstWins),
+				"second_wins": gorm.Expr("matchups.second_wins + ?", m.SecondWins),
+			}),
+		}).
+		Create(&m)
+	return record.Error
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"tiktok-arena/internal/core/models"
+)
+
+type PlaySessionRepository struct {
+	db *gorm.DB
+}
+
+// errMatchDecided rolls back vote when match was decided by concurrent vote
+var errMatchDecided = errors.New("match is already decided")
+
+func NewPlaySessionRepository(db *gorm.DB) *PlaySessionRepository {
+	return &PlaySessionRepository{db: db}
+}
+
+func (r *PlaySessionRepository) CreatePlaySession(session *models.PlaySession) error {
+	record := r.db.
+		Create(session)
+	return record.Error
+}
+
+func (r *PlaySessionRepository) GetPlaySessionById(id uuid.UUID) (models.PlaySession, error) {
+	var session models.PlaySession
+	record := r.db.
+		First(&session, "id = ?", id)
+	return session, record.Error
+}
+
+// UpdatePlaySessionContest replaces contest of session only if it is still old contest,
+// returns false if contest was already replaced concurrently
+func (r *PlaySessionRepository) UpdatePlaySessionContest(id uuid.UUID, old string, contest string) (bool, error) {
+	record := r.db.
+		Model(&models.PlaySession{}).
+		Where("id = ? AND contest = ?", id, old).
+		Update("contest", contest)
+	return record.RowsAffected != 0, record.Error
+}
+
+func (r *PlaySessionRepository) GetMatchDecisions(sessionId uuid.UUID) ([]models.MatchDecision, error) {
+	var decisions []models.MatchDecision
+	record := r.db.
+		Where("session_id = ?", sessionId).
+		Order("created_at").
+		Find(&decisions)
+	return decisions, record.Error
+}
+
+func (r *PlaySessionRepository) GetMatchVotes(sessionId uuid.UUID) ([]models.MatchVote, error) {
+	var votes []models.MatchVote
+	record := r.db.
+		Where("session_id = ?", sessionId).
+		Order("created_at").
+		Find(&votes)
+	return votes, record.Error
+}
+
+// RecordMatchVote creates vote of match, when vote decides match also creates its decision,
+// records matchup and updates ratings of match participators with rate function in the same transaction,
+// returns false if match was already decided by concurrent vote
+func (r *PlaySessionRepository) RecordMatchVote(tournamentId uuid.UUID, vote models.MatchVote,
+	decision *models.MatchDecision, rate func(winner *models.Tiktok, loser *models.Tiktok)) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		record := tx.
+			Create(&vote)
+		if record.Error != nil {
+			return record.Error
+		}
+		if decision == nil {
+			return nil
+		}
+
+		record = tx.
+			Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "session_id"}, {Name: "match_id"}},
+				DoNothing: true,
+			}).
+			Create(decision)
+		if record.Error != nil {
+			return record.Error
+		}
+		if record.RowsAffected == 0 {
+			return errMatchDecided
+		}
+
+		err := recordMatchup(tx, models.NewMatchup(tournamentId, decision.WinnerURL, decision.LoserURL))
+		if err != nil {
+			return err
+		}
+		return updateTiktokRatings(tx, tournamentId, decision.WinnerURL, decision.LoserURL, rate)
+	})
+	if err == errMatchDecided {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *PlaySessionRepository) GetTournamentRankings(tournamentId uuid.UUID) ([]models.SessionRanking, error) {
+	var rankings []models.SessionRanking
+	record := r.db.
+		Where("tournament_id = ?", tournamentId).
+		Find(&rankings)
+	return rankings, record.Error
+}
+
+// CompletePlaySession marks session as completed with its podium and ranking (only for full ranking)
+// and updates statistics of tournament and of tiktoks drawn for session,
+// statistics are updated only once even if session is completed concurrently
+func (r *PlaySessionRepository) CompletePlaySession(session models.PlaySession, tiktokURLs []string,
+	ranking []models.SessionRanking) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		record := tx.
+			Model(&models.PlaySession{}).
+			Where("id = ? AND is_completed = ?", session.ID, false).
+			Updates(map[string]interface{}{
+				"is_completed": true,
+				"winner_url":   session.WinnerURL,
+				"second_url":   session.SecondURL,
+				"third_url":    session.ThirdURL,
+			})
+		if record.Error != nil || record.RowsAffected == 0 {
+			return record.Error
+		}
+		if len(ranking) != 0 {
+			record = tx.Create(&ranking)
+			if record.Error != nil {
+				return record.Error
+			}
+		}
+		record = tx.
+			Model(&models.Tournament{}).
+			Where("id = ?", session.TournamentID).
+			UpdateColumn("times_played", gorm.Expr("times_played + ?", 1))
+		if record.Error != nil {
+			return record.Error
+		}
+		record = tx.
+			Model(&models.Tiktok{}).
+			Where("tournament_id = ? AND url IN ?", session.TournamentID, tiktokURLs).
+			UpdateColumn("appearances", gorm.Expr("appearances + ?", 1))
+		if record.Error != nil {
+			return record.Error
+		}
+		record = tx.
+			Model(&models.Tiktok{}).
+			Where("tournament_id = ? AND url = ?", session.TournamentID, session.WinnerURL).
+			UpdateColumn("wins", gorm.Expr("wins + ?", 1))
+		if record.Error != nil {
+			return record.Error
+		}
+		record = tx.
+			Model(&models.Tiktok{}).
+			Where("tournament_id = ? AND url = ?", session.TournamentID, session.SecondURL).
+			UpdateColumn("second_places", gorm.Expr("second_places + ?", 1))
+		if record.Error != nil {
+			return record.Error
+		}
+		record = tx.
+			Model(&models.Tiktok{}).
+			Where("tournament_id = ? AND url = ?", session.TournamentID, session.ThirdURL).
+			UpdateColumn("third_places", gorm.Expr("third_places + ?", 1))
+		return record.Error
+	})
+}
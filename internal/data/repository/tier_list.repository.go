@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"tiktok-arena/internal/core/models"
+)
+
+type TierListRepository struct {
+	db *gorm.DB
+}
+
+func NewTierListRepository(db *gorm.DB) *TierListRepository {
+	return &TierListRepository{db: db}
+}
+
+// CreateTierList creates tier list with all its placements
+func (r *TierListRepository) CreateTierList(tierList *models.TierList) error {
+	record := r.db.
+		Create(tierList)
+	return record.Error
+}
+
+func (r *TierListRepository) GetTournamentTierPlacements(tournamentId uuid.UUID) ([]models.TierPlacement, error) {
+	var placements []models.TierPlacement
+	record := r.db.
+		Where("tournament_id = ?", tournamentId).
+		Find(&placements)
+	return placements, record.Error
+}
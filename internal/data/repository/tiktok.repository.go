@@ -3,6 +3,7 @@ package repository
 import (
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"tiktok-arena/internal/core/models"
 )
 
@@ -53,10 +54,20 @@ func (r *TiktokRepository) GetTournamentTiktoksById(tournamentId uuid.UUID) ([]m
 	var tiktoks []models.Tiktok
 	record := r.db.
 		Select("*").
+		Order("url"). // Stable order of tiktoks is required to regenerate contest from its seed
 		Find(&tiktoks, "tournament_id = ?", tournamentId)
 	return tiktoks, record.Error
 }
 
+func (r *TiktokRepository) CheckIfTiktokExists(tournamentId uuid.UUID, tiktokURL string) (bool, error) {
+	var count int64
+	record := r.db.
+		Model(&models.Tiktok{}).
+		Where("tournament_id = ? AND url = ?", tournamentId, tiktokURL).
+		Count(&count)
+	return count != 0, record.Error
+}
+
 func (r *TiktokRepository) UpdateTiktokWins(tournamentId uuid.UUID, tiktokURL string) error {
 	record := r.db.
 		Model(&models.Tiktok{}).
@@ -64,3 +75,55 @@ func (r *TiktokRepository) UpdateTiktokWins(tournamentId uuid.UUID, tiktokURL st
 		UpdateColumn("wins", gorm.Expr("wins + ?", 1))
 	return record.Error
 }
+
+// UpdateTiktoksAppearances increments count of plays for tiktoks of tournament with URLs,
+// for all tiktoks of tournament if URLs are empty
+func (r *TiktokRepository) UpdateTiktoksAppearances(tournamentId uuid.UUID, tiktokURLs []string) error {
+	query := r.db.
+		Model(&models.Tiktok{}).
+		Where("tournament_id = ?", tournamentId)
+	if len(tiktokURLs) != 0 {
+		query = query.Where("url IN ?", tiktokURLs)
+	}
+	record := query.UpdateColumn("appearances", gorm.Expr("appearances + ?", 1))
+	return record.Error
+}
+
+// updateTiktokRatings locks winner and loser of match and updates their ratings with rate function,
+// rows are locked in order of URLs, so concurrent matches of same tiktoks can't deadlock
+func updateTiktokRatings(tx *gorm.DB, tournamentId uuid.UUID, winnerURL string, loserURL string,
+	rate func(winner *models.Tiktok, loser *models.Tiktok)) error {
+	var tiktoks []models.Tiktok
+	record := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tournament_id = ? AND url IN (?,?)", tournamentId, winnerURL, loserURL).
+		Order("url").
+		Find(&tiktoks)
+	if record.Error != nil {
+		return record.Error
+	}
+	if len(tiktoks) != 2 {
+		return gorm.ErrRecordNotFound
+	}
+	winner, loser := tiktoks[0], tiktoks[1]
+	if winner.URL != winnerURL {
+		winner, loser = loser, winner
+	}
+
+	rate(&winner, &loser)
+
+	for _, t := range []models.Tiktok{winner, loser} {
+		record = tx.
+			Model(&models.Tiktok{}).
+			Where("tournament_id = ? AND url = ?", t.TournamentID, t.URL).
+			Updates(map[string]interface{}{
+				"rating":            t.Rating,
+				"rating_deviation":  t.RatingDeviation,
+				"rating_volatility": t.RatingVolatility,
+			})
+		if record.Error != nil {
+			return record.Error
+		}
+	}
+	return nil
+}
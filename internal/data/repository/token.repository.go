@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"tiktok-arena/internal/core/models"
+	"time"
+)
+
+type TokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) *TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+func (r *TokenRepository) CreateRefreshToken(token *models.RefreshToken) error {
+	record := r.db.
+		Create(token)
+	return record.Error
+}
+
+func (r *TokenRepository) GetRefreshTokenByHash(tokenHash string) (models.RefreshToken, error) {
+	var token models.RefreshToken
+	record := r.db.
+		Where("token_hash = ?", tokenHash).
+		First(&token)
+	return token, record.Error
+}
+
+// RotateRefreshToken revokes old token and creates new token of the same family,
+// returns false if old token was already revoked (e.g. rotated concurrently)
+func (r *TokenRepository) RotateRefreshToken(old models.RefreshToken, new *models.RefreshToken) (bool, error) {
+	rotated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		record := tx.
+			Model(&models.RefreshToken{}).
+			Where("id = ? AND is_revoked = ?", old.ID, false).
+			Update("is_revoked", true)
+		if record.Error != nil || record.RowsAffected == 0 {
+			return record.Error
+		}
+		record = tx.
+			Create(new)
+		if record.Error != nil {
+			return record.Error
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
+
+func (r *TokenRepository) RevokeRefreshTokenFamily(familyId uuid.UUID) error {
+	record := r.db.
+		Model(&models.RefreshToken{}).
+		Where("family_id = ?", familyId).
+		Update("is_revoked", true)
+	return record.Error
+}
+
+func (r *TokenRepository) RevokeRefreshToken(userId uuid.UUID, tokenHash string) error {
+	record := r.db.
+		Model(&models.RefreshToken{}).
+		Where("user_id = ? AND token_hash = ?", userId, tokenHash).
+		Update("is_revoked", true)
+	return record.Error
+}
+
+func (r *TokenRepository) RevokeUserRefreshTokens(userId uuid.UUID) error {
+	record := r.db.
+		Model(&models.RefreshToken{}).
+		Where("user_id = ?", userId).
+		Update("is_revoked", true)
+	return record.Error
+}
+
+// RevokeAccessToken adds access token to revocation list and removes already expired tokens from it
+func (r *TokenRepository) RevokeAccessToken(token models.RevokedToken) error {
+	record := r.db.
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&token)
+	if record.Error != nil {
+		return record.Error
+	}
+	record = r.db.
+		Where("expires_at < ?", time.Now().UTC()).
+		Delete(&models.RevokedToken{})
+	return record.Error
+}
+
+func (r *TokenRepository) IsAccessTokenRevoked(jti string) (bool, error) {
+	var count int64
+	record := r.db.
+		Model(&models.RevokedToken{}).
+		Where("jti = ?", jti).
+		Count(&count)
+	return count != 0, record.Error
+}
+
+// RevokeUserAccessTokens revokes access tokens of user issued before revokedAt
+// and removes revocations of already expired tokens
+func (r *TokenRepository) RevokeUserAccessTokens(userId uuid.UUID, revokedAt time.Time, expiresAt time.Time) error {
+	return revokeUserAccessTokens(r.db, userId, revokedAt, expiresAt)
+}
+
+func revokeUserAccessTokens(db *gorm.DB, userId uuid.UUID, revokedAt time.Time, expiresAt time.Time) error {
+	record := db.
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"revoked_at", "expires_at"}),
+		}).
+		Create(&models.RevokedUserTokens{UserID: userId, RevokedAt: revokedAt, ExpiresAt: expiresAt})
+	if record.Error != nil {
+		return record.Error
+	}
+	record = db.
+		Where("expires_at < ?", time.Now().UTC()).
+		Delete(&models.RevokedUserTokens{})
+	return record.Error
+}
+
+// IsUserAccessTokenRevoked checks if access token of user issued at issuedAt is revoked with all tokens of user,
+// issuedAt has precision of seconds, so token issued in the same second as revocation is revoked too
+func (r *TokenRepository) IsUserAccessTokenRevoked(userId uuid.UUID, issuedAt time.Time) (bool, error) {
+	var count int64
+	record := r.db.
+		Model(&models.RevokedUserTokens{}).
+		Where("user_id = ? AND revoked_at >= ?", userId, issuedAt).
+		Count(&count)
+	return count != 0, record.Error
+}
+
+func (r *TokenRepository) CreatePasswordResetToken(token *models.PasswordResetToken) error {
+	record := r.db.
+		Create(token)
+	return record.Error
+}
+
+// ResetPassword uses password reset token, sets new password of its user and revokes refresh tokens of user
+// and its access tokens issued before revokedAt, returns false if token doesn't exist, is expired or is already used
+func (r *TokenRepository) ResetPassword(tokenHash string, password string, revokedAt time.Time, expiresAt time.Time) (bool, error) {
+	reset := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var token models.PasswordResetToken
+		record := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND is_used = ? AND expires_at > ?", tokenHash, false, time.Now().UTC()).
+			Find(&token)
+		if record.Error != nil || record.RowsAffected == 0 {
+			return record.Error
+		}
+		record = tx.
+			Model(&models.PasswordResetToken{}).
+			Where("id = ?", token.ID).
+			Update("is_used", true)
+		if record.Error != nil {
+			return record.Error
+		}
+		record = tx.
+			Model(&models.User{}).
+			Where("id = ?", token.UserID).
+			Update("password", password)
+		if record.Error != nil {
+			return record.Error
+		}
+		record = tx.
+			Model(&models.RefreshToken{}).
+			Where("user_id = ?", token.UserID).
+			Update("is_revoked", true)
+		if record.Error != nil {
+			return record.Error
+		}
+		err := revokeUserAccessTokens(tx, token.UserID, revokedAt, expiresAt)
+		if err != nil {
+			return err
+		}
+		reset = true
+		return nil
+	})
+	return reset, err
+}
+
+func (r *TokenRepository) CreateEmailChangeToken(token *models.EmailChangeToken) error {
+	record := r.db.
+		Create(token)
+	return record.Error
+}
+
+// ChangeEmail uses email change token and sets email of token to its user,
+// returns false if token doesn't exist, is expired or is already used
+func (r *TokenRepository) ChangeEmail(tokenHash string) (bool, error) {
+	changed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var token models.EmailChangeToken
+		record := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND is_used = ? AND expires_at > ?", tokenHash, false, time.Now().UTC()).
+			Find(&token)
+		if record.Error != nil || record.RowsAffected == 0 {
+			return record.Error
+		}
+		record = tx.
+			Model(&models.EmailChangeToken{}).
+			Where("id = ?", token.ID).
+			Update("is_used", true)
+		if record.Error != nil {
+			return record.Error
+		}
+		record = tx.
+			Model(&models.User{}).
+			Where("id = ?", token.UserID).
+			Update("email", token.Email)
+		if record.Error != nil {
+			return record.Error
+		}
+		changed = true
+		return nil
+	})
+	return changed, err
+}
+
+// ReplaceRecoveryCodes deletes recovery codes of user and creates new ones
+func (r *TokenRepository) ReplaceRecoveryCodes(userId uuid.UUID, codes []models.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		record := tx.
+			Where("user_id = ?", userId).
+			Delete(&models.RecoveryCode{})
+		if record.Error != nil {
+			return record.Error
+		}
+		record = tx.
+			Create(&codes)
+		return record.Error
+	})
+}
+
+// UseRecoveryCode marks recovery code of user as used, returns false if code doesn't exist or is already used
+func (r *TokenRepository) UseRecoveryCode(userId uuid.UUID, codeHash string) (bool, error) {
+	record := r.db.
+		Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND is_used = ?", userId, codeHash, false).
+		Update("is_used", true)
+	return record.RowsAffected != 0, record.Error
+}
+
+func (r *TokenRepository) DeleteRecoveryCodes(userId uuid.UUID) error {
+	record := r.db.
+		Where("user_id = ?", userId).
+		Delete(&models.RecoveryCode{})
+	return record.Error
+}
+
+// CreateTwoFactorChallenge saves challenge and removes expired challenges
+func (r *TokenRepository) CreateTwoFactorChallenge(challenge *models.TwoFactorChallenge) error {
+	record := r.db.
+		Create(challenge)
+	if record.Error != nil {
+		return record.Error
+	}
+	record = r.db.
+		Where("expires_at < ?", time.Now().UTC()).
+		Delete(&models.TwoFactorChallenge{})
+	return record.Error
+}
+
+// UseTwoFactorChallengeAttempt counts attempt to complete challenge and returns challenge,
+// returns false if challenge doesn't exist, is expired or has no attempts left.
+// Attempt is counted before code is checked, so concurrent attempts can't exceed maxAttempts.
+func (r *TokenRepository) UseTwoFactorChallengeAttempt(tokenHash string, now time.Time,
+	maxAttempts int) (models.TwoFactorChallenge, bool, error) {
+	var challenge models.TwoFactorChallenge
+	record := r.db.
+		Model(&challenge).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND attempts < ? AND expires_at > ?", tokenHash, maxAttempts, now).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	return challenge, record.RowsAffected != 0, record.Error
+}
+
+// DeleteTwoFactorChallenge deletes challenge, returns false if it was already deleted
+func (r *TokenRepository) DeleteTwoFactorChallenge(tokenHash string) (bool, error) {
+	record := r.db.
+		Where("token_hash = ?", tokenHash).
+		Delete(&models.TwoFactorChallenge{})
+	return record.RowsAffected != 0, record.Error
+}
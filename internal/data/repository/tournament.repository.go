@@ -5,6 +5,7 @@ import (
 	"gorm.io/gorm"
 	"tiktok-arena/internal/core/dtos"
 	"tiktok-arena/internal/core/models"
+	"tiktok-arena/internal/core/ratings"
 	"tiktok-arena/internal/data/repository/scopes"
 )
 
@@ -24,15 +25,22 @@ func (r *TournamentRepository) GetTournamentWithUserById(tournamentId uuid.UUID)
 	return *tournament, record.Error
 }
 
-func (r *TournamentRepository) GetAllTournamentsWithUsers(totalTournaments int64, queries dtos.PaginationQueries) (dtos.TournamentsResponse, error) {
+func (r *TournamentRepository) GetTournamentById(tournamentId uuid.UUID) (models.Tournament, error) {
+	var tournament models.Tournament
+	record := r.db.
+		First(&tournament, "id = ?", tournamentId)
+	return tournament, record.Error
+}
+
+func (r *TournamentRepository) GetAllTournamentsWithUsers(queries dtos.PaginationQueries, isPrivate bool) ([]models.Tournament, error) {
 	var tournaments []models.Tournament
 	record := r.db.
 		Preload("User").
-		Scopes(scopes.Private(false)).
+		Scopes(scopes.Private(isPrivate)).
 		Scopes(scopes.Search(queries.SearchText)).
 		Scopes(scopes.Paginate(queries.Page, queries.Count)).
 		Find(&tournaments)
-	return dtos.TournamentsResponse{TournamentCount: totalTournaments, Tournaments: tournaments}, record.Error
+	return tournaments, record.Error
 }
 
 func (r *TournamentRepository) CheckIfTournamentExistsByName(name string) (bool, error) {
@@ -87,18 +95,31 @@ func (r *TournamentRepository) EditTournament(t models.Tournament) error {
 	return record.Error
 }
 
+// DeleteTournamentById deletes tournament of user with its tiktoks, play sessions and statistics
 func (r *TournamentRepository) DeleteTournamentById(id uuid.UUID, userId uuid.UUID) error {
-	record := r.db.
-		Where("id = ? AND user_id = ?", id, userId).
-		Delete(&models.Tournament{})
-	return record.Error
+	return r.DeleteTournamentsByIds([]string{id.String()}, userId)
 }
 
+// DeleteTournamentsByIds deletes tournaments of user with their tiktoks, play sessions and statistics
+// in one transaction, tournaments of other users are not deleted
 func (r *TournamentRepository) DeleteTournamentsByIds(ids []string, userId uuid.UUID) error {
-	record := r.db.
-		Where("user_id = ? AND id IN (?)", userId, ids).
-		Delete(&models.Tournament{})
-	return record.Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var tournamentIds []uuid.UUID
+		record := tx.
+			Model(&models.Tournament{}).
+			Where("user_id = ? AND id IN (?)", userId, ids).
+			Pluck("id", &tournamentIds)
+		if record.Error != nil {
+			return record.Error
+		}
+		for _, id := range tournamentIds {
+			err := deleteTournament(tx, id)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 func (r *TournamentRepository) GetTournamentsByUserID(id uuid.UUID, totalTournaments int64, queries dtos.PaginationQueries, isPrivate bool) (dtos.TournamentsResponseWithUser, error) {
@@ -139,3 +160,91 @@ func (r *TournamentRepository) UpdateTournamentTimesPlayed(tournamentId uuid.UUI
 		UpdateColumn("times_played", gorm.Expr("times_played + ?", 1))
 	return record.Error
 }
+
+// DeleteTournamentWithStats deletes tournament of any user with its tiktoks, play sessions and statistics
+func (r *TournamentRepository) DeleteTournamentWithStats(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return deleteTournament(tx, id)
+	})
+}
+
+// ResetTournamentStats resets times played of tournament, wins, places and ratings of its tiktoks
+// and deletes matchups, rankings and tier lists of tournament, play sessions are kept
+func (r *TournamentRepository) ResetTournamentStats(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := deleteTournamentStats(tx, id)
+		if err != nil {
+			return err
+		}
+		record := tx.
+			Model(&models.Tiktok{}).
+			Where("tournament_id = ?", id).
+			Updates(map[string]interface{}{
+				"wins":              0,
+				"appearances":       0,
+				"second_places":     0,
+				"third_places":      0,
+				"rating":            ratings.InitialValue,
+				"rating_deviation":  ratings.InitialDeviation,
+				"rating_volatility": ratings.InitialVolatility,
+			})
+		if record.Error != nil {
+			return record.Error
+		}
+		record = tx.
+			Model(&models.Tournament{}).
+			Where("id = ?", id).
+			UpdateColumn("times_played", 0)
+		return record.Error
+	})
+}
+
+// deleteTournament deletes tournament with its tiktoks, play sessions and statistics
+func deleteTournament(tx *gorm.DB, id uuid.UUID) error {
+	err := deleteTournamentStats(tx, id)
+	if err != nil {
+		return err
+	}
+	sessions := tx.
+		Model(&models.PlaySession{}).
+		Select("id").
+		Where("tournament_id = ?", id)
+	for _, model := range []interface{}{&models.MatchDecision{}, &models.MatchVote{}} {
+		record := tx.
+			Where("session_id IN (?)", sessions).
+			Delete(model)
+		if record.Error != nil {
+			return record.Error
+		}
+	}
+	for _, model := range []interface{}{&models.PlaySession{}, &models.Tiktok{}} {
+		record := tx.
+			Where("tournament_id = ?", id).
+			Delete(model)
+		if record.Error != nil {
+			return record.Error
+		}
+	}
+	record := tx.
+		Where("id = ?", id).
+		Delete(&models.Tournament{})
+	return record.Error
+}
+
+// deleteTournamentStats deletes matchups, rankings and tier lists of tournament
+func deleteTournamentStats(tx *gorm.DB, id uuid.UUID) error {
+	for _, model := range []interface{}{
+		&models.Matchup{},
+		&models.SessionRanking{},
+		&models.TierPlacement{},
+		&models.TierList{},
+	} {
+		record := tx.
+			Where("tournament_id = ?", id).
+			Delete(model)
+		if record.Error != nil {
+			return record.Error
+		}
+	}
+	return nil
+}
@@ -3,11 +3,17 @@ package repository
 import (
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"math"
 	"tiktok-arena/internal/core/dtos"
 	"tiktok-arena/internal/core/models"
 	"tiktok-arena/internal/data/repository/scopes"
+	"time"
 )
 
+// maxLockoutDoublings limits doubling of login lockout when max lockout is not set
+const maxLockoutDoublings = 30
+
 type UserRepository struct {
 	db *gorm.DB
 }
@@ -49,6 +55,137 @@ func (r *UserRepository) ChangeUserPhoto(url string, id uuid.UUID) error {
 	return record.Error
 }
 
+func (r *UserRepository) UpdateUserPassword(id uuid.UUID, password string) error {
+	record := r.db.
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Update("password", password)
+	return record.Error
+}
+
+// SetUserProviderLoginAt saves time of login of user through provider
+func (r *UserRepository) SetUserProviderLoginAt(id uuid.UUID, at time.Time) error {
+	record := r.db.
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Update("provider_login_at", at)
+	return record.Error
+}
+
+// SetUserRole sets role of user, returns false if user doesn't exist
+func (r *UserRepository) SetUserRole(id uuid.UUID, role string) (bool, error) {
+	record := r.db.
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Update("role", role)
+	return record.RowsAffected != 0, record.Error
+}
+
+func (r *UserRepository) SetUserBanned(id uuid.UUID, isBanned bool) error {
+	record := r.db.
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Update("is_banned", isBanned)
+	return record.Error
+}
+
+// IncrementUserFailedLogins atomically counts failed login of user, login is locked from now when failed logins
+// reach maxAttempts, lockout is doubled for every next failed login up to maxLockout (not limited if not positive).
+// Returns time until login is locked, lock is not changed if maxAttempts or lockout is not positive.
+func (r *UserRepository) IncrementUserFailedLogins(id uuid.UUID, now time.Time, maxAttempts int,
+	lockout time.Duration, maxLockout time.Duration) (*time.Time, error) {
+	updates := map[string]interface{}{
+		"failed_logins": gorm.Expr("failed_logins + 1"),
+	}
+	if maxAttempts > 0 && lockout > 0 {
+		maxLockoutSeconds := lockout.Seconds() * math.Pow(2, maxLockoutDoublings)
+		if maxLockout > 0 {
+			maxLockoutSeconds = maxLockout.Seconds()
+		}
+		// Columns in SET are evaluated with values before update
+		updates["locked_until"] = gorm.Expr(`CASE WHEN failed_logins + 1 >= ?
+			THEN CAST(? AS timestamptz) + make_interval(secs => LEAST(? * power(2, LEAST(failed_logins + 1 - ?, ?)), ?))
+			ELSE locked_until END`,
+			maxAttempts, now, lockout.Seconds(), maxAttempts, maxLockoutDoublings, maxLockoutSeconds)
+	}
+
+	var user models.User
+	record := r.db.
+		Model(&user).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "locked_until"}}}).
+		Where("id = ?", id).
+		Updates(updates)
+	return user.LockedUntil, record.Error
+}
+
+// UpdateUserFailedLogins sets count of failed logins of user and time until login is locked
+func (r *UserRepository) UpdateUserFailedLogins(id uuid.UUID, failedLogins int, lockedUntil *time.Time) error {
+	record := r.db.
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"failed_logins": failedLogins,
+			"locked_until":  lockedUntil,
+		})
+	return record.Error
+}
+
+// SetUserTOTP sets TOTP secret of user and if second login step is required, used codes are forgotten
+func (r *UserRepository) SetUserTOTP(id uuid.UUID, secret string, enabled bool) error {
+	record := r.db.
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"totp_secret":       secret,
+			"totp_enabled":      enabled,
+			"totp_last_counter": 0,
+		})
+	return record.Error
+}
+
+// EnableUserTOTP enables 2FA of user with enrolled secret, saves counter of used code and replaces recovery codes,
+// returns false if 2FA is already enabled or another secret was enrolled meanwhile
+func (r *UserRepository) EnableUserTOTP(id uuid.UUID, secret string, counter int64,
+	codes []models.RecoveryCode) (bool, error) {
+	enabled := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		record := tx.
+			Model(&models.User{}).
+			Where("id = ? AND totp_secret = ? AND totp_enabled = ?", id, secret, false).
+			Updates(map[string]interface{}{
+				"totp_enabled":      true,
+				"totp_last_counter": counter,
+			})
+		if record.Error != nil || record.RowsAffected == 0 {
+			return record.Error
+		}
+		record = tx.
+			Where("user_id = ?", id).
+			Delete(&models.RecoveryCode{})
+		if record.Error != nil {
+			return record.Error
+		}
+		record = tx.
+			Create(&codes)
+		if record.Error != nil {
+			return record.Error
+		}
+		enabled = true
+		return nil
+	})
+	return enabled, err
+}
+
+// UseUserTOTPCounter saves counter of used TOTP code,
+// returns false if code with the same or later counter was already used
+func (r *UserRepository) UseUserTOTPCounter(id uuid.UUID, counter int64) (bool, error) {
+	record := r.db.
+		Model(&models.User{}).
+		Where("id = ? AND totp_last_counter < ?", id, counter).
+		Update("totp_last_counter", counter)
+	return record.RowsAffected != 0, record.Error
+}
+
 func (r *UserRepository) GetUserPhoto(id string) (string, error) {
 	var url string
 	record := r.db.
@@ -75,11 +212,11 @@ func (r *UserRepository) TotalUsers() (int64, error) {
 	return totalUsers, record.Error
 }
 
-func (r *UserRepository) GetAllUsers(totalUsers int64, queries dtos.PaginationQueries) (dtos.UsersResponse, error) {
+func (r *UserRepository) GetAllUsers(queries dtos.PaginationQueries) ([]models.User, error) {
 	var users []models.User
 	record := r.db.
 		Scopes(scopes.Search(queries.SearchText)).
 		Scopes(scopes.Paginate(queries.Page, queries.Count)).
 		Find(&users)
-	return dtos.UsersResponse{UserCount: totalUsers, Users: users}, record.Error
+	return users, record.Error
 }